@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+)
+
+// runIssueToken implements `issue-token`. It signs an access token with the same
+// JWT_SIGNING_KEY, JWT_ISSUER and JWT_AUDIENCE the server uses and prints it to stdout.
+// It exits 0 on success and 2 on usage or configuration errors.
+func runIssueToken(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("issue-token", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sub := fs.String("sub", "", "user id to put in the sub claim")
+	var roles stringList
+	fs.Var(&roles, "role", "role to grant; repeat for several")
+	ttl := fs.Duration("ttl", auth.MaxAccessTokenTTL, "token lifetime (at most 15m)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *sub == "" {
+		fmt.Fprintln(stderr, "issue-token: --sub is required")
+		fs.Usage()
+		return 2
+	}
+	seed, err := base64.StdEncoding.DecodeString(os.Getenv("JWT_SIGNING_KEY"))
+	if err != nil || len(seed) == 0 {
+		fmt.Fprintln(stderr, "issue-token: JWT_SIGNING_KEY must be set to the server's base64 key seed")
+		return 2
+	}
+	signer, err := signing.NewSigner(seed)
+	if err != nil {
+		fmt.Fprintf(stderr, "issue-token: %v\n", err)
+		return 2
+	}
+	cfg := authConfigFromEnv()
+	cfg.TTL = *ttl
+	token, exp, err := auth.NewIssuer(signer, cfg).Issue(*sub, roles)
+	if err != nil {
+		fmt.Fprintf(stderr, "issue-token: %v\n", err)
+		return 2
+	}
+	fmt.Fprintln(stdout, token)
+	fmt.Fprintf(stderr, "expires %s\n", exp.UTC().Format(time.RFC3339))
+	return 0
+}
@@ -1,13 +1,42 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
 	"github.com/mgmacri/pool-maintenance-app/internal/delivery"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
 	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/redact"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/mgmacri/pool-maintenance-app/internal/tracing"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
 	"github.com/mgmacri/pool-maintenance-app/internal/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -29,14 +58,62 @@ import (
 // @host            localhost:8080
 // @BasePath        /
 // @schemes         http
+// @securityDefinitions.apikey BearerAuth
+// @in              header
+// @name            Authorization
+// @description     Access token as "Bearer <jwt>".
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			// explicit form of the default below
+		case "verify-webhook":
+			os.Exit(runVerifyWebhook(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "users":
+			os.Exit(runUsers(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "issue-token":
+			os.Exit(runIssueToken(os.Args[2:], os.Stdout, os.Stderr))
+		case "verify-audit":
+			os.Exit(runVerifyAudit(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "help", "-h", "--help":
+			printUsage(os.Stdout)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+			printUsage(os.Stderr)
+			os.Exit(2)
+		}
+	}
+	serve()
+}
+
+// printUsage lists the subcommands supported by the binary.
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, `Usage: pool-maintenance-api [command] [flags]
+
+Commands:
+  serve            Start the HTTP server (default when no command is given)
+  verify-webhook   Verify a webhook signature against one or more secrets
+  verify-audit     Verify the audit and dose hash chains in an export
+  users            Bootstrap the first OWNER and manage accounts offline
+  issue-token      Mint an access token with JWT_SIGNING_KEY (service accounts, local testing)
+  help             Show this message
+`)
+}
+
+// serve starts the HTTP API and background workers; it blocks until the server exits.
+func serve() {
 	env := getEnvDefault("ENV", "dev")
 	logLevelStr := strings.ToLower(getEnvDefault("LOG_LEVEL", "info"))
 	lvl := parseLogLevel(logLevelStr)
 
 	cfg := zap.NewProductionConfig()
 	cfg.Level = zap.NewAtomicLevelAt(lvl)
-	logger, err := cfg.Build()
+	// Every line passes through the redacting core so secrets and PII never reach the log
+	// sink (E-SEC-007).
+	logger, err := cfg.Build(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return redact.NewCore(c, redact.Default())
+	}))
 	if err != nil {
 		panic("failed to initialize zap logger: " + err.Error())
 	}
@@ -51,9 +128,72 @@ func main() {
 		}
 	}()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Tracing: every request gets a server span that continues the caller's trace, and
+	// outbound HTTP clients carry it on (E-OBS-002).
+	tracerProvider, err := tracing.NewProvider(ctx, tracing.Config{
+		ServiceName:    "pool-maintenance-api",
+		ServiceVersion: version.Version,
+		Environment:    env,
+		Exporter:       getEnvDefault("OTEL_TRACES_EXPORTER", tracing.ExporterNone),
+		Output:         os.Stdout,
+	})
+	if err != nil {
+		logger.Fatal("tracing setup failed", zap.Error(err))
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+	propagator := tracing.Propagator()
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagator)
+	outboundTransport := tracing.Transport(tracerProvider, propagator, nil)
+
 	r := gin.New()
-	r.Use(gin.Recovery())
+	// X-Forwarded-For is honoured only from the proxies listed in TRUSTED_PROXIES; by
+	// default none, so the client IP (rate limits, logs, spans) is the peer address.
+	if err := r.SetTrustedProxies(splitList(os.Getenv("TRUSTED_PROXIES"))); err != nil {
+		logger.Fatal("invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+	r.Use(middleware.Tracing(tracerProvider, propagator))
 	r.Use(middleware.ZapLogger(logger))
+	// RED metrics, Go runtime and process metrics are served at /metrics (E-OBS-003).
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	r.Use(middleware.Metrics(metricsRegistry))
+	domainMetrics := metrics.New(metricsRegistry)
+	// Recovery and Errors render every failure, panics included, as the E-API-003 envelope.
+	r.Use(middleware.Recovery(logger))
+	r.Use(middleware.Errors(logger))
+	r.NoRoute(middleware.NotFound)
+
+	auditRepo := repository.NewInMemoryAuditRepository()
+	auditService := usecase.NewAuditService(logger, auditRepo)
+
+	// Authentication: every route except probes, metrics and docs needs a bearer token,
+	// either a user's access token or an integration partner's API key.
+	authCfg := authConfigFromEnv()
+	tokenSigner := signerFromEnv(logger, "JWT_SIGNING_KEY")
+	tokenVerifier := auth.NewVerifier(authCfg, tokenSigner.PublicKey())
+	// API keys live in a JSON file next to the accounts, so issued keys survive a restart.
+	apiKeyService := usecase.NewAPIKeyService(logger, repository.NewFileAPIKeyRepository(getEnvDefault("API_KEYS_FILE", "api_keys.json")), auditService)
+	r.Use(middleware.Auth(logger, tokenVerifier, apiKeyService, middleware.PublicPaths...))
+
+	// Every principal gets a default budget, and expensive routes a separate, smaller one, so
+	// one partner's runaway client can not starve technician traffic. With several replicas,
+	// RATE_LIMIT_STORE=redis enforces one budget across all of them.
+	rateLimiter := middleware.NewRateLimiter(logger, rateLimitStoreFromEnv(ctx, logger), metricsRegistry)
+	r.Use(rateLimiter.Limit(rateLimitFromEnv(logger, "RATE_LIMIT_DEFAULT", domain.RateLimit{Name: "default", Burst: 600, Period: time.Minute})))
+	doseComputationLimit := rateLimiter.Limit(rateLimitFromEnv(logger, "RATE_LIMIT_DOSE_COMPUTATION", domain.RateLimit{Name: "dose_computation", Burst: 30, Period: time.Minute}))
+	exportLimit := rateLimiter.Limit(rateLimitFromEnv(logger, "RATE_LIMIT_EXPORT", domain.RateLimit{Name: "export", Burst: 10, Period: time.Hour}))
+
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry})))
 
 	// Register Swagger UI route after router is initialized
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -65,6 +205,188 @@ func main() {
 	r.GET("/health/live", healthHandler.Live)
 	r.GET("/health/ready", healthHandler.Ready)
 
+	// Webhooks: in-memory stores until a database adapter lands.
+	webhookCfg := webhookConfigFromEnv()
+	webhookService := usecase.NewWebhookService(
+		logger,
+		repository.NewInMemoryWebhookSubscriptionRepository(),
+		repository.NewInMemoryWebhookDeliveryRepository(),
+		auditService,
+		&http.Client{Timeout: webhookCfg.Timeout, Transport: outboundTransport},
+		webhookCfg,
+	)
+	go webhookService.Run(ctx)
+
+	// Event contracts: every outbound event is validated against its published schema.
+	eventRegistry := events.MustNewRegistry()
+
+	// Transactional outbox: producers write events in the same transaction as their state
+	// change; the relay fans committed events out to the sinks.
+	txManager := repository.NewInMemoryTxManager()
+	outboxRepo := repository.NewInMemoryOutboxRepository()
+	eventPublisher := usecase.NewEventPublisher(logger, eventRegistry, outboxRepo)
+
+	doseRepo := repository.NewInMemoryDoseEventRepository()
+	// Creating POSTs replay their first response when a client retries with the same
+	// Idempotency-Key (E-API-005).
+	idempotent := middleware.Idempotency(logger, repository.NewInMemoryIdempotencyRepository(), domain.IdempotencyTTL)
+	// List endpoints page with signed cursors (E-API-004).
+	cursors := pagination.NewCodec(secretFromEnv(logger, "CURSOR_SIGNING_KEY"))
+
+	// In-process subscribers react to committed events via the bus instead of calling each other.
+	bus := events.NewBus(logger, metricsRegistry)
+	alertService := usecase.NewAlertService(logger, txManager, doseRepo, repository.NewInMemoryAlertRepository(), eventPublisher, auditService, domainMetrics)
+	subscribers := []interface{ Subscribe(*events.Bus) error }{
+		alertService,
+		usecase.NewInventoryService(logger, repository.NewInMemoryInventoryRepository()),
+		auditService,
+		usecase.NewNotificationService(logger, usecase.LogNotifier{Logger: logger}),
+	}
+	for _, s := range subscribers {
+		if err := s.Subscribe(bus); err != nil {
+			logger.Fatal("event bus subscription failed", zap.Error(err))
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		bus.Close()
+	}()
+
+	// Each bus subscriber is its own sink, so it is acknowledged only once it has handled an
+	// event and is retried on its own when it fails.
+	sinks := []usecase.EventSink{webhookService}
+	for _, s := range bus.Sinks() {
+		sinks = append(sinks, s)
+	}
+	outboxRelay := usecase.NewOutboxRelay(logger, outboxRepo, usecase.DefaultOutboxRelayConfig(), sinks...)
+	go outboxRelay.Run(ctx)
+
+	// The use case narrows a TECH to jobs on their route: the jobs an OWNER or DISPATCHER
+	// assigned to them through PUT /api/v1/jobs/{id}/assignment.
+	assignmentRepo := repository.NewInMemoryJobAssignmentRepository()
+	doseService := usecase.NewDoseService(logger, txManager, doseRepo, assignmentRepo, eventPublisher, domainMetrics)
+
+	// Dose recommendations are signed so they can be proven authentic in a dispute.
+	recommendationService := usecase.NewDoseRecommendationService(
+		logger,
+		dosingEngineFromEnv(logger),
+		signerFromEnv(logger, "DOSE_SIGNING_KEY"),
+		publicKeysFromEnv(logger, "DOSE_VERIFY_KEYS"),
+		repository.NewInMemoryDoseRecommendationRepository(),
+		domainMetrics,
+	)
+
+	// Tamper evidence: audit and dose logs are hash chained; heads are signed periodically.
+	chainService := usecase.NewAuditChainService(
+		logger,
+		txManager,
+		&hashchain.Signer{Signer: signerFromEnv(logger, "AUDIT_SIGNING_KEY")},
+		repository.NewInMemoryCheckpointRepository(),
+		auditRepo,
+		doseRepo,
+		getEnvDuration("AUDIT_CHECKPOINT_INTERVAL", usecase.DefaultCheckpointInterval),
+	)
+	go chainService.Run(ctx)
+
+	// Accounts live in a JSON file shared with the `users` CLI, which creates the first OWNER.
+	userRepo := repository.NewFileUserRepository(getEnvDefault("USERS_FILE", "users.json"))
+	// Wrong passwords and wrong MFA codes count toward the same lockout.
+	lockoutPolicy := usecase.LockoutPolicy{
+		MaxFailures: getEnvInt("LOGIN_MAX_FAILURES", usecase.DefaultLockoutPolicy.MaxFailures),
+		Duration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", usecase.DefaultLockoutPolicy.Duration),
+	}
+	userService := usecase.NewUserService(
+		logger,
+		userRepo,
+		auditService,
+		lockoutPolicy,
+	)
+	if users, err := userService.List(ctx); err != nil {
+		logger.Fatal("load users failed", zap.Error(err))
+	} else if len(users) == 0 {
+		logger.Warn("no user accounts; create the first OWNER with `pool-maintenance-api users bootstrap`")
+	}
+
+	// Second factor: TOTP, required for the roles in MFA_REQUIRED_ROLES.
+	mfaService := usecase.NewMFAService(
+		logger,
+		userRepo,
+		repository.NewInMemoryMFAChallengeRepository(),
+		auditService,
+		mfaPolicyFromEnv(),
+		lockoutPolicy,
+		getEnvDefault("MFA_ISSUER", "Pool Maintenance"),
+	)
+
+	// Sessions: login issues an access token plus a rotating refresh token.
+	sessionService := usecase.NewSessionService(
+		logger,
+		userService,
+		mfaService,
+		auth.NewIssuer(tokenSigner, authCfg),
+		repository.NewInMemoryRefreshTokenRepository(),
+		auditService,
+		getEnvDuration("REFRESH_TOKEN_TTL", auth.MaxRefreshTokenTTL),
+	)
+
+	// Every API route, with the roles and API key scope it admits, is in APIRoutes.
+	handlers := APIHandlers{
+		EventSchemas:    delivery.NewEventSchemaHandler(logger, eventRegistry),
+		Audit:           delivery.NewAuditHandler(logger, auditService, cursors),
+		AuditChain:      delivery.NewAuditChainHandler(logger, chainService),
+		Doses:           delivery.NewDoseHandler(logger, doseService, cursors),
+		JobAssignments:  delivery.NewJobAssignmentHandler(logger, usecase.NewJobAssignmentService(logger, txManager, assignmentRepo, userRepo, auditService)),
+		Alerts:          delivery.NewAlertHandler(logger, alertService, cursors),
+		Recommendations: delivery.NewDoseRecommendationHandler(logger, recommendationService),
+		Webhooks:        delivery.NewWebhookHandler(logger, webhookService, cursors),
+		Auth:            delivery.NewAuthHandler(logger, sessionService, mfaService),
+		APIKeys:         delivery.NewAPIKeyHandler(logger, apiKeyService),
+	}
+
+	// Single sign-on through an OpenID Connect provider, enabled by OIDC_ISSUER_URL.
+	if issuerURL := os.Getenv("OIDC_ISSUER_URL"); issuerURL != "" {
+		discoverCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		provider, err := auth.NewOIDCProvider(discoverCtx, auth.OIDCConfig{
+			IssuerURL:    issuerURL,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			Scopes:       splitList(getEnvDefault("OIDC_SCOPES", "profile,email")),
+			HTTPClient:   &http.Client{Timeout: 10 * time.Second, Transport: outboundTransport},
+		})
+		cancel()
+		if err != nil {
+			logger.Fatal("oidc provider setup failed", zap.String("issuer", issuerURL), zap.Error(err))
+		}
+		mapping, err := oidcRoleMappingFromEnv()
+		if err != nil {
+			logger.Fatal("invalid OIDC role mapping", zap.Error(err))
+		}
+		frontendURL, err := url.Parse(os.Getenv("OIDC_FRONTEND_URL"))
+		if err != nil || !frontendURL.IsAbs() || frontendURL.Fragment != "" {
+			logger.Fatal("OIDC_FRONTEND_URL must be an absolute URL without a fragment when OIDC_ISSUER_URL is set")
+		}
+		handlers.SSO = delivery.NewSSOHandler(logger, usecase.NewSSOService(
+			logger,
+			provider,
+			repository.NewInMemorySSOLoginStateRepository(),
+			repository.NewInMemorySSOHandoffRepository(),
+			userService,
+			sessionService,
+			auditService,
+			mapping,
+		), delivery.SSOHandlerConfig{
+			FrontendURL:  frontendURL.String(),
+			SecureCookie: strings.HasPrefix(os.Getenv("OIDC_REDIRECT_URL"), "https://"),
+		})
+		logger.Info("single sign-on enabled", zap.String("issuer", issuerURL))
+	}
+	RegisterRoutes(r, APIRoutes(handlers, RouteMiddleware{
+		Idempotent:      idempotent,
+		DoseComputation: doseComputationLimit,
+		Export:          exportLimit,
+	}))
+
 	logger.Info("starting server", zap.String("addr", ":8080"), zap.String("log_level", lvl.String()))
 	if err := r.Run(":8080"); err != nil {
 		logger.Fatal("server failed", zap.Error(err))
@@ -99,3 +421,220 @@ func getEnvDefault(key, def string) string {
 	}
 	return v
 }
+
+// webhookConfigFromEnv overlays WEBHOOK_* environment variables on the default retry policy.
+func webhookConfigFromEnv() usecase.WebhookConfig {
+	cfg := usecase.DefaultWebhookConfig()
+	cfg.MaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", cfg.MaxAttempts)
+	cfg.BaseBackoff = getEnvDuration("WEBHOOK_BASE_BACKOFF", cfg.BaseBackoff)
+	cfg.MaxBackoff = getEnvDuration("WEBHOOK_MAX_BACKOFF", cfg.MaxBackoff)
+	cfg.PollInterval = getEnvDuration("WEBHOOK_POLL_INTERVAL", cfg.PollInterval)
+	cfg.Timeout = getEnvDuration("WEBHOOK_TIMEOUT", cfg.Timeout)
+	cfg.SecretGracePeriod = getEnvDuration("WEBHOOK_SECRET_GRACE_PERIOD", cfg.SecretGracePeriod)
+	return cfg
+}
+
+// getEnvInt parses an integer env var, falling back to def when unset or invalid.
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvDuration parses a time.Duration env var (e.g. "30s"), falling back to def when unset or invalid.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// rateLimitFromEnv parses a rate limit budget env var written as "<burst>/<period>" (e.g.
+// "600/1m"), falling back to def when unset.
+func rateLimitFromEnv(logger *zap.Logger, key string, def domain.RateLimit) domain.RateLimit {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	burst, period, ok := strings.Cut(v, "/")
+	n, err := strconv.Atoi(burst)
+	d, derr := time.ParseDuration(period)
+	if !ok || err != nil || derr != nil || n <= 0 || d <= 0 {
+		logger.Fatal(key+` must look like "600/1m"`, zap.String("value", v))
+	}
+	def.Burst, def.Period = n, d
+	return def
+}
+
+// rateLimitStoreFromEnv returns the store named by RATE_LIMIT_STORE: "memory" (default),
+// which enforces budgets per replica, or "redis", shared by every replica through REDIS_URL.
+func rateLimitStoreFromEnv(ctx context.Context, logger *zap.Logger) domain.RateLimitStore {
+	switch kind := getEnvDefault("RATE_LIMIT_STORE", "memory"); kind {
+	case "memory":
+		return repository.NewInMemoryRateLimitStore()
+	case "redis":
+		opts, err := redis.ParseURL(os.Getenv("REDIS_URL"))
+		if err != nil {
+			logger.Fatal("REDIS_URL must be a redis:// or rediss:// URL when RATE_LIMIT_STORE=redis", zap.Error(err))
+		}
+		client := redis.NewClient(opts)
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := client.Ping(pingCtx).Err(); err != nil {
+			// The limiter fails open, so the API still serves while Redis is down.
+			logger.Error("redis unreachable; rate limits are not enforced until it is", zap.String("addr", opts.Addr), zap.Error(err))
+		}
+		return repository.NewRedisRateLimitStore(client, "ratelimit:")
+	default:
+		logger.Fatal(`RATE_LIMIT_STORE must be "memory" or "redis"`, zap.String("value", kind))
+		return nil
+	}
+}
+
+// dosingEngineFromEnv returns the engine named by DOSING_ENGINE: "stub" (default), which
+// recommends nothing until the real engine ships, or "none", which turns recommendations off.
+func dosingEngineFromEnv(logger *zap.Logger) domain.DosingEngine {
+	switch kind := getEnvDefault("DOSING_ENGINE", "stub"); kind {
+	case "stub":
+		logger.Warn("dosing engine is a stub; recommendations list no doses", zap.String("engine_version", usecase.StubDosingEngine{}.Version()))
+		return usecase.StubDosingEngine{}
+	case "none":
+		return nil
+	default:
+		logger.Fatal(`DOSING_ENGINE must be "stub" or "none"`, zap.String("value", kind))
+		return nil
+	}
+}
+
+// signerFromEnv loads an Ed25519 key from the named env var (base64 of the 32-byte seed).
+// Without it an ephemeral key is generated, which is fine for development but means
+// signatures cannot be verified after a restart.
+func signerFromEnv(logger *zap.Logger, name string) *signing.Signer {
+	seed, err := base64.StdEncoding.DecodeString(os.Getenv(name))
+	if err != nil {
+		logger.Fatal(name+" is not valid base64", zap.Error(err))
+	}
+	if len(seed) == 0 {
+		seed = make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			logger.Fatal("generate signing key", zap.String("env", name), zap.Error(err))
+		}
+		logger.Warn(name + " not set; using an ephemeral signing key")
+	}
+	signer, err := signing.NewSigner(seed)
+	if err != nil {
+		logger.Fatal("invalid "+name, zap.Error(err))
+	}
+	logger.Info("signing key loaded",
+		zap.String("env", name),
+		zap.String("key_id", signer.KeyID()),
+		zap.String("public_key", base64.StdEncoding.EncodeToString(signer.PublicKey())),
+	)
+	return signer
+}
+
+// publicKeysFromEnv reads a comma-separated list of base64 Ed25519 public keys, such as the
+// keys a signing key replaced. An unset variable means none.
+func publicKeysFromEnv(logger *zap.Logger, name string) []ed25519.PublicKey {
+	var keys []ed25519.PublicKey
+	for _, field := range strings.Split(os.Getenv(name), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(field)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			logger.Fatal(fmt.Sprintf("%s entries must be base64 of a %d-byte Ed25519 public key", name, ed25519.PublicKeySize))
+		}
+		keys = append(keys, raw)
+		logger.Info("verification key loaded", zap.String("env", name), zap.String("key_id", signing.KeyID(raw)))
+	}
+	return keys
+}
+
+// secretFromEnv reads a base64 HMAC key of at least 32 bytes, or generates an ephemeral one.
+func secretFromEnv(logger *zap.Logger, name string) []byte {
+	key, err := base64.StdEncoding.DecodeString(os.Getenv(name))
+	if err != nil {
+		logger.Fatal(name+" is not valid base64", zap.Error(err))
+	}
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logger.Fatal("generate key", zap.String("env", name), zap.Error(err))
+		}
+		logger.Warn(name + " not set; using an ephemeral key")
+	}
+	if len(key) < 32 {
+		logger.Fatal(name + " must be at least 32 bytes")
+	}
+	return key
+}
+
+// authConfigFromEnv reads the JWT issuer, audience and access token lifetime.
+func authConfigFromEnv() auth.Config {
+	return auth.Config{
+		Issuer:   getEnvDefault("JWT_ISSUER", "pool-maintenance-api"),
+		Audience: getEnvDefault("JWT_AUDIENCE", "pool-maintenance-api"),
+		TTL:      getEnvDuration("ACCESS_TOKEN_TTL", auth.MaxAccessTokenTTL),
+	}
+}
+
+// mfaPolicyFromEnv reads MFA_REQUIRED_ROLES, a comma separated role list. "none" turns the
+// requirement off; users who enrolled anyway are still challenged.
+func mfaPolicyFromEnv() usecase.MFAPolicy {
+	raw, ok := os.LookupEnv("MFA_REQUIRED_ROLES")
+	if !ok {
+		return usecase.DefaultMFAPolicy
+	}
+	var p usecase.MFAPolicy
+	for _, r := range splitList(raw) {
+		if r = strings.ToUpper(r); r != "NONE" {
+			p.RequiredRoles = append(p.RequiredRoles, r)
+		}
+	}
+	return p
+}
+
+// oidcRoleMappingFromEnv reads how ID token claims map to roles. OIDC_ROLE_MAP is a comma
+// separated list of value=ROLE pairs matched against the OIDC_ROLES_CLAIM claim; a value may
+// appear more than once to grant several roles. OIDC_DEFAULT_ROLES applies when nothing
+// matches and is empty by default, so unmapped users are refused.
+func oidcRoleMappingFromEnv() (usecase.RoleMapping, error) {
+	m := usecase.DefaultRoleMapping
+	m.Claim = getEnvDefault("OIDC_ROLES_CLAIM", m.Claim)
+	m.Map = map[string][]string{}
+	for _, pair := range splitList(os.Getenv("OIDC_ROLE_MAP")) {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return m, fmt.Errorf("OIDC_ROLE_MAP entry %q must be value=ROLE", pair)
+		}
+		value, role := pair[:i], strings.ToUpper(pair[i+1:])
+		if !slices.Contains(domain.AllRoles, role) {
+			return m, fmt.Errorf("OIDC_ROLE_MAP entry %q: unknown role %q", pair, role)
+		}
+		m.Map[value] = append(m.Map[value], role)
+	}
+	for _, r := range splitList(os.Getenv("OIDC_DEFAULT_ROLES")) {
+		r = strings.ToUpper(r)
+		if !slices.Contains(domain.AllRoles, r) {
+			return m, fmt.Errorf("OIDC_DEFAULT_ROLES: unknown role %q", r)
+		}
+		m.Default = append(m.Default, r)
+	}
+	return m, nil
+}
+
+// splitList splits a comma separated list, trimming space and dropping empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/delivery"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+)
+
+// Route is one API endpoint and who may call it: users holding one of Roles, and API keys
+// granted Scope. A route without Roles has no guard; it must lie under
+// middleware.PublicPaths, where Auth admits anonymous callers.
+type Route struct {
+	Method   string
+	Path     string
+	Scope    string
+	Roles    []string
+	Handlers []gin.HandlerFunc
+}
+
+// APIHandlers are the handlers behind the API routes.
+type APIHandlers struct {
+	EventSchemas    *delivery.EventSchemaHandler
+	Audit           *delivery.AuditHandler
+	AuditChain      *delivery.AuditChainHandler
+	Doses           *delivery.DoseHandler
+	JobAssignments  *delivery.JobAssignmentHandler
+	Alerts          *delivery.AlertHandler
+	Recommendations *delivery.DoseRecommendationHandler
+	Webhooks        *delivery.WebhookHandler
+	Auth            *delivery.AuthHandler
+	APIKeys         *delivery.APIKeyHandler
+	// SSO is nil when single sign-on is off, which leaves its routes out.
+	SSO *delivery.SSOHandler
+}
+
+// RouteMiddleware is what some routes run between the access check and the handler.
+type RouteMiddleware struct {
+	// Idempotent replays the first response of a creating POST (E-API-005).
+	Idempotent gin.HandlerFunc
+	// DoseComputation and Export are the rate limits of expensive routes.
+	DoseComputation gin.HandlerFunc
+	Export          gin.HandlerFunc
+}
+
+// APIRoutes is the route table of /api/v1. The access matrix in docs/auth.md documents it.
+func APIRoutes(h APIHandlers, mw RouteMiddleware) []Route {
+	chain := func(handlers ...gin.HandlerFunc) []gin.HandlerFunc { return handlers }
+	routes := []Route{
+		{http.MethodGet, "/api/v1/events/schemas", domain.ScopeEventsRead, domain.AllRoles, chain(h.EventSchemas.List)},
+		{http.MethodGet, "/api/v1/audit", domain.ScopeAuditRead, domain.AdminRoles, chain(h.Audit.List)},
+		{http.MethodGet, "/api/v1/admin/audit/export", domain.ScopeExportsRead, domain.AdminRoles, chain(mw.Export, h.AuditChain.Export)},
+
+		{http.MethodPost, "/api/v1/jobs/:id/doses", domain.ScopeJobsWrite, domain.StaffRoles, chain(mw.Idempotent, h.Doses.Record)},
+		{http.MethodGet, "/api/v1/jobs/:id/doses", domain.ScopeJobsRead, domain.StaffRoles, chain(h.Doses.List)},
+		// The assignment decides which jobs a TECH may act on, so a TECH can not change it.
+		{http.MethodPut, "/api/v1/jobs/:id/assignment", domain.ScopeJobsWrite, []string{domain.RoleOwner, domain.RoleDispatcher}, chain(h.JobAssignments.Assign)},
+		// Alerts span every job, so a TECH, who may only see jobs on their route, can not list them.
+		{http.MethodGet, "/api/v1/alerts", domain.ScopeJobsRead, []string{domain.RoleOwner, domain.RoleDispatcher}, chain(h.Alerts.List)},
+		{http.MethodPost, "/api/v1/alerts/:id/acknowledge", domain.ScopeJobsWrite, []string{domain.RoleOwner, domain.RoleDispatcher}, chain(h.Alerts.Acknowledge)},
+
+		{http.MethodPost, "/api/v1/dose-recommendations", domain.ScopeRecommendationsWrite, domain.StaffRoles, chain(mw.DoseComputation, mw.Idempotent, h.Recommendations.Recommend)},
+		{http.MethodGet, "/api/v1/dose-recommendations/:id", domain.ScopeRecommendationsRead, domain.StaffRoles, chain(h.Recommendations.Get)},
+		// Anyone holding a recommendation document, customers included, may check it.
+		{http.MethodPost, "/api/v1/dose-recommendations/verify", domain.ScopeRecommendationsRead, domain.AllRoles, chain(h.Recommendations.Verify)},
+
+		{http.MethodPost, "/api/v1/admin/webhooks/subscriptions", "", domain.AdminRoles, chain(mw.Idempotent, h.Webhooks.CreateSubscription)},
+		{http.MethodGet, "/api/v1/admin/webhooks/subscriptions", "", domain.AdminRoles, chain(h.Webhooks.ListSubscriptions)},
+		{http.MethodDelete, "/api/v1/admin/webhooks/subscriptions/:id", "", domain.AdminRoles, chain(h.Webhooks.DeleteSubscription)},
+		{http.MethodPost, "/api/v1/admin/webhooks/subscriptions/:id/rotate-secret", "", domain.AdminRoles, chain(h.Webhooks.RotateSecret)},
+		{http.MethodGet, "/api/v1/admin/webhooks/deliveries", "", domain.AdminRoles, chain(h.Webhooks.ListDeliveries)},
+		{http.MethodGet, "/api/v1/admin/webhooks/deliveries/:id", "", domain.AdminRoles, chain(h.Webhooks.GetDelivery)},
+		{http.MethodPost, "/api/v1/admin/webhooks/deliveries/:id/redeliver", "", domain.AdminRoles, chain(mw.Idempotent, h.Webhooks.Redeliver)},
+		{http.MethodDelete, "/api/v1/admin/users/:id/sessions", "", domain.AdminRoles, chain(h.Auth.RevokeUserSessions)},
+
+		// API keys for integration partners; only an OWNER may manage them.
+		{http.MethodPost, "/api/v1/api-keys", "", domain.AdminRoles, chain(h.APIKeys.Create)},
+		{http.MethodGet, "/api/v1/api-keys", "", domain.AdminRoles, chain(h.APIKeys.List)},
+		{http.MethodDelete, "/api/v1/api-keys/:id", "", domain.AdminRoles, chain(h.APIKeys.Revoke)},
+
+		// The token endpoints authenticate with credentials, a challenge or a refresh token.
+		{http.MethodPost, "/api/v1/auth/login", "", nil, chain(h.Auth.Login)},
+		{http.MethodPost, "/api/v1/auth/mfa/enroll", "", nil, chain(h.Auth.EnrollMFA)},
+		{http.MethodPost, "/api/v1/auth/mfa/verify", "", nil, chain(h.Auth.VerifyMFA)},
+		{http.MethodPost, "/api/v1/auth/refresh", "", nil, chain(h.Auth.Refresh)},
+		{http.MethodPost, "/api/v1/auth/logout", "", nil, chain(h.Auth.Logout)},
+	}
+	if h.SSO != nil {
+		routes = append(routes,
+			Route{http.MethodGet, "/api/v1/auth/oidc/login", "", nil, chain(h.SSO.Login)},
+			Route{http.MethodGet, "/api/v1/auth/oidc/callback", "", nil, chain(h.SSO.Callback)},
+			Route{http.MethodPost, "/api/v1/auth/oidc/token", "", nil, chain(h.SSO.Token)},
+		)
+	}
+	return routes
+}
+
+// RegisterRoutes adds routes to r, each behind middleware.Allow with its scope and roles.
+func RegisterRoutes(r gin.IRoutes, routes []Route) {
+	for _, rt := range routes {
+		handlers := rt.Handlers
+		if len(rt.Roles) > 0 {
+			handlers = append([]gin.HandlerFunc{middleware.Allow(rt.Scope, rt.Roles...)}, handlers...)
+		}
+		r.Handle(rt.Method, rt.Path, handlers...)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+const usersUsage = `Usage: pool-maintenance-api users <command> [flags]
+
+Manages accounts in USERS_FILE (default users.json) without a running server. Passwords are
+read from the first line of stdin.
+
+Commands:
+  bootstrap     --username NAME               Create the first OWNER (only when no users exist)
+  add           --username NAME --role ROLE   Create a user; repeat --role for several
+  list                                        List users
+  set-password  --username NAME               Replace a password and clear any lockout
+  set-roles     --username NAME --role ROLE   Replace a user's roles
+  disable       --username NAME               Block logins and token refresh
+  enable        --username NAME               Undo disable
+  unlock        --username NAME               Clear a lockout early
+  reset-mfa     --username NAME               Remove the user's authenticator and recovery codes
+
+Roles: OWNER, DISPATCHER, TECH, CUSTOMER
+`
+
+// runUsers implements `users`. It exits 0 on success, 1 when the operation fails (duplicate
+// or unknown user, password policy, unreadable file) and 2 on usage errors.
+func runUsers(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usersUsage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("users "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	file := fs.String("file", getEnvDefault("USERS_FILE", "users.json"), "user file")
+	username := fs.String("username", "", "username")
+	var roles stringList
+	fs.Var(&roles, "role", "role; repeat for several")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	svc := usecase.NewUserService(zap.NewNop(), repository.NewFileUserRepository(*file), nil, usecase.DefaultLockoutPolicy)
+	ctx := context.Background()
+	needUser := func() bool {
+		if *username == "" {
+			fmt.Fprintf(stderr, "users %s: --username is required\n", cmd)
+			return false
+		}
+		return true
+	}
+
+	var err error
+	switch cmd {
+	case "list":
+		return listUsers(ctx, svc, stdout, stderr)
+	case "bootstrap", "add", "set-password":
+		if !needUser() {
+			return 2
+		}
+		var pw string
+		if pw, err = readPassword(stdin); err != nil {
+			fmt.Fprintf(stderr, "users %s: %v\n", cmd, err)
+			return 2
+		}
+		switch cmd {
+		case "bootstrap":
+			_, err = svc.Bootstrap(ctx, *username, pw)
+		case "add":
+			_, err = svc.Create(ctx, usecase.CreateUserInput{Username: *username, Password: pw, Roles: roles})
+		default:
+			err = svc.SetPassword(ctx, *username, pw)
+		}
+	case "set-roles":
+		if !needUser() {
+			return 2
+		}
+		err = svc.SetRoles(ctx, *username, roles)
+	case "disable", "enable":
+		if !needUser() {
+			return 2
+		}
+		err = svc.SetDisabled(ctx, *username, cmd == "disable")
+	case "unlock":
+		if !needUser() {
+			return 2
+		}
+		err = svc.Unlock(ctx, *username)
+	case "reset-mfa":
+		if !needUser() {
+			return 2
+		}
+		err = svc.ResetMFA(ctx, *username)
+	default:
+		fmt.Fprintf(stderr, "users: unknown command %q\n\n%s", cmd, usersUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "users %s: %v\n", cmd, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "OK: %s %s\n", cmd, *username)
+	return 0
+}
+
+func listUsers(ctx context.Context, svc *usecase.UserService, stdout, stderr io.Writer) int {
+	users, err := svc.List(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "users list: %v\n", err)
+		return 1
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tROLES\tMFA\tSTATUS")
+	now := time.Now()
+	for _, u := range users {
+		mfa, status := "no", "active"
+		if u.MFAEnrolled() {
+			mfa = "yes"
+		}
+		switch {
+		case u.Disabled:
+			status = "disabled"
+		case u.Locked(now):
+			status = "locked until " + u.LockedUntil.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", u.ID, u.Username, strings.Join(u.Roles, ","), mfa, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// readPassword returns the first line of stdin without its line ending.
+func readPassword(stdin io.Reader) (string, error) {
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("password must be given on stdin")
+	}
+	return line, nil
+}
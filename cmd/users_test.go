@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunUsers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "users.json")
+	run := func(stdin string, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runUsers(append(args, "--file", file), strings.NewReader(stdin), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, _, errOut := run("", "bootstrap", "--username", "owner"); code != 2 {
+		t.Fatalf("bootstrap without password: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("short\n", "bootstrap", "--username", "owner"); code != 1 || !strings.Contains(errOut, "at least 12") {
+		t.Fatalf("weak password: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("correct horse battery\n", "bootstrap", "--username", "owner"); code != 0 {
+		t.Fatalf("bootstrap: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("another long password\n", "bootstrap", "--username", "owner2"); code != 1 || !strings.Contains(errOut, "already exist") {
+		t.Fatalf("second bootstrap: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("tech password 123\n", "add", "--username", "tina", "--role", "TECH"); code != 0 {
+		t.Fatalf("add: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("", "set-roles", "--username", "tina", "--role", "ADMIN"); code != 1 || !strings.Contains(errOut, "unknown role") {
+		t.Fatalf("set-roles with bad role: exit %d, stderr %q", code, errOut)
+	}
+	if code, _, errOut := run("", "disable", "--username", "tina"); code != 0 {
+		t.Fatalf("disable: exit %d, stderr %q", code, errOut)
+	}
+
+	code, out, _ := run("", "list")
+	if code != 0 {
+		t.Fatalf("list: exit %d", code)
+	}
+	for _, want := range []string{"owner", "OWNER", "active", "tina", "TECH", "disabled"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "argon2id") {
+		t.Errorf("list must not print password hashes:\n%s", out)
+	}
+
+	if code, _, _ := run("", "frobnicate"); code != 2 {
+		t.Errorf("unknown command: exit %d, want 2", code)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+)
+
+// runVerifyAudit implements `verify-audit`. It reads a chain export (GET
+// /api/v1/admin/audit/export) from --file or stdin, walks both chains and reports the first
+// break in each. It exits 0 when both chains verify, 1 on a break, and 2 on usage errors.
+// The checkpoint public key comes from --public-key or AUDIT_PUBLIC_KEY.
+func runVerifyAudit(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify-audit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	file := fs.String("file", "", "chain export JSON (default: stdin)")
+	pubKey := fs.String("public-key", os.Getenv("AUDIT_PUBLIC_KEY"), "base64 Ed25519 public key that signs checkpoints")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var pub ed25519.PublicKey
+	if *pubKey != "" {
+		raw, err := base64.StdEncoding.DecodeString(*pubKey)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			fmt.Fprintf(stderr, "verify-audit: --public-key must be base64 of a %d-byte Ed25519 key\n", ed25519.PublicKeySize)
+			return 2
+		}
+		pub = raw
+	}
+
+	in := stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(stderr, "verify-audit: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	var exp domain.ChainExport
+	if err := json.NewDecoder(in).Decode(&exp); err != nil {
+		fmt.Fprintf(stderr, "verify-audit: decode export: %v\n", err)
+		return 2
+	}
+
+	if pub == nil {
+		fmt.Fprintln(stdout, "WARNING: no public key given; checkpoint signatures were not checked")
+	}
+	code := 0
+	for _, res := range hashchain.VerifyExport(&exp, pub) {
+		if res.Break != nil {
+			fmt.Fprintf(stdout, "BROKEN: %v\n", res.Break)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "OK: %s chain, %d records, %d checkpoints\n", res.Chain, res.Records, res.Checkpoints)
+	}
+	return code
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func TestRunVerifyAudit(t *testing.T) {
+	signer, err := hashchain.NewSigner(bytes.Repeat([]byte{3}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo := repository.NewInMemoryAuditRepository()
+	audit := usecase.NewAuditService(zap.NewNop(), auditRepo)
+	for _, id := range []string{"u1", "u2", "u3"} {
+		if _, err := audit.Record(context.Background(), usecase.AuditEntry{ActorID: id, ActionType: "LOGIN_SUCCEEDED", EntityType: "user", EntityID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chain := usecase.NewAuditChainService(zap.NewNop(), repository.NewInMemoryTxManager(), signer, repository.NewInMemoryCheckpointRepository(), auditRepo, repository.NewInMemoryDoseEventRepository(), 0)
+	if _, err := chain.Checkpoint(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exp, _ := chain.Export(context.Background())
+	intact, _ := json.Marshal(exp)
+	exp.AuditEvents[1].ActorID = "mallory"
+	tampered, _ := json.Marshal(exp)
+
+	dir := t.TempDir()
+	intactFile := filepath.Join(dir, "intact.json")
+	if err := os.WriteFile(intactFile, intact, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pub := base64.StdEncoding.EncodeToString(signer.PublicKey())
+
+	cases := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    int
+		wantOut string
+	}{
+		{"intact from file", []string{"--file", intactFile, "--public-key", pub}, "", 0, "OK: audit chain, 3 records, 1 checkpoints"},
+		{"tampered from stdin", []string{"--public-key", pub}, string(tampered), 1, "audit chain broken at sequence 2"},
+		{"no key still checks links", nil, string(intact), 0, "WARNING"},
+		{"bad key", []string{"--public-key", "nope"}, string(intact), 2, ""},
+		{"not json", nil, "garbage", 2, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("AUDIT_PUBLIC_KEY", "")
+			var out, errOut bytes.Buffer
+			got := runVerifyAudit(tc.args, strings.NewReader(tc.stdin), &out, &errOut)
+			if got != tc.want {
+				t.Fatalf("exit code = %d, want %d (stdout=%q stderr=%q)", got, tc.want, out.String(), errOut.String())
+			}
+			if !strings.Contains(out.String(), tc.wantOut) {
+				t.Fatalf("stdout %q does not contain %q", out.String(), tc.wantOut)
+			}
+		})
+	}
+}
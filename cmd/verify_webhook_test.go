@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/pkg/webhooksig"
+)
+
+func TestRunVerifyWebhook(t *testing.T) {
+	body := `{"event":"JobCompleted"}`
+	ts := time.Unix(1759665600, 0)
+	sig := webhooksig.Sign("new", ts, []byte(body))
+	tsArg := strconv.FormatInt(ts.Unix(), 10)
+
+	cases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"valid with rotation", []string{"--secret", "old", "--secret", "new", "--timestamp", tsArg, "--signature", sig, "--now", tsArg}, 0},
+		{"wrong secret", []string{"--secret", "old", "--timestamp", tsArg, "--signature", sig, "--now", tsArg}, 1},
+		{"outside replay window", []string{"--secret", "new", "--timestamp", tsArg, "--signature", sig, "--now", strconv.FormatInt(ts.Add(10*time.Minute).Unix(), 10)}, 1},
+		{"missing signature", []string{"--secret", "new", "--timestamp", tsArg}, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WEBHOOK_SECRETS", "")
+			var out, errOut bytes.Buffer
+			got := runVerifyWebhook(tc.args, strings.NewReader(body), &out, &errOut)
+			if got != tc.want {
+				t.Fatalf("exit code = %d, want %d (stdout=%q stderr=%q)", got, tc.want, out.String(), errOut.String())
+			}
+		})
+	}
+}
+
+func TestRunVerifyWebhook_OversizedBody(t *testing.T) {
+	body := strings.Repeat("a", webhooksig.MaxBodyBytes+1)
+	ts := time.Unix(1759665600, 0)
+	tsArg := strconv.FormatInt(ts.Unix(), 10)
+	t.Setenv("WEBHOOK_SECRETS", "")
+
+	var out, errOut bytes.Buffer
+	code := runVerifyWebhook([]string{"--secret", "s", "--timestamp", tsArg, "--signature", webhooksig.Sign("s", ts, []byte(body)), "--now", tsArg}, strings.NewReader(body), &out, &errOut)
+	if code != 2 || !strings.Contains(errOut.String(), "body exceeds") {
+		t.Fatalf("exit code = %d, stdout=%q stderr=%q", code, out.String(), errOut.String())
+	}
+}
+
+func TestRunVerifyWebhook_SecretsFromEnv(t *testing.T) {
+	body := `{}`
+	ts := time.Unix(1759665600, 0)
+	tsArg := strconv.FormatInt(ts.Unix(), 10)
+	t.Setenv("WEBHOOK_SECRETS", "a, b")
+
+	var out, errOut bytes.Buffer
+	code := runVerifyWebhook([]string{"--timestamp", tsArg, "--signature", webhooksig.Sign("b", ts, []byte(body)), "--now", tsArg}, strings.NewReader(body), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stdout=%q stderr=%q", code, out.String(), errOut.String())
+	}
+}
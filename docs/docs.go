@@ -24,49 +24,2596 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/admin/audit/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every AuditEvent and DoseEvent in chain order plus signed checkpoints. Feed the response to ` + "`" + `pool-maintenance-api verify-audit` + "`" + `.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Export audit and dose hash chains",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChainExport"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes all refresh tokens of a user. Access tokens already issued expire within 15 minutes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke user sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.RevokeSessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns delivery history with attempt counts and status. Filter by subscription or status. Pages are cursor based (E-API-004).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription id",
+                        "name": "subscription_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "PENDING",
+                            "DELIVERED",
+                            "FAILED",
+                            "DEAD_LETTERED"
+                        ],
+                        "type": "string",
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "next_attempt_at",
+                            "-next_attempt_at"
+                        ],
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Sort key, - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one delivery including every attempt (status code, error, duration).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a fresh delivery (new id, full retry budget) carrying the original payload. Works for any status, including DEAD_LETTERED.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery id to replay",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response when retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.WebhookSubscriptionListResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers an endpoint for the given event types, or for every event when none are listed. The response carries the HMAC signing secret; it is returned once and never listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.CreateWebhookSubscriptionRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response when retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.WebhookSubscriptionCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/subscriptions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops deliveries to the endpoint. Deliveries already queued for it are dead-lettered when they come due.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/subscriptions/{id}/rotate-secret": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a new HMAC signing secret, returned once. Until previous_secret_expires_at (WEBHOOK_SECRET_GRACE_PERIOD) deliveries are signed with both the new and the previous secret, so the receiver can switch over without rejecting any. Rotating again within that period retires the previous secret at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Rotate webhook secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.WebhookSecretRotatedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns chemistry alerts (E-DOM-006). Sorting by severity orders by urgency, then time. Pages are cursor based (E-API-004): pass next_cursor with the same filters and sort to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "job_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "INFO",
+                            "WARNING",
+                            "CRITICAL"
+                        ],
+                        "type": "string",
+                        "description": "Severity",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Acknowledged",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "severity",
+                            "-severity"
+                        ],
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Sort key, - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size (1-1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.AlertListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/alerts/{id}/acknowledge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Records who acknowledged the alert and when (E-DOM-006). Acknowledging an acknowledged alert returns it unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Acknowledge alert",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alert id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Alert"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.APIKeyListResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a scoped, expiring API key for a machine client. The key is returned once; only its hash is stored. Send it as ` + "`" + `Authorization: Bearer pmk_…` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Key",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.APIKeyCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns append-only audit events (E-AUD-001). Filter by actor, entity and time range; from is inclusive, to is exclusive. Pages are cursor based (E-API-004): pass next_cursor with the same filters and sort to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query audit trail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Actor id",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "dose_event",
+                        "description": "Entity type",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity id",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of range (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of range (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "occurred_at",
+                            "-occurred_at",
+                            "sequence",
+                            "-sequence"
+                        ],
+                        "type": "string",
+                        "default": "-occurred_at",
+                        "description": "Sort key, - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size (1-1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.AuditEventListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/login": {
+            "post": {
+                "description": "Returns a 15 minute access token and a single-use refresh token, or 202 with an MFA challenge when the account needs a second factor. Successful and failed attempts are audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.TokenResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.MFAChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/logout": {
+            "post": {
+                "description": "Revokes the refresh token and every token rotated from the same login. Unknown tokens are accepted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/mfa/enroll": {
+            "post": {
+                "description": "Returns a new TOTP secret as an otpauth URI and QR code. It becomes active when /auth/mfa/verify accepts its first code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Enroll TOTP authenticator",
+                "parameters": [
+                    {
+                        "description": "MFA challenge token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.MFAEnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.MFAEnrollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/mfa/verify": {
+            "post": {
+                "description": "Completes login with a TOTP code or a single-use recovery code. The first code after enrollment activates the authenticator and returns recovery codes once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify second factor",
+                "parameters": [
+                    {
+                        "description": "MFA challenge token and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.MFAVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/callback": {
+            "get": {
+                "description": "The identity provider redirects here. The state must match the cookie set by the login redirect. The ID token is validated, its claims are mapped to roles, and the account is created on first login. The browser is then redirected to the front end with a one-time code in the fragment, which the front end exchanges at /api/v1/auth/oidc/token.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete single sign-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "State from the login redirect",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/login": {
+            "get": {
+                "description": "Redirects to the configured OpenID Connect provider using the authorization code flow with PKCE. The login's state is also set in an HttpOnly cookie, which the callback requires.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start single sign-on",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/token": {
+            "post": {
+                "description": "Exchanges the one-time code the callback put in the front end's URL fragment for access and refresh tokens. A code is valid for one minute and can be used once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Redeem single sign-on code",
+                "parameters": [
+                    {
+                        "description": "One-time code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.SSORedeemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access and refresh token. Presenting an already used refresh token revokes the whole session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/dose-recommendations": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the dosing engine and signs inputs, engine version and outputs with Ed25519.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doses"
+                ],
+                "summary": "Create signed dose recommendation",
+                "parameters": [
+                    {
+                        "description": "Engine inputs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DoseRecommendationInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response when retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DoseRecommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/dose-recommendations/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks that a recommendation document is byte-for-byte what the engine produced and signed. A tampered document returns valid=false. Fields the document does not define are rejected with 400, since the signature could not vouch for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doses"
+                ],
+                "summary": "Verify dose recommendation",
+                "parameters": [
+                    {
+                        "description": "Recommendation document as returned by the API",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.DoseRecommendation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.VerifyRecommendationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/dose-recommendations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doses"
+                ],
+                "summary": "Get dose recommendation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recommendation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DoseRecommendation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/schemas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the JSON Schema of every outbound event version (JobCompleted, DoseRecorded, InvoicePaid, AlertRaised, ...). Published versions only change additively; breaking changes ship as a new version.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "List event schemas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event type, e.g. DoseRecorded",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.EventSchemaListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/jobs/{id}/assignment": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Routes the job to an enabled TECH, who may then record and list its doses. Replaces any previous assignment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Assign job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Technician",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.AssignJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.JobAssignment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/jobs/{id}/doses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pages are cursor based (E-API-004): pass next_cursor with the same sort to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doses"
+                ],
+                "summary": "List doses for a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "parameter",
+                            "-parameter"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Sort key, - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size (1-1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.DoseEventListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Persists a DoseEvent (E-DOM-004) and, in the same transaction, a DoseRecorded event for partners.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doses"
+                ],
+                "summary": "Record dose",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dose",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/delivery.RecordDoseRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response when retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DoseEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
-                "description": "Returns service health and version info. Useful for uptime monitoring, CI/CD, and debugging.\n\n**Example GitHub Actions usage:**\nA step in your CI/CD pipeline to verify deployment.\n` + "`" + `` + "`" + `` + "`" + `yaml\n- name: Check service health\nuses: jtalk/url-health-check-action@v4\nwith:\nurl: https://your-app.com/health/live\nmax-attempts: 10\nretry-delay: 5s\n` + "`" + `` + "`" + `` + "`" + `",
+                "description": "Returns service health and version info. Useful for uptime monitoring, CI/CD, and debugging.\n\n**Example GitHub Actions usage:**\nA step in your CI/CD pipeline to verify deployment.\n` + "`" + `` + "`" + `` + "`" + `yaml\n- name: Check service health\nuses: jtalk/url-health-check-action@v4\nwith:\nurl: https://your-app.com/health/live\nmax-attempts: 10\nretry-delay: 5s\n` + "`" + `` + "`" + `` + "`" + `",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.HealthCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Returns 200 if the process is running. Avoids external dependency checks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.HealthCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Indicates whether the service is ready to accept traffic based on dependency health.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "health"
                 ],
-                "summary": "Health check",
+                "summary": "Readiness probe",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/delivery.HealthCheckResponse"
+                            "$ref": "#/definitions/delivery.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/delivery.ReadinessResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "delivery.HealthCheckResponse": {
+        }
+    },
+    "definitions": {
+        "delivery.APIKeyCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "pmk_1a2b3c4d_9Zr…"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "delivery.APIKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.APIKey"
+                    }
+                }
+            }
+        },
+        "delivery.AlertListResponse": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Alert"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; absent on the last page.",
+                    "type": "string",
+                    "example": "eyJmIjoi...In19.Yk3c..."
+                }
+            }
+        },
+        "delivery.AssignJobRequest": {
+            "type": "object",
+            "required": [
+                "technician_id"
+            ],
+            "properties": {
+                "technician_id": {
+                    "type": "string",
+                    "example": "u-42"
+                }
+            }
+        },
+        "delivery.AuditEventListResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEvent"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; absent on the last page.",
+                    "type": "string",
+                    "example": "eyJmIjoi...In19.Yk3c..."
+                }
+            }
+        },
+        "delivery.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "ExpiresInDays defaults to 90 and may be at most 365.",
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme HOA integration"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "jobs:read",
+                        "exports:read"
+                    ]
+                }
+            }
+        },
+        "delivery.CreateWebhookSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "target_url"
+            ],
+            "properties": {
+                "event_types": {
+                    "description": "EventTypes limits the subscription to these events; empty means every event.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "JobCompleted",
+                        "DoseRecorded"
+                    ]
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/pool"
+                }
+            }
+        },
+        "delivery.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "timeout"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "db"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "delivery.DoseEventListResponse": {
+            "type": "object",
+            "properties": {
+                "dose_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DoseEvent"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; absent on the last page.",
+                    "type": "string",
+                    "example": "eyJmIjoi...In19.Yk3c..."
+                }
+            }
+        },
+        "delivery.EventSchemaListResponse": {
+            "type": "object",
+            "properties": {
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.Schema"
+                    }
+                }
+            }
+        },
+        "delivery.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "build_date": {
+                    "type": "string",
+                    "example": "2025-08-25T12:34:56Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "abc1234"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "uptime_seconds": {
+                    "type": "number",
+                    "example": 123.45
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "delivery.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.MFAChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "enrollment_required": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.MFAEnrollRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.MFAEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "qr_code_png": {
+                    "type": "string",
+                    "format": "base64"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.MFAVerifyRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "build_date": {
+                    "type": "string",
+                    "example": "2025-08-25T12:34:56Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "abc1234"
+                },
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/delivery.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
+                }
+            }
+        },
+        "delivery.RecordDoseRequest": {
+            "type": "object",
+            "required": [
+                "actual_amount",
+                "parameter",
+                "product_id",
+                "unit"
+            ],
+            "properties": {
+                "actual_amount": {
+                    "type": "number",
+                    "example": 30
+                },
+                "after_value": {
+                    "type": "number",
+                    "example": 3
+                },
+                "before_value": {
+                    "type": "number",
+                    "example": 1.2
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "FC"
+                },
+                "product_id": {
+                    "type": "string",
+                    "example": "liquid-chlorine-12.5"
+                },
+                "recommended_amount": {
+                    "type": "number",
+                    "example": 32
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "oz"
+                },
+                "user_id": {
+                    "description": "UserID defaults to the caller; OWNER, DISPATCHER and API keys may record on a technician's behalf.",
+                    "type": "string",
+                    "example": "tech-42"
+                }
+            }
+        },
+        "delivery.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.RevokeSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "delivery.SSORedeemRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "delivery.VerifyRecommendationResponse": {
+            "type": "object",
+            "properties": {
+                "key_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "delivery.WebhookDeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookDelivery"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the following page; absent on the last page.",
+                    "type": "string",
+                    "example": "eyJmIjoi...In19.Yk3c..."
+                }
+            }
+        },
+        "delivery.WebhookSecretRotatedResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "JobCompleted",
+                        "DoseRecorded"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"
+                },
+                "previous_secret_expires_at": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "q8Vw…"
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/pool"
+                }
+            }
+        },
+        "delivery.WebhookSubscriptionCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "JobCompleted",
+                        "DoseRecorded"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"
+                },
+                "previous_secret_expires_at": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "q8Vw…"
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/pool"
+                }
+            }
+        },
+        "delivery.WebhookSubscriptionListResponse": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookSubscription"
+                    }
+                }
+            }
+        },
+        "domain.APIKey": {
             "type": "object",
             "properties": {
-                "build_date": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Alert": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "boolean"
+                },
+                "acknowledged_at": {
+                    "description": "AcknowledgedAt and AcknowledgedBy record who acknowledged the alert, once someone has.",
+                    "type": "string"
+                },
+                "acknowledged_by": {
                     "type": "string",
-                    "example": "2025-08-25T12:34:56Z"
+                    "example": "dispatcher-7"
                 },
-                "commit": {
+                "alert_type": {
                     "type": "string",
-                    "example": "abc1234"
+                    "example": "FC_LOW"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1c0b7e-3d2a-4e5f-9a8b-7c6d5e4f3a2b"
+                },
+                "job_id": {
+                    "type": "string",
+                    "example": "job-123"
+                },
+                "job_reading_id": {
+                    "type": "string",
+                    "example": "2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "FC"
+                },
+                "severity": {
+                    "type": "string",
+                    "example": "CRITICAL"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 0.4
+                }
+            }
+        },
+        "domain.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action_type": {
+                    "type": "string",
+                    "example": "DOSE_RECORDED"
+                },
+                "actor_id": {
+                    "type": "string",
+                    "example": "tech-42"
+                },
+                "actor_role": {
+                    "type": "string",
+                    "example": "TECHNICIAN"
+                },
+                "entity_id": {
+                    "type": "string",
+                    "example": "2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"
+                },
+                "entity_type": {
+                    "type": "string",
+                    "example": "dose_event"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "hash_version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b8f6c2e-5a1d-4e3f-8c7b-9a6d5e4f3c2b"
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "5f2b8c1d9e3a4f6b7c8d9e0f1a2b3c4d"
+                },
+                "sequence": {
+                    "description": "Sequence, PrevHash, Hash and HashVersion are assigned by the repository on Append (see\nChainAudit).",
+                    "type": "integer",
+                    "example": 1
+                },
+                "trace_id": {
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                }
+            }
+        },
+        "domain.ChainCheckpoint": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "audit"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "signature": {
+                    "type": "string",
+                    "format": "base64"
+                }
+            }
+        },
+        "domain.ChainExport": {
+            "type": "object",
+            "properties": {
+                "audit_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEvent"
+                    }
+                },
+                "checkpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ChainCheckpoint"
+                    }
+                },
+                "dose_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DoseEvent"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DoseEvent": {
+            "type": "object",
+            "properties": {
+                "actual_amount": {
+                    "type": "number",
+                    "example": 30
+                },
+                "after_value": {
+                    "type": "number",
+                    "example": 3
+                },
+                "before_value": {
+                    "type": "number",
+                    "example": 1.2
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "hash_version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "string",
+                    "example": "2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"
+                },
+                "job_id": {
+                    "type": "string",
+                    "example": "job-123"
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "FC"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string",
+                    "example": "liquid-chlorine-12.5"
+                },
+                "recommended_amount": {
+                    "type": "number",
+                    "example": 32
+                },
+                "sequence": {
+                    "description": "Sequence, PrevHash, Hash and HashVersion are assigned by the repository on Create (see\nChainDose).",
+                    "type": "integer",
+                    "example": 1
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "oz"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "tech-42"
+                }
+            }
+        },
+        "domain.DoseRecommendation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "engine_version": {
+                    "type": "string",
+                    "example": "2025.10.1"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7a1e2b3c-4d5e-6f70-8192-a3b4c5d6e7f8"
+                },
+                "inputs": {
+                    "$ref": "#/definitions/domain.DoseRecommendationInput"
+                },
+                "key_id": {
+                    "type": "string",
+                    "example": "3f2a9c1b7d4e5f60"
+                },
+                "outputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecommendedDose"
+                    }
+                },
+                "signature": {
+                    "type": "string",
+                    "format": "base64"
+                },
+                "signature_version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.DoseRecommendationInput": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string",
+                    "example": "job-123"
+                },
+                "pool_volume_gallons": {
+                    "type": "number",
+                    "example": 15000
+                },
+                "readings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "targets": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "pool_volume_gallons"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be positive"
+                }
+            }
+        },
+        "domain.JobAssignment": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string",
+                    "example": "job-123"
+                },
+                "technician_id": {
+                    "type": "string",
+                    "example": "u-42"
+                }
+            }
+        },
+        "domain.RecommendedDose": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 32
+                },
+                "parameter": {
+                    "type": "string",
+                    "example": "FC"
+                },
+                "product_id": {
+                    "type": "string",
+                    "example": "liquid-chlorine-12.5"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "oz"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookDeliveryAttempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dead_lettered_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "evt_123"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "JobCompleted"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b9d7c1e-3f5d-4e0b-9a35-6a8a1c1f2d44"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery_of": {
+                    "type": "string"
                 },
                 "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.WebhookDeliveryStatus"
+                        }
+                    ],
+                    "example": "PENDING"
+                },
+                "subscription_id": {
                     "type": "string",
-                    "example": "ok"
+                    "example": "5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "attempted_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string",
+                    "example": "unexpected status 502"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 502
+                }
+            }
+        },
+        "domain.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "DELIVERED",
+                "FAILED",
+                "DEAD_LETTERED"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliveryDelivered",
+                "WebhookDeliveryFailed",
+                "WebhookDeliveryDeadLettered"
+            ]
+        },
+        "domain.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "JobCompleted",
+                        "DoseRecorded"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"
+                },
+                "previous_secret_expires_at": {
+                    "type": "string"
+                },
+                "target_url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/pool"
+                }
+            }
+        },
+        "events.Schema": {
+            "type": "object",
+            "properties": {
+                "schema": {
+                    "type": "object"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "DoseRecorded"
                 },
                 "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "middleware.ErrorBody": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string",
-                    "example": "1.0.0"
+                    "example": "NOT_FOUND"
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "f3b1a6d9099f4f42e8e97d5d6d3fe0c2"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "webhook delivery \"d-1\" not found"
+                }
+            }
+        },
+        "middleware.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/middleware.ErrorBody"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Access token as \"Bearer \u003cjwt\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so keys are recognisable in the Authorization header
+// and by secret scanners.
+const APIKeyPrefix = "pmk_"
+
+// NewAPIKey returns a random API key of the form pmk_<8 hex>_<secret>, its public prefix
+// (pmk_<8 hex>) and the hash to store for it.
+func NewAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashOpaqueToken(key), nil
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a JWT.
+func IsAPIKey(raw string) bool { return strings.HasPrefix(raw, APIKeyPrefix) }
+
+// APIKeyPrefixOf returns the public prefix of an API key, or "" if raw is not one.
+func APIKeyPrefixOf(raw string) string {
+	if !IsAPIKey(raw) {
+		return ""
+	}
+	prefix, _, ok := strings.Cut(raw[len(APIKeyPrefix):], "_")
+	if !ok {
+		return ""
+	}
+	return APIKeyPrefix + prefix
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifier = "verifier-0123456789-0123456789-0123456789"
+
+func newTestOIDCProvider(t *testing.T) (*OIDCProvider, *oidctest.Provider) {
+	t.Helper()
+	idp := oidctest.New(t)
+	p, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://app.test/callback",
+	})
+	require.NoError(t, err)
+	return p, idp
+}
+
+func TestOIDCProvider_CodeFlowWithPKCE(t *testing.T) {
+	p, idp := newTestOIDCProvider(t)
+	idp.SetClaims(map[string]any{"sub": "abc", "groups": []string{"admins"}})
+
+	cb, err := idp.Login(p.AuthCodeURL("state-1", "nonce-1", testVerifier))
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", cb.Query().Get("state"))
+
+	tok, err := p.Exchange(context.Background(), cb.Query().Get("code"), testVerifier)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", tok.Subject)
+	assert.Equal(t, "nonce-1", tok.Nonce)
+	assert.Equal(t, idp.Issuer(), tok.Issuer)
+	assert.Equal(t, []any{"admins"}, tok.Claims["groups"])
+}
+
+func TestOIDCProvider_RejectsWrongVerifier(t *testing.T) {
+	p, idp := newTestOIDCProvider(t)
+	cb, err := idp.Login(p.AuthCodeURL("s", "n", testVerifier))
+	require.NoError(t, err)
+
+	_, err = p.Exchange(context.Background(), cb.Query().Get("code"), "another-"+testVerifier)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewOIDCProvider_DiscoveryFailure(t *testing.T) {
+	idp := oidctest.New(t)
+	_, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		IssuerURL: idp.Issuer() + "/wrong", ClientID: "c", RedirectURL: "http://app.test/callback",
+	})
+	assert.Error(t, err)
+}
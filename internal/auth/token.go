@@ -0,0 +1,129 @@
+// Package auth issues and validates the service's access tokens: short-lived EdDSA-signed
+// JWTs carrying the user id (sub) and roles.
+package auth
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+)
+
+// MaxAccessTokenTTL is the longest lifetime an access token may have (E-SEC-004).
+const MaxAccessTokenTTL = 15 * time.Minute
+
+// MaxRefreshTokenTTL is the longest lifetime a refresh token may have (E-SEC-001).
+const MaxRefreshTokenTTL = 14 * 24 * time.Hour
+
+// ErrInvalidToken wraps every reason a token is rejected.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the JWT claims of an access token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// Config names the issuer and audience tokens are minted for and checked against.
+type Config struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+// Issuer signs access tokens.
+type Issuer struct {
+	signer *signing.Signer
+	cfg    Config
+	now    func() time.Time
+}
+
+// NewIssuer creates an Issuer. TTL defaults to, and is capped at, MaxAccessTokenTTL.
+func NewIssuer(signer *signing.Signer, cfg Config) *Issuer {
+	if cfg.TTL <= 0 || cfg.TTL > MaxAccessTokenTTL {
+		cfg.TTL = MaxAccessTokenTTL
+	}
+	return &Issuer{signer: signer, cfg: cfg, now: time.Now}
+}
+
+// Issue returns a signed access token for userID and its expiry.
+func (i *Issuer) Issue(userID string, roles []string) (string, time.Time, error) {
+	now := i.now().Truncate(time.Second)
+	exp := now.Add(i.cfg.TTL)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			Issuer:    i.cfg.Issuer,
+			Audience:  jwt.ClaimStrings{i.cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+		Roles: roles,
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	tok.Header["kid"] = i.signer.KeyID()
+	signed, err := tok.SignedString(i.signer.PrivateKey())
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return signed, exp, nil
+}
+
+// Verifier validates access tokens.
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewVerifier accepts tokens signed by any of keys. Passing the previous key alongside the
+// current one lets tokens survive a key rotation until they expire.
+func NewVerifier(cfg Config, keys ...ed25519.PublicKey) *Verifier {
+	m := make(map[string]ed25519.PublicKey, len(keys))
+	for _, k := range keys {
+		m[signing.KeyID(k)] = k
+	}
+	return &Verifier{keys: m, cfg: cfg, now: time.Now}
+}
+
+// Verify checks signature, algorithm, issuer, audience, expiry and that the token lives no
+// longer than MaxAccessTokenTTL, and returns its claims.
+func (v *Verifier) Verify(raw string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, v.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithAudience(v.cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+	if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > MaxAccessTokenTTL {
+		return nil, fmt.Errorf("%w: lifetime exceeds %s", ErrInvalidToken, MaxAccessTokenTTL)
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) key(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if k, ok := v.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// HasRole reports whether claims grant role.
+func (c *Claims) HasRole(role string) bool { return slices.Contains(c.Roles, role) }
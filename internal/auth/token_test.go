@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{Issuer: "test-issuer", Audience: "test-api", TTL: 10 * time.Minute}
+
+func testSigner(t *testing.T, b byte) *signing.Signer {
+	t.Helper()
+	s, err := signing.NewSigner(bytes.Repeat([]byte{b}, 32))
+	require.NoError(t, err)
+	return s
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	s := testSigner(t, 1)
+	token, exp, err := NewIssuer(s, testConfig).Issue("u1", []string{"TECHNICIAN"})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), exp, 2*time.Second)
+
+	claims, err := NewVerifier(testConfig, s.PublicKey()).Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.Subject)
+	assert.True(t, claims.HasRole("TECHNICIAN"))
+	assert.False(t, claims.HasRole("OWNER"))
+}
+
+func TestNewIssuer_CapsTTL(t *testing.T) {
+	cfg := testConfig
+	cfg.TTL = time.Hour
+	_, exp, err := NewIssuer(testSigner(t, 1), cfg).Issue("u1", nil)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(MaxAccessTokenTTL), exp, 2*time.Second)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	s := testSigner(t, 1)
+	v := NewVerifier(testConfig, s.PublicKey())
+	issue := func(cfg Config, sub string) string {
+		token, _, err := NewIssuer(s, cfg).Issue(sub, nil)
+		require.NoError(t, err)
+		return token
+	}
+	wrongIssuer, wrongAudience := testConfig, testConfig
+	wrongIssuer.Issuer = "someone-else"
+	wrongAudience.Audience = "other-api"
+	otherKey, _, err := NewIssuer(testSigner(t, 2), testConfig).Issue("u1", nil)
+	require.NoError(t, err)
+
+	expired := NewIssuer(s, testConfig)
+	expired.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expiredToken, _, err := expired.Issue("u1", nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	longLived := jwt.NewWithClaims(jwt.SigningMethodEdDSA, Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "u1", Issuer: testConfig.Issuer, Audience: jwt.ClaimStrings{testConfig.Audience},
+		IssuedAt: jwt.NewNumericDate(now), ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}})
+	longLived.Header["kid"] = s.KeyID()
+	longLivedToken, err := longLived.SignedString(s.PrivateKey())
+	require.NoError(t, err)
+
+	none := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "u1", Issuer: testConfig.Issuer, Audience: jwt.ClaimStrings{testConfig.Audience},
+		IssuedAt: jwt.NewNumericDate(now), ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}})
+	noneToken, err := none.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"wrong issuer":   issue(wrongIssuer, "u1"),
+		"wrong audience": issue(wrongAudience, "u1"),
+		"missing sub":    issue(testConfig, ""),
+		"unknown key":    otherKey,
+		"expired":        expiredToken,
+		"lifetime > 15m": longLivedToken,
+		"alg none":       noneToken,
+		"garbage":        "not.a.token",
+	}
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerify_AcceptsPreviousKey(t *testing.T) {
+	old, current := testSigner(t, 1), testSigner(t, 2)
+	token, _, err := NewIssuer(old, testConfig).Issue("u1", nil)
+	require.NoError(t, err)
+	_, err = NewVerifier(testConfig, current.PublicKey(), old.PublicKey()).Verify(token)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// AlertListResponse wraps a page of chemistry alerts.
+type AlertListResponse struct {
+	Alerts []domain.Alert `json:"alerts"`
+	// NextCursor fetches the following page; absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJmIjoi...In19.Yk3c..."`
+}
+
+// AlertHandler exposes the chemistry alerts raised from recorded doses.
+type AlertHandler struct {
+	Logger  *zap.Logger
+	service *usecase.AlertService
+	cursors *pagination.Codec
+}
+
+// NewAlertHandler creates an AlertHandler backed by the given service.
+func NewAlertHandler(logger *zap.Logger, service *usecase.AlertService, cursors *pagination.Codec) *AlertHandler {
+	return &AlertHandler{Logger: logger, service: service, cursors: cursors}
+}
+
+// alertListSpec whitelists the filters and sort keys of GET /api/v1/alerts.
+var alertListSpec = pagination.Spec{
+	Filters:      []string{"job_id", "severity", "acknowledged"},
+	Sorts:        []string{domain.AlertSortCreatedAt, domain.AlertSortSeverity},
+	DefaultSort:  "-" + domain.AlertSortCreatedAt,
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+}
+
+// List returns a page of alerts, newest first.
+// @Summary List alerts
+// @Description Returns chemistry alerts (E-DOM-006). Sorting by severity orders by urgency, then time. Pages are cursor based (E-API-004): pass next_cursor with the same filters and sort to fetch the next page.
+// @Tags alerts
+// @Produce json
+// @Param job_id query string false "Job id"
+// @Param severity query string false "Severity" Enums(INFO, WARNING, CRITICAL)
+// @Param acknowledged query bool false "Acknowledged"
+// @Param sort query string false "Sort key, - for descending" Enums(created_at, -created_at, severity, -severity) default(-created_at)
+// @Param limit query int false "Page size (1-1000)" default(100)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} delivery.AlertListResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/alerts [get]
+func (h *AlertHandler) List(c *gin.Context) {
+	q, err := h.cursors.Parse(c.Request.URL.Query(), alertListSpec)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	filter := domain.AlertFilter{
+		JobID:       q.Filters["job_id"],
+		Severity:    q.Filters["severity"],
+		PageRequest: q.Request(),
+	}
+	if raw, ok := q.Filters["acknowledged"]; ok {
+		ack, err := strconv.ParseBool(raw)
+		if err != nil {
+			_ = c.Error(domain.Validation("", domain.FieldError{Field: "acknowledged", Message: "must be true or false"}))
+			return
+		}
+		filter.Acknowledged = &ack
+	}
+	alerts, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	alerts, next := pagination.Next(h.cursors, q, alerts)
+	c.JSON(http.StatusOK, AlertListResponse{Alerts: alerts, NextCursor: next})
+}
+
+// Acknowledge marks an alert as seen by the caller.
+// @Summary Acknowledge alert
+// @Description Records who acknowledged the alert and when (E-DOM-006). Acknowledging an acknowledged alert returns it unchanged.
+// @Tags alerts
+// @Produce json
+// @Param id path string true "Alert id"
+// @Success 200 {object} domain.Alert
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/alerts/{id}/acknowledge [post]
+func (h *AlertHandler) Acknowledge(c *gin.Context) {
+	id := c.Param("id")
+	alert, err := h.service.Acknowledge(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(notFound(err, "alert", id))
+		return
+	}
+	c.JSON(http.StatusOK, alert)
+}
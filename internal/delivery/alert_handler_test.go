@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAlertHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := repository.NewInMemoryAlertRepository()
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	for i, a := range []domain.Alert{
+		{ID: "a1", JobID: "j1", Severity: domain.SeverityWarning},
+		{ID: "a2", JobID: "j1", Severity: domain.SeverityCritical, Acknowledged: true},
+		{ID: "a3", JobID: "j2", Severity: domain.SeverityCritical},
+	} {
+		a.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Create(context.Background(), &a))
+	}
+	svc := usecase.NewAlertService(zap.NewNop(), repository.NewInMemoryTxManager(), repository.NewInMemoryDoseEventRepository(), repo,
+		usecase.NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), nil, metrics.New(nil))
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.GET("/api/v1/alerts", NewAlertHandler(zap.NewNop(), svc, testCursors).List)
+
+	get := func(url string) (*httptest.ResponseRecorder, AlertListResponse) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+		var resp AlertListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+	ids := func(resp AlertListResponse) []string {
+		var out []string
+		for _, a := range resp.Alerts {
+			out = append(out, a.ID)
+		}
+		return out
+	}
+
+	w, resp := get("/api/v1/alerts?limit=2")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"a3", "a2"}, ids(resp))
+	require.NotEmpty(t, resp.NextCursor)
+	_, resp = get("/api/v1/alerts?limit=2&cursor=" + resp.NextCursor)
+	assert.Equal(t, []string{"a1"}, ids(resp))
+	assert.Empty(t, resp.NextCursor)
+
+	_, resp = get("/api/v1/alerts?job_id=j1&acknowledged=false")
+	assert.Equal(t, []string{"a1"}, ids(resp))
+	_, resp = get("/api/v1/alerts?severity=CRITICAL&sort=created_at")
+	assert.Equal(t, []string{"a2", "a3"}, ids(resp))
+
+	w, _ = get("/api/v1/alerts?acknowledged=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// CreateAPIKeyRequest is the body of POST /api/v1/api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required" example:"Acme HOA integration"`
+	Scopes []string `json:"scopes" binding:"required" example:"jobs:read,exports:read"`
+	// ExpiresInDays defaults to 90 and may be at most 365.
+	ExpiresInDays int `json:"expires_in_days,omitempty" example:"90"`
+}
+
+// APIKeyCreatedResponse is a new key. Key is shown only in this response.
+type APIKeyCreatedResponse struct {
+	domain.APIKey
+	Key string `json:"key" example:"pmk_1a2b3c4d_9Zr…"`
+}
+
+// APIKeyListResponse lists API keys without their secrets.
+type APIKeyListResponse struct {
+	APIKeys []domain.APIKey `json:"api_keys"`
+}
+
+// APIKeyHandler manages API keys for integration partners.
+type APIKeyHandler struct {
+	Logger  *zap.Logger
+	service *usecase.APIKeyService
+}
+
+// NewAPIKeyHandler creates an APIKeyHandler backed by the given service.
+func NewAPIKeyHandler(logger *zap.Logger, service *usecase.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{Logger: logger, service: service}
+}
+
+// Create issues an API key.
+// @Summary Create API key
+// @Description Issues a scoped, expiring API key for a machine client. The key is returned once; only its hash is stored. Send it as `Authorization: Bearer pmk_…`.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param body body delivery.CreateAPIKeyRequest true "Key"
+// @Success 201 {object} delivery.APIKeyCreatedResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	k, plain, err := h.service.Create(c.Request.Context(), usecase.CreateAPIKeyInput{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+		TTL:    time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, APIKeyCreatedResponse{APIKey: *k, Key: plain})
+}
+
+// List returns every API key, without secrets.
+// @Summary List API keys
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} delivery.APIKeyListResponse
+// @Security BearerAuth
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, APIKeyListResponse{APIKeys: keys})
+}
+
+// Revoke disables an API key immediately.
+// @Summary Revoke API key
+// @Tags api-keys
+// @Param id path string true "API key id"
+// @Success 204
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		_ = c.Error(notFound(err, "api key", id))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
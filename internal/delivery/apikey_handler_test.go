@@ -0,0 +1,54 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPIKeyHandler_Lifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := usecase.NewAPIKeyService(zap.NewNop(), repository.NewInMemoryAPIKeyRepository(),
+		usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()))
+	h := NewAPIKeyHandler(zap.NewNop(), svc)
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.POST("/api/v1/api-keys", h.Create)
+	r.GET("/api/v1/api-keys", h.List)
+	r.DELETE("/api/v1/api-keys/:id", h.Revoke)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/api-keys", `{"name":"acme","scopes":["jobs:delete"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do("POST", "/api/v1/api-keys", `{"name":"acme","scopes":["jobs:read"],"expires_in_days":30}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var created APIKeyCreatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+	assert.NotContains(t, w.Body.String(), "key_hash")
+
+	w = do("GET", "/api/v1/api-keys", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.Prefix)
+	assert.NotContains(t, w.Body.String(), created.Key, "listing never shows the secret")
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", "/api/v1/api-keys/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/api/v1/api-keys/missing", "").Code)
+}
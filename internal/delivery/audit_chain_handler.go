@@ -0,0 +1,38 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// AuditChainHandler exposes the hash-chained audit and dose logs for offline verification.
+type AuditChainHandler struct {
+	Logger  *zap.Logger
+	service *usecase.AuditChainService
+}
+
+// NewAuditChainHandler creates an AuditChainHandler backed by the given service.
+func NewAuditChainHandler(logger *zap.Logger, service *usecase.AuditChainService) *AuditChainHandler {
+	return &AuditChainHandler{Logger: logger, service: service}
+}
+
+// Export returns both hash chains with their signed checkpoints.
+// @Summary Export audit and dose hash chains
+// @Description Returns every AuditEvent and DoseEvent in chain order plus signed checkpoints. Feed the response to `pool-maintenance-api verify-audit`.
+// @Tags audit
+// @Produce json
+// @Success 200 {object} domain.ChainExport
+// @Failure 429 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/audit/export [get]
+func (h *AuditChainHandler) Export(c *gin.Context) {
+	exp, err := h.service.Export(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, exp)
+}
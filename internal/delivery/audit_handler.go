@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// AuditEventListResponse wraps a page of audit events.
+type AuditEventListResponse struct {
+	Events []domain.AuditEvent `json:"events"`
+	// NextCursor fetches the following page; absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJmIjoi...In19.Yk3c..."`
+}
+
+// AuditHandler exposes the read-only audit trail.
+type AuditHandler struct {
+	Logger  *zap.Logger
+	service *usecase.AuditService
+	cursors *pagination.Codec
+}
+
+// NewAuditHandler creates an AuditHandler backed by the given service.
+func NewAuditHandler(logger *zap.Logger, service *usecase.AuditService, cursors *pagination.Codec) *AuditHandler {
+	return &AuditHandler{Logger: logger, service: service, cursors: cursors}
+}
+
+// auditListSpec whitelists the filters and sort keys of GET /api/v1/audit.
+var auditListSpec = pagination.Spec{
+	Filters:      []string{"actor_id", "entity_type", "entity_id", "from", "to"},
+	Sorts:        []string{domain.AuditSortOccurredAt, domain.AuditSortSequence},
+	DefaultSort:  "-" + domain.AuditSortOccurredAt,
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+}
+
+// List returns a page of audit events, newest first.
+// @Summary Query audit trail
+// @Description Returns append-only audit events (E-AUD-001). Filter by actor, entity and time range; from is inclusive, to is exclusive. Pages are cursor based (E-API-004): pass next_cursor with the same filters and sort to fetch the next page.
+// @Tags audit
+// @Produce json
+// @Param actor_id query string false "Actor id"
+// @Param entity_type query string false "Entity type" example(dose_event)
+// @Param entity_id query string false "Entity id"
+// @Param from query string false "Start of range (RFC 3339)"
+// @Param to query string false "End of range (RFC 3339)"
+// @Param sort query string false "Sort key, - for descending" Enums(occurred_at, -occurred_at, sequence, -sequence) default(-occurred_at)
+// @Param limit query int false "Page size (1-1000)" default(100)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} delivery.AuditEventListResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	q, err := h.cursors.Parse(c.Request.URL.Query(), auditListSpec)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	filter := domain.AuditFilter{
+		ActorID:     q.Filters["actor_id"],
+		EntityType:  q.Filters["entity_type"],
+		EntityID:    q.Filters["entity_id"],
+		PageRequest: q.Request(),
+	}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := q.Filters[name]; raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				_ = c.Error(domain.Validation("", domain.FieldError{Field: name, Message: "must be an RFC 3339 timestamp"}))
+				return
+			}
+			*dst = t
+		}
+	}
+	evs, err := h.service.Query(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	evs, next := pagination.Next(h.cursors, q, evs)
+	c.JSON(http.StatusOK, AuditEventListResponse{Events: evs, NextCursor: next})
+}
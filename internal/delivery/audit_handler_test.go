@@ -0,0 +1,122 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testCursors signs list cursors in handler tests.
+var testCursors = pagination.NewCodec([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestAuditHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	for _, e := range []usecase.AuditEntry{
+		{ActorID: "u1", ActionType: "DOSE_RECORDED", EntityType: "dose_event", EntityID: "d1", OccurredAt: base},
+		{ActorID: "u2", ActionType: "JOB_COMPLETED", EntityType: "job", EntityID: "j1", OccurredAt: base.Add(time.Hour)},
+	} {
+		_, err := svc.Record(context.Background(), e)
+		require.NoError(t, err)
+	}
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.GET("/api/v1/audit", NewAuditHandler(zap.NewNop(), svc, testCursors).List)
+
+	get := func(url string) (*httptest.ResponseRecorder, AuditEventListResponse) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+		var resp AuditEventListResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	w, resp := get("/api/v1/audit")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, resp.Events, 2)
+
+	_, resp = get("/api/v1/audit?actor_id=u1")
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "d1", resp.Events[0].EntityID)
+
+	_, resp = get("/api/v1/audit?entity_type=job&entity_id=j1")
+	require.Len(t, resp.Events, 1)
+
+	_, resp = get("/api/v1/audit?from=2025-10-05T12:30:00Z&to=2025-10-05T14:00:00Z")
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "j1", resp.Events[0].EntityID)
+
+	for _, bad := range []string{
+		"/api/v1/audit?from=yesterday",
+		"/api/v1/audit?limit=0",
+		"/api/v1/audit?sort=actor_id",
+		"/api/v1/audit?action=DELETE",
+		"/api/v1/audit?cursor=forged",
+	} {
+		w, _ = get(bad)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+}
+
+// TestAuditHandler_CursorPaging walks the trail page by page while new events are appended,
+// as happens in production: every event present at the start is seen exactly once.
+func TestAuditHandler_CursorPaging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	record := func(i int) {
+		_, err := svc.Record(context.Background(), usecase.AuditEntry{
+			ActorID: "u1", ActionType: "DOSE_RECORDED", EntityType: "dose_event",
+			EntityID: fmt.Sprintf("d%02d", i), OccurredAt: base.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	for i := range 7 {
+		record(i)
+	}
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.GET("/api/v1/audit", NewAuditHandler(zap.NewNop(), svc, testCursors).List)
+
+	var seen []string
+	url := "/api/v1/audit?actor_id=u1&sort=-occurred_at&limit=3"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "paging must terminate")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp AuditEventListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		for _, e := range resp.Events {
+			seen = append(seen, e.EntityID)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		record(100 + pages) // newer than everything already paged past
+		url = "/api/v1/audit?actor_id=u1&sort=-occurred_at&limit=3&cursor=" + resp.NextCursor
+
+		// The cursor is bound to its query.
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/audit?actor_id=u2&cursor="+resp.NextCursor, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+	assert.Equal(t, []string{"d06", "d05", "d04", "d03", "d02", "d01", "d00"}, seen)
+}
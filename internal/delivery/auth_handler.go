@@ -0,0 +1,247 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// LoginRequest carries a username and password.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest carries a refresh token for /auth/refresh and /auth/logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse is returned by login and refresh. The refresh token replaces the one
+// presented; it can be used once. RecoveryCodes are returned once, when a login completes
+// MFA enrollment.
+type TokenResponse struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	RecoveryCodes    []string  `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is needed. Pass MFAToken to
+// /auth/mfa/verify, after /auth/mfa/enroll when EnrollmentRequired is set.
+type MFAChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	MFAToken           string    `json:"mfa_token"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+// MFAEnrollRequest starts TOTP enrollment for a challenged login.
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFAEnrollResponse carries the new authenticator secret. QRCodePNG is a base64 PNG of the
+// otpauth URI.
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodePNG  []byte `json:"qr_code_png" swaggertype:"string" format:"base64"`
+}
+
+// MFAVerifyRequest completes a challenged login with a TOTP code or a recovery code.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+// RevokeSessionsResponse reports how many sessions were revoked.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// AuthHandler exposes login, MFA, token refresh, logout and session revocation.
+type AuthHandler struct {
+	Logger  *zap.Logger
+	service *usecase.SessionService
+	mfa     *usecase.MFAService
+}
+
+// NewAuthHandler creates an AuthHandler backed by the given services. mfa may be nil when
+// second factors are disabled.
+func NewAuthHandler(logger *zap.Logger, service *usecase.SessionService, mfa *usecase.MFAService) *AuthHandler {
+	return &AuthHandler{Logger: logger, service: service, mfa: mfa}
+}
+
+// Login exchanges credentials for an access and refresh token.
+// @Summary Log in
+// @Description Returns a 15 minute access token and a single-use refresh token, or 202 with an MFA challenge when the account needs a second factor. Successful and failed attempts are audited.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body delivery.LoginRequest true "Credentials"
+// @Success 200 {object} delivery.TokenResponse
+// @Success 202 {object} delivery.MFAChallengeResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	res, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
+	if err == nil && res.MFA != nil {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusAccepted, MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           res.MFA.Token,
+			EnrollmentRequired: res.MFA.EnrollmentRequired,
+			ExpiresAt:          res.MFA.ExpiresAt,
+		})
+		return
+	}
+	var tokens *usecase.Tokens
+	if res != nil {
+		tokens = res.Tokens
+	}
+	h.respondTokens(c, tokens, err)
+}
+
+// EnrollMFA creates a TOTP secret for a challenged login.
+// @Summary Enroll TOTP authenticator
+// @Description Returns a new TOTP secret as an otpauth URI and QR code. It becomes active when /auth/mfa/verify accepts its first code.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body delivery.MFAEnrollRequest true "MFA challenge token"
+// @Success 200 {object} delivery.MFAEnrollResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req MFAEnrollRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if h.mfa == nil {
+		_ = c.Error(domain.ErrInvalidCredentials)
+		return
+	}
+	enr, err := h.mfa.Enroll(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, MFAEnrollResponse{Secret: enr.Secret, OTPAuthURI: enr.URI, QRCodePNG: enr.QRCodePNG})
+}
+
+// VerifyMFA completes a challenged login.
+// @Summary Verify second factor
+// @Description Completes login with a TOTP code or a single-use recovery code. The first code after enrollment activates the authenticator and returns recovery codes once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body delivery.MFAVerifyRequest true "MFA challenge token and code"
+// @Success 200 {object} delivery.TokenResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	tokens, err := h.service.CompleteMFA(c.Request.Context(), req.MFAToken, req.Code)
+	h.respondTokens(c, tokens, err)
+}
+
+// Refresh rotates a refresh token.
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access and refresh token. Presenting an already used refresh token revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body delivery.RefreshRequest true "Refresh token"
+// @Success 200 {object} delivery.TokenResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	h.respondTokens(c, tokens, err)
+}
+
+// Logout ends the session a refresh token belongs to.
+// @Summary Log out
+// @Description Revokes the refresh token and every token rotated from the same login. Unknown tokens are accepted.
+// @Tags auth
+// @Accept json
+// @Param body body delivery.RefreshRequest true "Refresh token"
+// @Success 204
+// @Failure 400 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeUserSessions revokes every session of a user.
+// @Summary Revoke user sessions
+// @Description Revokes all refresh tokens of a user. Access tokens already issued expire within 15 minutes.
+// @Tags auth
+// @Produce json
+// @Param id path string true "User id"
+// @Success 200 {object} delivery.RevokeSessionsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	n, err := h.service.RevokeUserSessions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, RevokeSessionsResponse{Revoked: n})
+}
+
+func (h *AuthHandler) respondTokens(c *gin.Context, tokens *usecase.Tokens, err error) {
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, newTokenResponse(tokens))
+}
+
+func newTokenResponse(t *usecase.Tokens) TokenResponse {
+	return TokenResponse{
+		AccessToken:      t.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        t.AccessExpiresAt,
+		RefreshToken:     t.RefreshToken,
+		RefreshExpiresAt: t.RefreshExpiresAt,
+		RecoveryCodes:    t.RecoveryCodes,
+	}
+}
@@ -0,0 +1,167 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// singleUser authenticates one hard-coded account.
+type singleUser struct{}
+
+func (singleUser) Authenticate(_ context.Context, username, password string) (*domain.Principal, error) {
+	if username != "alice" || password != "secret-password" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return &domain.Principal{UserID: "u1", Roles: []string{"OWNER"}}, nil
+}
+
+func (singleUser) Principal(_ context.Context, userID string) (*domain.Principal, error) {
+	if userID != "u1" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return &domain.Principal{UserID: "u1", Roles: []string{"OWNER"}}, nil
+}
+
+func newTestAuthRouter(t *testing.T, authn domain.Authenticator) *gin.Engine {
+	t.Helper()
+	return newTestAuthRouterWithMFA(t, authn, nil)
+}
+
+func newTestAuthRouterWithMFA(t *testing.T, authn domain.Authenticator, mfa *usecase.MFAService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{6}, 32))
+	require.NoError(t, err)
+	cfg := auth.Config{Issuer: "test", Audience: "test"}
+	svc := usecase.NewSessionService(zap.NewNop(), authn, mfa, auth.NewIssuer(signer, cfg),
+		repository.NewInMemoryRefreshTokenRepository(),
+		usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()), 0)
+	h := NewAuthHandler(zap.NewNop(), svc, mfa)
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.POST("/api/v1/auth/login", h.Login)
+	r.POST("/api/v1/auth/mfa/enroll", h.EnrollMFA)
+	r.POST("/api/v1/auth/mfa/verify", h.VerifyMFA)
+	r.POST("/api/v1/auth/refresh", h.Refresh)
+	r.POST("/api/v1/auth/logout", h.Logout)
+	r.DELETE("/api/v1/admin/users/:id/sessions", h.RevokeUserSessions)
+	return r
+}
+
+func TestAuthHandler_LoginRefreshLogout(t *testing.T) {
+	r := newTestAuthRouter(t, singleUser{})
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/auth/login", `{"username":"alice","password":"nope"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = do("POST", "/api/v1/auth/login", `{"username":"alice"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = do("POST", "/api/v1/auth/login", `{"username":"alice","password":"secret-password"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var login TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	assert.Equal(t, "Bearer", login.TokenType)
+	assert.NotEmpty(t, login.AccessToken)
+
+	w = do("POST", "/api/v1/auth/refresh", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var refreshed TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+
+	w = do("POST", "/api/v1/auth/refresh", `{"refresh_token":"`+login.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "reuse is refused")
+	w = do("POST", "/api/v1/auth/refresh", `{"refresh_token":"`+refreshed.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "reuse revoked the family")
+
+	w = do("POST", "/api/v1/auth/logout", `{"refresh_token":"`+refreshed.RefreshToken+`"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAuthHandler_RevokeUserSessions(t *testing.T) {
+	r := newTestAuthRouter(t, singleUser{})
+	for range 2 {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"username":"alice","password":"secret-password"}`))
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/v1/admin/users/u1/sessions", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked":2}`, w.Body.String())
+}
+
+func TestAuthHandler_NoAuthenticator(t *testing.T) {
+	r := newTestAuthRouter(t, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{"username":"alice","password":"x"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAuthHandler_MFAEnrollmentFlow(t *testing.T) {
+	users := repository.NewInMemoryUserRepository()
+	require.NoError(t, users.Create(context.Background(), &domain.User{ID: "u1", Username: "alice", Roles: []string{domain.RoleOwner}}))
+	mfa := usecase.NewMFAService(zap.NewNop(), users, repository.NewInMemoryMFAChallengeRepository(),
+		usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()), usecase.DefaultMFAPolicy, usecase.DefaultLockoutPolicy, "Pool Maintenance")
+	r := newTestAuthRouterWithMFA(t, singleUser{}, mfa)
+	do := func(url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/api/v1/auth/login", `{"username":"alice","password":"secret-password"}`)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var ch MFAChallengeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ch))
+	assert.True(t, ch.MFARequired)
+	assert.True(t, ch.EnrollmentRequired)
+	assert.NotContains(t, w.Body.String(), "access_token")
+
+	w = do("/api/v1/auth/mfa/enroll", `{"mfa_token":"`+ch.MFAToken+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var enr MFAEnrollResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enr))
+	assert.True(t, strings.HasPrefix(enr.OTPAuthURI, "otpauth://totp/"))
+	assert.Equal(t, []byte("\x89PNG"), enr.QRCodePNG[:4], "qr_code_png is base64 PNG")
+
+	w = do("/api/v1/auth/mfa/verify", `{"mfa_token":"`+ch.MFAToken+`","code":"000000"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	code, err := totp.GenerateCode(enr.Secret, time.Now())
+	require.NoError(t, err)
+	w = do("/api/v1/auth/mfa/verify", `{"mfa_token":"`+ch.MFAToken+`","code":"`+code+`"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tokens TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Len(t, tokens.RecoveryCodes, 10)
+}
@@ -0,0 +1,118 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// RecordDoseRequest is the body of POST /api/v1/jobs/{id}/doses.
+type RecordDoseRequest struct {
+	Parameter         string   `json:"parameter" binding:"required" example:"FC"`
+	ProductID         string   `json:"product_id" binding:"required" example:"liquid-chlorine-12.5"`
+	Unit              string   `json:"unit" binding:"required" example:"oz"`
+	RecommendedAmount *float64 `json:"recommended_amount,omitempty" example:"32"`
+	ActualAmount      *float64 `json:"actual_amount" binding:"required" example:"30"`
+	BeforeValue       *float64 `json:"before_value,omitempty" example:"1.2"`
+	AfterValue        *float64 `json:"after_value,omitempty" example:"3.0"`
+	// UserID defaults to the caller; OWNER, DISPATCHER and API keys may record on a technician's behalf.
+	UserID string `json:"user_id,omitempty" example:"tech-42"`
+}
+
+// DoseEventListResponse lists a page of the doses recorded for a job.
+type DoseEventListResponse struct {
+	DoseEvents []domain.DoseEvent `json:"dose_events"`
+	// NextCursor fetches the following page; absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJmIjoi...In19.Yk3c..."`
+}
+
+// DoseHandler exposes dose logging endpoints.
+type DoseHandler struct {
+	Logger  *zap.Logger
+	service *usecase.DoseService
+	cursors *pagination.Codec
+}
+
+// NewDoseHandler creates a DoseHandler backed by the given service.
+func NewDoseHandler(logger *zap.Logger, service *usecase.DoseService, cursors *pagination.Codec) *DoseHandler {
+	return &DoseHandler{Logger: logger, service: service, cursors: cursors}
+}
+
+// doseListSpec whitelists the sort keys of a job's dose list; it has no filters.
+var doseListSpec = pagination.Spec{
+	Sorts:        []string{domain.DoseSortCreatedAt, domain.DoseSortParameter},
+	DefaultSort:  domain.DoseSortCreatedAt,
+	DefaultLimit: 100,
+	MaxLimit:     1000,
+}
+
+// Record logs a dose applied during a job.
+// @Summary Record dose
+// @Description Persists a DoseEvent (E-DOM-004) and, in the same transaction, a DoseRecorded event for partners.
+// @Tags doses
+// @Accept json
+// @Produce json
+// @Param id path string true "Job id"
+// @Param body body delivery.RecordDoseRequest true "Dose"
+// @Param Idempotency-Key header string false "Replays the first response when retried with the same key"
+// @Success 201 {object} domain.DoseEvent
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/jobs/{id}/doses [post]
+func (h *DoseHandler) Record(c *gin.Context) {
+	var req RecordDoseRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	ev, err := h.service.Record(c.Request.Context(), usecase.RecordDoseInput{
+		JobID:             c.Param("id"),
+		Parameter:         req.Parameter,
+		ProductID:         req.ProductID,
+		Unit:              req.Unit,
+		RecommendedAmount: req.RecommendedAmount,
+		ActualAmount:      *req.ActualAmount,
+		BeforeValue:       req.BeforeValue,
+		AfterValue:        req.AfterValue,
+		UserID:            req.UserID,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, ev)
+}
+
+// List returns a page of the doses recorded for a job, oldest first.
+// @Summary List doses for a job
+// @Description Pages are cursor based (E-API-004): pass next_cursor with the same sort to fetch the next page.
+// @Tags doses
+// @Produce json
+// @Param id path string true "Job id"
+// @Param sort query string false "Sort key, - for descending" Enums(created_at, -created_at, parameter, -parameter) default(created_at)
+// @Param limit query int false "Page size (1-1000)" default(100)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} delivery.DoseEventListResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/jobs/{id}/doses [get]
+func (h *DoseHandler) List(c *gin.Context) {
+	q, err := h.cursors.Parse(c.Request.URL.Query(), doseListSpec)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	doses, err := h.service.ListByJob(c.Request.Context(), c.Param("id"), q.Request())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	doses, next := pagination.Next(h.cursors, q, doses)
+	c.JSON(http.StatusOK, DoseEventListResponse{DoseEvents: doses, NextCursor: next})
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestDoseRouter(mw ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	publisher := usecase.NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository())
+	svc := usecase.NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), repository.NewInMemoryDoseEventRepository(), repository.NewInMemoryJobAssignmentRepository(), publisher, metrics.New(nil))
+	h := NewDoseHandler(zap.NewNop(), svc, testCursors)
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.Use(mw...)
+	r.POST("/api/v1/jobs/:id/doses", h.Record)
+	r.GET("/api/v1/jobs/:id/doses", h.List)
+	return r
+}
+
+func TestDoseHandler_RecordAndList(t *testing.T) {
+	r := newTestDoseRouter()
+
+	body := `{"parameter":"FC","product_id":"liquid-chlorine","unit":"oz","actual_amount":30,"user_id":"tech-1"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/jobs/job-1/doses", strings.NewReader(body))
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/jobs/job-1/doses", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		DoseEvents []struct {
+			JobID     string `json:"job_id"`
+			Parameter string `json:"parameter"`
+		} `json:"dose_events"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.DoseEvents, 1)
+	assert.Equal(t, "job-1", resp.DoseEvents[0].JobID)
+	assert.Equal(t, "FC", resp.DoseEvents[0].Parameter)
+}
+
+func TestDoseHandler_RecordRejectsInvalidInput(t *testing.T) {
+	r := newTestDoseRouter()
+	for body, field := range map[string]string{
+		`{"parameter":"FC"}`: "product_id",
+		`{"parameter":"Iron","product_id":"x","unit":"oz","actual_amount":1,"user_id":"u"}`: "parameter",
+		`{"parameter":"FC","product_id":"x","unit":"oz","actual_amount":"a lot"}`:           "actual_amount",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/jobs/job-1/doses", strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+
+		var resp middleware.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, middleware.CodeValidation, resp.Error.Code)
+		require.NotEmpty(t, resp.Error.Fields, body)
+		assert.Equal(t, field, resp.Error.Fields[0].Field, body)
+	}
+}
+
+func TestDoseHandler_ForbiddenForOtherTechnician(t *testing.T) {
+	r := newTestDoseRouter(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(requestctx.WithUser(c.Request.Context(), "tech-9", []string{domain.RoleTech}))
+	})
+
+	body := `{"parameter":"FC","product_id":"liquid-chlorine","unit":"oz","actual_amount":30}`
+	for method, b := range map[string]string{"POST": body, "GET": ""} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/jobs/job-1/doses", strings.NewReader(b))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, method)
+	}
+}
@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"go.uber.org/zap"
+)
+
+// EventSchemaListResponse lists published event schemas.
+type EventSchemaListResponse struct {
+	Schemas []events.Schema `json:"schemas"`
+}
+
+// EventSchemaHandler serves the versioned JSON Schemas of outbound domain events.
+type EventSchemaHandler struct {
+	Logger   *zap.Logger
+	registry *events.Registry
+}
+
+// NewEventSchemaHandler creates an EventSchemaHandler for the given registry.
+func NewEventSchemaHandler(logger *zap.Logger, registry *events.Registry) *EventSchemaHandler {
+	return &EventSchemaHandler{Logger: logger, registry: registry}
+}
+
+// List returns every published event schema, optionally filtered by event type.
+// @Summary List event schemas
+// @Description Returns the JSON Schema of every outbound event version (JobCompleted, DoseRecorded, InvoicePaid, AlertRaised, ...). Published versions only change additively; breaking changes ship as a new version.
+// @Tags events
+// @Produce json
+// @Param type query string false "Event type, e.g. DoseRecorded"
+// @Success 200 {object} delivery.EventSchemaListResponse
+// @Security BearerAuth
+// @Router /api/v1/events/schemas [get]
+func (h *EventSchemaHandler) List(c *gin.Context) {
+	all := h.registry.List()
+	eventType := c.Query("type")
+	out := make([]events.Schema, 0, len(all))
+	for _, s := range all {
+		if eventType == "" || s.Type == eventType {
+			out = append(out, s)
+		}
+	}
+	c.JSON(http.StatusOK, EventSchemaListResponse{Schemas: out})
+}
@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventSchemaHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewEventSchemaHandler(zap.NewNop(), events.MustNewRegistry())
+	r.GET("/api/v1/events/schemas", h.List)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/events/schemas", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Schemas []struct {
+			Type    string                 `json:"type"`
+			Version int                    `json:"version"`
+			Schema  map[string]interface{} `json:"schema"`
+		} `json:"schemas"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Schemas)
+	types := map[string]bool{}
+	for _, s := range resp.Schemas {
+		types[s.Type] = true
+		assert.Equal(t, "object", s.Schema["type"], "schema document is embedded as JSON, not a string")
+	}
+	assert.True(t, types["DoseRecorded"])
+	assert.True(t, types["AlertRaised"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/events/schemas?type=InvoicePaid", nil)
+	r.ServeHTTP(w, req)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Schemas, 1)
+	assert.Equal(t, "InvoicePaid", resp.Schemas[0].Type)
+}
@@ -1,7 +1,106 @@
 // Package delivery contains HTTP handlers and request/response models.
 package delivery
 
-// Example: PoolHandler struct
-// type PoolHandler struct {
-//     Usecase usecase.PoolUsecase
-// }
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// Handlers report failures with c.Error and return; middleware.Errors renders the E-API-003
+// envelope. Typed domain errors carry the message the client sees.
+
+func init() {
+	// Name fields in validation errors after their JSON keys, as clients know them.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// bindJSON decodes the request body into v. On failure it records a validation error that
+// lists the offending fields and returns false.
+func bindJSON(c *gin.Context, v any) bool {
+	err := c.ShouldBindJSON(v)
+	if err == nil {
+		return true
+	}
+	_ = c.Error(bindError(err))
+	return false
+}
+
+// bindStrictJSON is bindJSON for bodies that must be exactly one JSON value with no fields
+// beyond those of v, such as documents whose every field is covered by a signature.
+func bindStrictJSON(c *gin.Context, v any) bool {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		if _, terr := dec.Token(); terr != io.EOF {
+			_ = c.Error(domain.Validation("request body must hold a single JSON value"))
+			return false
+		}
+		err = binding.Validator.ValidateStruct(v)
+	}
+	if err == nil {
+		return true
+	}
+	_ = c.Error(bindError(err))
+	return false
+}
+
+func bindError(err error) error {
+	var (
+		verrs   validator.ValidationErrors
+		typeErr *json.UnmarshalTypeError
+		synErr  *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]domain.FieldError, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = domain.FieldError{Field: fe.Field(), Message: ruleMessage(fe)}
+		}
+		return domain.Validation("", fields...)
+	case errors.As(err, &typeErr):
+		return domain.Validation("", domain.FieldError{Field: typeErr.Field, Message: "must be a " + typeErr.Type.String()})
+	case errors.As(err, &synErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return domain.Validation("request body is not valid JSON")
+	case errors.Is(err, io.EOF):
+		return domain.Validation("request body is required")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return domain.Validation("", domain.FieldError{Field: field, Message: "is not a known field"})
+	default:
+		return domain.Validation(err.Error())
+	}
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	if fe.Tag() == "required" {
+		return "is required"
+	}
+	return fmt.Sprintf("failed the %q rule", fe.Tag())
+}
+
+// notFound names the missing resource when err is a bare domain.ErrNotFound from a repository.
+func notFound(err error, resource, id string) error {
+	var typed *domain.Error
+	if errors.Is(err, domain.ErrNotFound) && !errors.As(err, &typed) {
+		return domain.NotFound(resource, id)
+	}
+	return err
+}
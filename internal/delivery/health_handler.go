@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
 	"github.com/mgmacri/pool-maintenance-app/internal/version"
 	"go.uber.org/zap"
 )
@@ -81,7 +82,7 @@ func NewHealthHandler(logger *zap.Logger, checkers ...ReadinessChecker) *HealthH
 // @Success      200  {object}  delivery.HealthCheckResponse
 // @Router       /health [get]
 func (h *HealthHandler) Check(c *gin.Context) { // legacy alias for backward compatibility
-	h.Logger.Info("health check endpoint called (alias for /health/live)", zap.String("path", c.FullPath()))
+	h.logger(c).Info("health check endpoint called (alias for /health/live)", zap.String("path", c.FullPath()))
 	h.livePayload(c)
 }
 
@@ -93,7 +94,7 @@ func (h *HealthHandler) Check(c *gin.Context) { // legacy alias for backward com
 // @Success 200 {object} delivery.HealthCheckResponse
 // @Router /health/live [get]
 func (h *HealthHandler) Live(c *gin.Context) {
-	h.Logger.Debug("liveness probe", zap.String("path", c.FullPath()))
+	h.logger(c).Debug("liveness probe", zap.String("path", c.FullPath()))
 	h.livePayload(c)
 }
 
@@ -106,7 +107,8 @@ func (h *HealthHandler) Live(c *gin.Context) {
 // @Success 503 {object} delivery.ReadinessResponse
 // @Router /health/ready [get]
 func (h *HealthHandler) Ready(c *gin.Context) {
-	h.Logger.Debug("readiness probe", zap.String("path", c.FullPath()))
+	logger := h.logger(c)
+	logger.Debug("readiness probe", zap.String("path", c.FullPath()))
 	depStatuses := make([]DependencyStatus, 0, len(h.checkers))
 	overallStatus := "ok"
 	httpCode := http.StatusOK
@@ -118,7 +120,7 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 			dep.Error = err.Error()
 			overallStatus = "degraded"
 			httpCode = http.StatusServiceUnavailable
-			h.Logger.Warn("readiness dependency failed", zap.String("dependency", chk.Name()), zap.Error(err))
+			logger.Warn("readiness dependency failed", zap.String("dependency", chk.Name()), zap.Error(err))
 		} else {
 			dep.Status = "ok"
 		}
@@ -133,6 +135,11 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 	})
 }
 
+// logger returns h.Logger carrying the request's correlation ids.
+func (h *HealthHandler) logger(c *gin.Context) *zap.Logger {
+	return requestctx.Enrich(c.Request.Context(), h.Logger)
+}
+
 func (h *HealthHandler) livePayload(c *gin.Context) {
 	uptime := time.Since(h.startTime).Seconds()
 	c.JSON(http.StatusOK, HealthCheckResponse{
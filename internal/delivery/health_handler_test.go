@@ -9,8 +9,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestHealthHandler_Check_LegacyAlias(t *testing.T) {
@@ -185,3 +188,21 @@ func validateStandardFields(t *testing.T, body []byte) {
 	assert.IsType(t, "", resp["commit"])
 	assert.IsType(t, "", resp["build_date"])
 }
+
+func TestHealthHandler_LogsWithRequestIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(requestctx.WithRequestID(c.Request.Context(), "req-7"))
+	})
+	r.GET("/health", NewHealthHandler(zap.New(core)).Check)
+
+	req, _ := http.NewRequest("GET", "/health", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "req-7", entries[0].ContextMap()["request_id"])
+	}
+}
@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// AssignJobRequest names the technician a job is routed to.
+type AssignJobRequest struct {
+	TechnicianID string `json:"technician_id" binding:"required" example:"u-42"`
+}
+
+// JobAssignmentHandler routes jobs to technicians.
+type JobAssignmentHandler struct {
+	Logger  *zap.Logger
+	service *usecase.JobAssignmentService
+}
+
+// NewJobAssignmentHandler creates a JobAssignmentHandler backed by the given service.
+func NewJobAssignmentHandler(logger *zap.Logger, service *usecase.JobAssignmentService) *JobAssignmentHandler {
+	return &JobAssignmentHandler{Logger: logger, service: service}
+}
+
+// Assign routes a job to a technician.
+// @Summary Assign job
+// @Description Routes the job to an enabled TECH, who may then record and list its doses. Replaces any previous assignment.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job id"
+// @Param body body delivery.AssignJobRequest true "Technician"
+// @Success 200 {object} domain.JobAssignment
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/jobs/{id}/assignment [put]
+func (h *JobAssignmentHandler) Assign(c *gin.Context) {
+	var req AssignJobRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	assignment, err := h.service.Assign(c.Request.Context(), c.Param("id"), req.TechnicianID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// VerifyRecommendationResponse reports whether a recommendation document is authentic.
+type VerifyRecommendationResponse struct {
+	Valid  bool   `json:"valid"`
+	KeyID  string `json:"key_id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// DoseRecommendationHandler exposes signed dose recommendations.
+type DoseRecommendationHandler struct {
+	Logger  *zap.Logger
+	service *usecase.DoseRecommendationService
+}
+
+// NewDoseRecommendationHandler creates a DoseRecommendationHandler backed by the given service.
+func NewDoseRecommendationHandler(logger *zap.Logger, service *usecase.DoseRecommendationService) *DoseRecommendationHandler {
+	return &DoseRecommendationHandler{Logger: logger, service: service}
+}
+
+// Recommend runs the dosing engine and returns the signed result.
+// @Summary Create signed dose recommendation
+// @Description Runs the dosing engine and signs inputs, engine version and outputs with Ed25519.
+// @Tags doses
+// @Accept json
+// @Produce json
+// @Param body body domain.DoseRecommendationInput true "Engine inputs"
+// @Param Idempotency-Key header string false "Replays the first response when retried with the same key"
+// @Success 201 {object} domain.DoseRecommendation
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/dose-recommendations [post]
+func (h *DoseRecommendationHandler) Recommend(c *gin.Context) {
+	var in domain.DoseRecommendationInput
+	if !bindJSON(c, &in) {
+		return
+	}
+	rec, err := h.service.Recommend(c.Request.Context(), in)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+// Get returns a stored signed recommendation.
+// @Summary Get dose recommendation
+// @Tags doses
+// @Produce json
+// @Param id path string true "Recommendation id"
+// @Success 200 {object} domain.DoseRecommendation
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/dose-recommendations/{id} [get]
+func (h *DoseRecommendationHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	rec, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(notFound(err, "recommendation", id))
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// Verify checks a recommendation document's signature.
+// @Summary Verify dose recommendation
+// @Description Checks that a recommendation document is byte-for-byte what the engine produced and signed. A tampered document returns valid=false. Fields the document does not define are rejected with 400, since the signature could not vouch for them.
+// @Tags doses
+// @Accept json
+// @Produce json
+// @Param body body domain.DoseRecommendation true "Recommendation document as returned by the API"
+// @Success 200 {object} delivery.VerifyRecommendationResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/dose-recommendations/verify [post]
+func (h *DoseRecommendationHandler) Verify(c *gin.Context) {
+	var rec domain.DoseRecommendation
+	if !bindStrictJSON(c, &rec) {
+		return
+	}
+	if err := h.service.Verify(rec); err != nil {
+		c.JSON(http.StatusOK, VerifyRecommendationResponse{Valid: false, KeyID: rec.KeyID, Reason: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, VerifyRecommendationResponse{Valid: true, KeyID: rec.KeyID})
+}
@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubEngine struct{}
+
+func (stubEngine) Version() string { return "stub-1" }
+
+func (stubEngine) Recommend(context.Context, domain.DoseRecommendationInput) ([]domain.RecommendedDose, error) {
+	return []domain.RecommendedDose{{Parameter: "FC", ProductID: "liquid-chlorine-12.5", Unit: "oz", Amount: 32}}, nil
+}
+
+func newTestRecommendationRouter(t *testing.T, engine domain.DosingEngine) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{4}, 32))
+	require.NoError(t, err)
+	svc := usecase.NewDoseRecommendationService(zap.NewNop(), engine, signer, nil, repository.NewInMemoryDoseRecommendationRepository(), metrics.New(nil))
+	h := NewDoseRecommendationHandler(zap.NewNop(), svc)
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.POST("/api/v1/dose-recommendations", h.Recommend)
+	r.POST("/api/v1/dose-recommendations/verify", h.Verify)
+	r.GET("/api/v1/dose-recommendations/:id", h.Get)
+	return r
+}
+
+const recommendationInput = `{"job_id":"job-1","pool_volume_gallons":15000,"readings":{"FC":1.5}}`
+
+func TestDoseRecommendationHandler_SignGetVerify(t *testing.T) {
+	r := newTestRecommendationRouter(t, stubEngine{})
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/dose-recommendations", recommendationInput)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	doc := w.Body.String()
+	var rec domain.DoseRecommendation
+	require.NoError(t, json.Unmarshal([]byte(doc), &rec))
+
+	w = do("GET", "/api/v1/dose-recommendations/"+rec.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = do("GET", "/api/v1/dose-recommendations/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var resp VerifyRecommendationResponse
+	w = do("POST", "/api/v1/dose-recommendations/verify", doc)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Valid)
+
+	tampered := strings.Replace(doc, `"amount":32`, `"amount":16`, 1)
+	require.NotEqual(t, doc, tampered)
+	w = do("POST", "/api/v1/dose-recommendations/verify", tampered)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Valid)
+	assert.NotEmpty(t, resp.Reason)
+
+	w = do("POST", "/api/v1/dose-recommendations/verify", "{")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Fields outside the signed document are not vouched for, so they are rejected rather
+	// than reported valid.
+	extra := strings.Replace(doc, `"outputs":`, `"approved_by":"owner-1","outputs":`, 1)
+	require.NotEqual(t, doc, extra)
+	w = do("POST", "/api/v1/dose-recommendations/verify", extra)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "approved_by")
+	nested := strings.Replace(doc, `"readings":`, `"note":"x","readings":`, 1)
+	w = do("POST", "/api/v1/dose-recommendations/verify", nested)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do("POST", "/api/v1/dose-recommendations/verify", doc+doc)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDoseRecommendationHandler_NoEngine(t *testing.T) {
+	r := newTestRecommendationRouter(t, nil)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/dose-recommendations", strings.NewReader(recommendationInput))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,135 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+const (
+	// ssoStateCookie keeps the login's state in the browser that started it.
+	ssoStateCookie = "sso_state"
+	// ssoCookiePath limits the cookie to the login and callback endpoints.
+	ssoCookiePath = "/api/v1/auth/oidc"
+	// ssoStateCookieMaxAge matches how long a login state is valid, in seconds.
+	ssoStateCookieMaxAge = 10 * 60
+)
+
+// SSOHandlerConfig configures the browser side of single sign-on.
+type SSOHandlerConfig struct {
+	// FrontendURL is where the callback sends the browser, with a one-time code in the
+	// fragment: FrontendURL#sso_code=<code>.
+	FrontendURL string
+	// SecureCookie marks the state cookie Secure; set it when the service is served over
+	// HTTPS.
+	SecureCookie bool
+}
+
+// SSORedeemRequest carries the one-time code from the single sign-on callback.
+type SSORedeemRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// SSOHandler exposes OpenID Connect single sign-on.
+type SSOHandler struct {
+	Logger  *zap.Logger
+	service *usecase.SSOService
+	cfg     SSOHandlerConfig
+}
+
+// NewSSOHandler creates an SSOHandler backed by the given service.
+func NewSSOHandler(logger *zap.Logger, service *usecase.SSOService, cfg SSOHandlerConfig) *SSOHandler {
+	return &SSOHandler{Logger: logger, service: service, cfg: cfg}
+}
+
+// Login redirects the browser to the identity provider.
+// @Summary Start single sign-on
+// @Description Redirects to the configured OpenID Connect provider using the authorization code flow with PKCE. The login's state is also set in an HttpOnly cookie, which the callback requires.
+// @Tags auth
+// @Success 302
+// @Failure 500 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/oidc/login [get]
+func (h *SSOHandler) Login(c *gin.Context) {
+	authURL, state, err := h.service.Begin(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	h.setStateCookie(c, state, ssoStateCookieMaxAge)
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes single sign-on and sends the browser to the front end.
+// @Summary Complete single sign-on
+// @Description The identity provider redirects here. The state must match the cookie set by the login redirect. The ID token is validated, its claims are mapped to roles, and the account is created on first login. The browser is then redirected to the front end with a one-time code in the fragment, which the front end exchanges at /api/v1/auth/oidc/token.
+// @Tags auth
+// @Param state query string true "State from the login redirect"
+// @Param code query string true "Authorization code"
+// @Success 302
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/oidc/callback [get]
+func (h *SSOHandler) Callback(c *gin.Context) {
+	browserState, _ := c.Cookie(ssoStateCookie)
+	h.setStateCookie(c, "", -1)
+	if e := c.Query("error"); e != "" {
+		_ = c.Error(&domain.Error{Kind: domain.ErrInvalidCredentials, Message: "identity provider refused login: " + e})
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		_ = c.Error(domain.Validation("state and code are required"))
+		return
+	}
+	handoff, err := h.service.Complete(c.Request.Context(), state, browserState, code)
+	if errors.Is(err, domain.ErrConflict) {
+		// The account exists but can not take this identity; do not say why.
+		err = domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	// The code goes in the fragment, which browsers neither send to servers nor put in Referer.
+	c.Redirect(http.StatusFound, h.cfg.FrontendURL+"#"+url.Values{"sso_code": {handoff}}.Encode())
+}
+
+// Token exchanges the one-time code from the callback for session tokens.
+// @Summary Redeem single sign-on code
+// @Description Exchanges the one-time code the callback put in the front end's URL fragment for access and refresh tokens. A code is valid for one minute and can be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body delivery.SSORedeemRequest true "One-time code"
+// @Success 200 {object} delivery.TokenResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Router /api/v1/auth/oidc/token [post]
+func (h *SSOHandler) Token(c *gin.Context) {
+	var req SSORedeemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	tokens, err := h.service.Redeem(c.Request.Context(), req.Code)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, newTokenResponse(tokens))
+}
+
+// setStateCookie sets, or with maxAge -1 clears, the state cookie. SameSite=Lax lets it
+// ride along on the provider's top-level redirect back to the callback.
+func (h *SSOHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, state, maxAge, ssoCookiePath, "", h.cfg.SecureCookie, true)
+}
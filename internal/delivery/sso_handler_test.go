@@ -0,0 +1,159 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth/oidctest"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestSSORouter(t *testing.T, idp *oidctest.Provider) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://app.test/api/v1/auth/oidc/callback",
+	})
+	require.NoError(t, err)
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{6}, 32))
+	require.NoError(t, err)
+	audit := usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	users := usecase.NewUserService(zap.NewNop(), repository.NewInMemoryUserRepository(), audit, usecase.DefaultLockoutPolicy)
+	sessions := usecase.NewSessionService(zap.NewNop(), users, nil, auth.NewIssuer(signer, auth.Config{Issuer: "t", Audience: "t"}),
+		repository.NewInMemoryRefreshTokenRepository(), audit, 0)
+	mapping := usecase.DefaultRoleMapping
+	mapping.Map = map[string][]string{"dispatch": {domain.RoleDispatcher}}
+	svc := usecase.NewSSOService(zap.NewNop(), provider, repository.NewInMemorySSOLoginStateRepository(), repository.NewInMemorySSOHandoffRepository(), users, sessions, audit, mapping)
+
+	h := NewSSOHandler(zap.NewNop(), svc, SSOHandlerConfig{FrontendURL: "https://app.test/login"})
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.GET("/api/v1/auth/oidc/login", h.Login)
+	r.GET("/api/v1/auth/oidc/callback", h.Callback)
+	r.POST("/api/v1/auth/oidc/token", h.Token)
+	return r
+}
+
+// ssoGet sends a GET with the given cookies.
+func ssoGet(r *gin.Engine, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", target, nil)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// stateCookie returns the state cookie a login response set.
+func stateCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == ssoStateCookie {
+			return ck
+		}
+	}
+	t.Fatal("no state cookie set")
+	return nil
+}
+
+func TestSSOHandler_LoginAndCallback(t *testing.T) {
+	idp := oidctest.New(t)
+	r := newTestSSORouter(t, idp)
+
+	idp.SetClaims(map[string]any{"sub": "d-1", "email": "dana@hoa.example", "groups": []string{"dispatch"}})
+	w := ssoGet(r, "/api/v1/auth/oidc/login")
+	require.Equal(t, http.StatusFound, w.Code)
+	authURL := w.Header().Get("Location")
+	assert.Contains(t, authURL, idp.Issuer()+"/authorize?")
+	assert.Contains(t, authURL, "code_challenge_method=S256")
+	ck := stateCookie(t, w)
+	assert.True(t, ck.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, ck.SameSite)
+	assert.Equal(t, "/api/v1/auth/oidc", ck.Path)
+
+	cb, err := idp.Login(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, cb.Query().Get("state"), ck.Value)
+	w = ssoGet(r, cb.RequestURI(), ck)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, -1, stateCookie(t, w).MaxAge, "state cookie is cleared")
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.test/login", loc.Scheme+"://"+loc.Host+loc.Path)
+	assert.Empty(t, loc.RawQuery, "nothing secret in the query")
+	fragment, err := url.ParseQuery(loc.Fragment)
+	require.NoError(t, err)
+	handoff := fragment.Get("sso_code")
+	require.NotEmpty(t, handoff)
+	assert.NotContains(t, w.Body.String(), "access_token")
+
+	redeem := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/auth/oidc/token", strings.NewReader(`{"code":"`+handoff+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w = redeem()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var tokens TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, redeem().Code, "code can not be redeemed twice")
+
+	w = ssoGet(r, cb.RequestURI(), ck)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "callback can not be replayed")
+}
+
+func TestSSOHandler_CallbackRequiresStateCookie(t *testing.T) {
+	idp := oidctest.New(t)
+	r := newTestSSORouter(t, idp)
+	idp.SetClaims(map[string]any{"sub": "d-1", "groups": []string{"dispatch"}})
+
+	// Login CSRF: the attacker's callback URL, opened in a browser without the cookie.
+	cb, err := idp.Login(ssoGet(r, "/api/v1/auth/oidc/login").Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, ssoGet(r, cb.RequestURI()).Code)
+
+	// A cookie from a different login does not match either.
+	victim := stateCookie(t, ssoGet(r, "/api/v1/auth/oidc/login"))
+	cb, err = idp.Login(ssoGet(r, "/api/v1/auth/oidc/login").Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, ssoGet(r, cb.RequestURI(), victim).Code)
+}
+
+func TestSSOHandler_CallbackErrors(t *testing.T) {
+	idp := oidctest.New(t)
+	r := newTestSSORouter(t, idp)
+
+	assert.Equal(t, http.StatusUnauthorized, ssoGet(r, "/api/v1/auth/oidc/callback?error=access_denied").Code)
+	assert.Equal(t, http.StatusBadRequest, ssoGet(r, "/api/v1/auth/oidc/callback?state=abc").Code)
+
+	idp.SetClaims(map[string]any{"sub": "g-1", "groups": []string{"guests"}})
+	w := ssoGet(r, "/api/v1/auth/oidc/login")
+	cb, err := idp.Login(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, ssoGet(r, cb.RequestURI(), stateCookie(t, w)).Code)
+}
@@ -0,0 +1,225 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/pagination"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// WebhookDeliveryListResponse wraps a page of webhook delivery history.
+type WebhookDeliveryListResponse struct {
+	Deliveries []domain.WebhookDelivery `json:"deliveries"`
+	// NextCursor fetches the following page; absent on the last page.
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJmIjoi...In19.Yk3c..."`
+}
+
+// CreateWebhookSubscriptionRequest is the body of POST /api/v1/admin/webhooks/subscriptions.
+type CreateWebhookSubscriptionRequest struct {
+	TargetURL string `json:"target_url" binding:"required" example:"https://partner.example.com/hooks/pool"`
+	// EventTypes limits the subscription to these events; empty means every event.
+	EventTypes []string `json:"event_types,omitempty" example:"JobCompleted,DoseRecorded"`
+}
+
+// WebhookSubscriptionCreatedResponse is a new subscription. Secret is shown only in this
+// response.
+type WebhookSubscriptionCreatedResponse struct {
+	domain.WebhookSubscription
+	Secret string `json:"secret" example:"q8Vw…"`
+}
+
+// WebhookSecretRotatedResponse is a subscription with its new secret. Secret is shown only in
+// this response.
+type WebhookSecretRotatedResponse struct {
+	domain.WebhookSubscription
+	Secret string `json:"secret" example:"q8Vw…"`
+}
+
+// WebhookSubscriptionListResponse lists webhook subscriptions without their secrets.
+type WebhookSubscriptionListResponse struct {
+	Subscriptions []domain.WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookHandler exposes admin endpoints for managing webhook subscriptions and for
+// inspecting and replaying deliveries.
+type WebhookHandler struct {
+	Logger  *zap.Logger
+	service *usecase.WebhookService
+	cursors *pagination.Codec
+}
+
+// NewWebhookHandler creates a WebhookHandler backed by the given service.
+func NewWebhookHandler(logger *zap.Logger, service *usecase.WebhookService, cursors *pagination.Codec) *WebhookHandler {
+	return &WebhookHandler{Logger: logger, service: service, cursors: cursors}
+}
+
+// CreateSubscription registers a partner endpoint.
+// @Summary Create webhook subscription
+// @Description Registers an endpoint for the given event types, or for every event when none are listed. The response carries the HMAC signing secret; it is returned once and never listed.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param body body delivery.CreateWebhookSubscriptionRequest true "Subscription"
+// @Param Idempotency-Key header string false "Replays the first response when retried with the same key"
+// @Success 201 {object} delivery.WebhookSubscriptionCreatedResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/subscriptions [post]
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req CreateWebhookSubscriptionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	sub, secret, err := h.service.CreateSubscription(c.Request.Context(), usecase.CreateWebhookSubscriptionInput{
+		TargetURL:  req.TargetURL,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, WebhookSubscriptionCreatedResponse{WebhookSubscription: *sub, Secret: secret})
+}
+
+// ListSubscriptions returns every subscription, without secrets.
+// @Summary List webhook subscriptions
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} delivery.WebhookSubscriptionListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/subscriptions [get]
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.service.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, WebhookSubscriptionListResponse{Subscriptions: subs})
+}
+
+// RotateSecret replaces the signing secret of a subscription.
+// @Summary Rotate webhook secret
+// @Description Issues a new HMAC signing secret, returned once. Until previous_secret_expires_at (WEBHOOK_SECRET_GRACE_PERIOD) deliveries are signed with both the new and the previous secret, so the receiver can switch over without rejecting any. Rotating again within that period retires the previous secret at once.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription id"
+// @Success 200 {object} delivery.WebhookSecretRotatedResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/subscriptions/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	id := c.Param("id")
+	sub, secret, err := h.service.RotateSecret(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(notFound(err, "webhook subscription", id))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, WebhookSecretRotatedResponse{WebhookSubscription: *sub, Secret: secret})
+}
+
+// DeleteSubscription removes a subscription.
+// @Summary Delete webhook subscription
+// @Description Stops deliveries to the endpoint. Deliveries already queued for it are dead-lettered when they come due.
+// @Tags webhooks
+// @Param id path string true "Subscription id"
+// @Success 204
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/subscriptions/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.DeleteSubscription(c.Request.Context(), id); err != nil {
+		_ = c.Error(notFound(err, "webhook subscription", id))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// deliveryListSpec whitelists the filters and sort keys of the delivery history.
+var deliveryListSpec = pagination.Spec{
+	Filters:      []string{"subscription_id", "status"},
+	Sorts:        []string{domain.WebhookDeliverySortCreatedAt, domain.WebhookDeliverySortNextAttemptAt},
+	DefaultSort:  "-" + domain.WebhookDeliverySortCreatedAt,
+	DefaultLimit: 50,
+	MaxLimit:     500,
+}
+
+// ListDeliveries returns a page of webhook delivery history, newest first.
+// @Summary List webhook deliveries
+// @Description Returns delivery history with attempt counts and status. Filter by subscription or status. Pages are cursor based (E-API-004).
+// @Tags webhooks
+// @Produce json
+// @Param subscription_id query string false "Subscription id"
+// @Param status query string false "Delivery status" Enums(PENDING, DELIVERED, FAILED, DEAD_LETTERED)
+// @Param sort query string false "Sort key, - for descending" Enums(created_at, -created_at, next_attempt_at, -next_attempt_at) default(-created_at)
+// @Param limit query int false "Page size (1-500)" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} delivery.WebhookDeliveryListResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	q, err := h.cursors.Parse(c.Request.URL.Query(), deliveryListSpec)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), domain.WebhookDeliveryFilter{
+		SubscriptionID: q.Filters["subscription_id"],
+		Status:         domain.WebhookDeliveryStatus(q.Filters["status"]),
+		PageRequest:    q.Request(),
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	deliveries, next := pagination.Next(h.cursors, q, deliveries)
+	c.JSON(http.StatusOK, WebhookDeliveryListResponse{Deliveries: deliveries, NextCursor: next})
+}
+
+// GetDelivery returns a single delivery with its attempt history.
+// @Summary Get webhook delivery
+// @Description Returns one delivery including every attempt (status code, error, duration).
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Delivery id"
+// @Success 200 {object} domain.WebhookDelivery
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id := c.Param("id")
+	d, err := h.service.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(notFound(err, "webhook delivery", id))
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// Redeliver queues a new delivery that replays the payload of an existing one.
+// @Summary Redeliver webhook
+// @Description Queues a fresh delivery (new id, full retry budget) carrying the original payload. Works for any status, including DEAD_LETTERED.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Delivery id to replay"
+// @Param Idempotency-Key header string false "Replays the first response when retried with the same key"
+// @Success 202 {object} domain.WebhookDelivery
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id := c.Param("id")
+	d, err := h.service.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(notFound(err, "webhook delivery", id))
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
@@ -0,0 +1,159 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/middleware"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupWebhookRouter(t *testing.T) (*gin.Engine, *usecase.WebhookService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	subs := repository.NewInMemoryWebhookSubscriptionRepository()
+	require.NoError(t, subs.Create(context.Background(), &domain.WebhookSubscription{
+		ID: "sub-1", TargetURL: "http://127.0.0.1:0", Secret: "s", Active: true,
+	}))
+	svc := usecase.NewWebhookService(zap.NewNop(), subs, repository.NewInMemoryWebhookDeliveryRepository(),
+		usecase.NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()), nil, usecase.DefaultWebhookConfig())
+	h := NewWebhookHandler(zap.NewNop(), svc, testCursors)
+
+	r := gin.New()
+	r.Use(middleware.Errors(zap.NewNop()))
+	r.POST("/api/v1/admin/webhooks/subscriptions", h.CreateSubscription)
+	r.GET("/api/v1/admin/webhooks/subscriptions", h.ListSubscriptions)
+	r.DELETE("/api/v1/admin/webhooks/subscriptions/:id", h.DeleteSubscription)
+	r.POST("/api/v1/admin/webhooks/subscriptions/:id/rotate-secret", h.RotateSecret)
+	r.GET("/api/v1/admin/webhooks/deliveries", h.ListDeliveries)
+	r.GET("/api/v1/admin/webhooks/deliveries/:id", h.GetDelivery)
+	r.POST("/api/v1/admin/webhooks/deliveries/:id/redeliver", h.Redeliver)
+	return r, svc
+}
+
+func TestWebhookHandler_ListAndGet(t *testing.T) {
+	r, svc := setupWebhookRouter(t)
+	queued, err := svc.Enqueue(context.Background(), "evt-1", "JobCompleted", []byte(`{}`))
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/webhooks/deliveries?status=PENDING", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var list WebhookDeliveryListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Deliveries, 1)
+	assert.Equal(t, queued[0].ID, list.Deliveries[0].ID)
+	assert.Equal(t, 0, list.Deliveries[0].AttemptCount)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/admin/webhooks/deliveries/"+queued[0].ID, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got domain.WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, domain.WebhookDeliveryPending, got.Status)
+}
+
+func TestWebhookHandler_ListRejectsBadLimit(t *testing.T) {
+	r, _ := setupWebhookRouter(t)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/webhooks/deliveries?limit=-1", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	r, svc := setupWebhookRouter(t)
+	queued, err := svc.Enqueue(context.Background(), "evt-1", "JobCompleted", []byte(`{}`))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/admin/webhooks/deliveries/"+queued[0].ID+"/redeliver", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var got domain.WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, queued[0].ID, got.RedeliveryOf)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/admin/webhooks/deliveries/nope/redeliver", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhookHandler_ManageSubscriptions(t *testing.T) {
+	r, svc := setupWebhookRouter(t)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/v1/admin/webhooks/subscriptions", `{"target_url":"https://partner.example.com/hooks","event_types":["AlertRaised","AlertRaised"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var created WebhookSubscriptionCreatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Secret)
+	assert.True(t, created.Active)
+	assert.Equal(t, []string{"AlertRaised"}, created.EventTypes)
+
+	queued, err := svc.Enqueue(context.Background(), "evt-1", "AlertRaised", []byte(`{}`))
+	require.NoError(t, err)
+	require.Len(t, queued, 2, "the new subscription receives events")
+
+	w = do("GET", "/api/v1/admin/webhooks/subscriptions", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret, "secrets are never listed")
+	var list WebhookSubscriptionListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Subscriptions, 2)
+	assert.Equal(t, created.ID, list.Subscriptions[0].ID, "newest first")
+
+	for _, body := range []string{
+		`{"target_url":"ftp://partner.example.com"}`,
+		`{"target_url":"/hooks"}`,
+		`{"target_url":"https://user:pw@partner.example.com"}`,
+		`{"target_url":"https://partner.example.com","event_types":["Unknown"]}`,
+		`{}`,
+	} {
+		w = do("POST", "/api/v1/admin/webhooks/subscriptions", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = do("POST", "/api/v1/admin/webhooks/subscriptions/"+created.ID+"/rotate-secret", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var rotated WebhookSecretRotatedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.NotEmpty(t, rotated.Secret)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.NotNil(t, rotated.PreviousSecretExpiresAt)
+	assert.NotContains(t, w.Body.String(), created.Secret, "the previous secret is never shown")
+	w = do("POST", "/api/v1/admin/webhooks/subscriptions/missing/rotate-secret", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do("DELETE", "/api/v1/admin/webhooks/subscriptions/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = do("DELETE", "/api/v1/admin/webhooks/subscriptions/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	subs, err := svc.ListSubscriptions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "sub-1", subs[0].ID)
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Alert severities (E-DOM-006).
+const (
+	SeverityInfo     = "INFO"
+	SeverityWarning  = "WARNING"
+	SeverityCritical = "CRITICAL"
+)
+
+// Alert is raised when a chemistry reading falls outside its target range (E-DOM-006).
+type Alert struct {
+	ID           string  `json:"id" example:"6f1c0b7e-3d2a-4e5f-9a8b-7c6d5e4f3a2b"`
+	JobID        string  `json:"job_id" example:"job-123"`
+	JobReadingID string  `json:"job_reading_id" example:"2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"`
+	Parameter    string  `json:"parameter" example:"FC"`
+	Value        float64 `json:"value" example:"0.4"`
+	AlertType    string  `json:"alert_type" example:"FC_LOW"`
+	Severity     string  `json:"severity" example:"CRITICAL"`
+	Acknowledged bool    `json:"acknowledged"`
+	// AcknowledgedAt and AcknowledgedBy record who acknowledged the alert, once someone has.
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty" example:"dispatcher-7"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ChemistryRange is the target band for a parameter and the wider band outside which a
+// reading is critical.
+type ChemistryRange struct {
+	Min, Max                 float64
+	CriticalMin, CriticalMax float64
+}
+
+// DefaultChemistryRanges are residential pool targets. TC has no range of its own; the
+// combined chlorine it implies is judged through FC.
+var DefaultChemistryRanges = map[string]ChemistryRange{
+	"FC":   {Min: 1, Max: 4, CriticalMin: 0.5, CriticalMax: 10},
+	"pH":   {Min: 7.2, Max: 7.8, CriticalMin: 6.8, CriticalMax: 8.2},
+	"TA":   {Min: 80, Max: 120, CriticalMin: 50, CriticalMax: 180},
+	"CH":   {Min: 200, Max: 400, CriticalMin: 100, CriticalMax: 1000},
+	"CYA":  {Min: 30, Max: 50, CriticalMin: 0, CriticalMax: 100},
+	"Salt": {Min: 2700, Max: 3400, CriticalMin: 2000, CriticalMax: 4500},
+}
+
+// Classify returns the alert type and severity for value, or ok=false when it is in range.
+func (r ChemistryRange) Classify(parameter string, value float64) (alertType, severity string, ok bool) {
+	switch {
+	case value < r.Min:
+		alertType = parameter + "_LOW"
+	case value > r.Max:
+		alertType = parameter + "_HIGH"
+	default:
+		return "", "", false
+	}
+	severity = SeverityWarning
+	if value < r.CriticalMin || value > r.CriticalMax {
+		severity = SeverityCritical
+	}
+	return alertType, severity, true
+}
+
+// Alert sort keys. The default order is newest first.
+const (
+	AlertSortCreatedAt = "created_at"
+	AlertSortSeverity  = "severity"
+)
+
+// severityRank orders severities from least to most urgent.
+var severityRank = map[string]int64{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+// PagePosition implements Pageable. Sorting by severity orders by urgency, then by time.
+func (a Alert) PagePosition(sort string) PagePosition {
+	if sort == AlertSortSeverity {
+		return PagePosition{Key: SortInt(severityRank[a.Severity]) + "/" + SortTime(a.CreatedAt), ID: a.ID}
+	}
+	return PagePosition{Key: SortTime(a.CreatedAt), ID: a.ID}
+}
+
+// AlertFilter narrows an alert listing. Zero values mean "any".
+type AlertFilter struct {
+	JobID        string
+	Severity     string
+	Acknowledged *bool
+	PageRequest
+}
+
+// Matches reports whether a satisfies every set criterion of f (the page is ignored).
+func (f AlertFilter) Matches(a Alert) bool {
+	switch {
+	case f.JobID != "" && a.JobID != f.JobID:
+		return false
+	case f.Severity != "" && a.Severity != f.Severity:
+		return false
+	case f.Acknowledged != nil && a.Acknowledged != *f.Acknowledged:
+		return false
+	}
+	return true
+}
+
+// AlertRepository persists alerts.
+type AlertRepository interface {
+	Create(ctx context.Context, a *Alert) error
+	Get(ctx context.Context, id string) (*Alert, error)
+	// Modify applies fn to the stored alert and saves the result unless fn returns an error,
+	// which Modify returns. Concurrent Modify calls on an alert run one at a time.
+	Modify(ctx context.Context, id string, fn func(*Alert) error) (*Alert, error)
+	// List returns a page of the alerts matching f, newest first by default.
+	List(ctx context.Context, f AlertFilter) ([]Alert, error)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// RoleIntegration is the role of a request authenticated with an API key. It is never
+// assigned to users; what a key may do is limited by its scopes.
+const RoleIntegration = "INTEGRATION"
+
+// API key scopes are "<resource>:<read|write>". write does not imply read.
+const (
+	ScopeJobsRead             = "jobs:read"
+	ScopeJobsWrite            = "jobs:write"
+	ScopeRecommendationsRead  = "recommendations:read"
+	ScopeRecommendationsWrite = "recommendations:write"
+	ScopeEventsRead           = "events:read"
+	ScopeAuditRead            = "audit:read"
+	ScopeExportsRead          = "exports:read"
+)
+
+// APIKeyScopes lists every scope a key may be granted.
+var APIKeyScopes = []string{
+	ScopeJobsRead, ScopeJobsWrite,
+	ScopeRecommendationsRead, ScopeRecommendationsWrite,
+	ScopeEventsRead, ScopeAuditRead, ScopeExportsRead,
+}
+
+// APIKey is a long-lived credential for a machine client such as an integration partner.
+// Only a hash of the key is stored; Prefix is its public part, shown in listings and logs
+// so a key can be identified without revealing it.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key may be used at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool { return slices.Contains(k.Scopes, scope) }
+
+// APIKeyRepository persists API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *APIKey) error
+	// Get returns the key with the given id or ErrNotFound.
+	Get(ctx context.Context, id string) (*APIKey, error)
+	// GetByHash returns the key with the given hash or ErrNotFound.
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	// Modify applies fn to the stored key and saves the result unless fn returns an error,
+	// which Modify returns; ErrNotFound if there is no such key. Concurrent Modify calls on a
+	// key run one at a time, so fn always sees the latest RevokedAt and LastUsedAt.
+	Modify(ctx context.Context, id string, fn func(*APIKey) error) (*APIKey, error)
+	// List returns every key, newest first.
+	List(ctx context.Context) ([]APIKey, error)
+}
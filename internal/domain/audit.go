@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent is an immutable record of who did what to which entity (E-AUD-001).
+type AuditEvent struct {
+	ID         string          `json:"id" example:"0b8f6c2e-5a1d-4e3f-8c7b-9a6d5e4f3c2b"`
+	ActorID    string          `json:"actor_id" example:"tech-42"`
+	ActorRole  string          `json:"actor_role" example:"TECHNICIAN"`
+	ActionType string          `json:"action_type" example:"DOSE_RECORDED"`
+	EntityType string          `json:"entity_type" example:"dose_event"`
+	EntityID   string          `json:"entity_id" example:"2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	RequestID  string          `json:"request_id,omitempty" example:"5f2b8c1d9e3a4f6b7c8d9e0f1a2b3c4d"`
+	TraceID    string          `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	// Sequence, PrevHash, Hash and HashVersion are assigned by the repository on Append (see
+	// ChainAudit).
+	Sequence    int64  `json:"sequence" example:"1"`
+	PrevHash    string `json:"prev_hash"`
+	Hash        string `json:"hash"`
+	HashVersion int    `json:"hash_version" example:"1"`
+}
+
+// ChainSequence, ChainPrevHash, ChainHash and ChainContent link AuditEvent into a hash chain.
+func (e AuditEvent) ChainSequence() int64  { return e.Sequence }
+func (e AuditEvent) ChainPrevHash() string { return e.PrevHash }
+func (e AuditEvent) ChainHash() string     { return e.Hash }
+
+// ChainContent is the canonical encoding of e under its HashVersion.
+func (e AuditEvent) ChainContent() ([]byte, error) {
+	if e.HashVersion != ChainHashV1 {
+		return nil, unknownHashVersion(e.HashVersion)
+	}
+	enc := newChainEncoder(ChainHashV1)
+	enc.string(e.ID)
+	enc.string(e.ActorID)
+	enc.string(e.ActorRole)
+	enc.string(e.ActionType)
+	enc.string(e.EntityType)
+	enc.string(e.EntityID)
+	enc.json(e.Metadata)
+	enc.time(e.OccurredAt)
+	enc.string(e.RequestID)
+	enc.string(e.TraceID)
+	enc.int(e.Sequence)
+	enc.string(e.PrevHash)
+	return enc.encoded()
+}
+
+// Audit event sort keys. The default order is newest first.
+const (
+	AuditSortOccurredAt = "occurred_at"
+	AuditSortSequence   = "sequence"
+)
+
+// PagePosition implements Pageable.
+func (e AuditEvent) PagePosition(sort string) PagePosition {
+	if sort == AuditSortSequence {
+		return PagePosition{Key: SortInt(e.Sequence), ID: e.ID}
+	}
+	return PagePosition{Key: SortTime(e.OccurredAt), ID: e.ID}
+}
+
+// AuditFilter selects audit events. Zero values match everything; From is inclusive and
+// To is exclusive.
+type AuditFilter struct {
+	ActorID    string
+	EntityType string
+	EntityID   string
+	From       time.Time
+	To         time.Time
+	PageRequest
+}
+
+// Matches reports whether e satisfies every set criterion of f (the page is ignored).
+func (f AuditFilter) Matches(e AuditEvent) bool {
+	switch {
+	case f.ActorID != "" && e.ActorID != f.ActorID:
+		return false
+	case f.EntityType != "" && e.EntityType != f.EntityType:
+		return false
+	case f.EntityID != "" && e.EntityID != f.EntityID:
+		return false
+	case !f.From.IsZero() && e.OccurredAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !e.OccurredAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// AuditRepository is append-only: there is deliberately no way to update or delete an
+// event. Append chains e to the current head and returns ErrConflict when an event with
+// the same id already exists.
+type AuditRepository interface {
+	Append(ctx context.Context, e *AuditEvent) error
+	Query(ctx context.Context, f AuditFilter) ([]AuditEvent, error)
+	// Head returns the newest link of the audit chain.
+	Head(ctx context.Context) (ChainHead, error)
+	// ListChain returns every event in sequence order, for verification and export.
+	ListChain(ctx context.Context) ([]AuditEvent, error)
+}
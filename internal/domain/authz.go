@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// ErrForbidden is returned when an authenticated caller may not perform an action or see a
+// resource (E-SEC-002).
+var ErrForbidden = errors.New("forbidden")
+
+// Roles (E-SEC-002). Internal operations roles and the customer portal role are disjoint
+// scopes (E-ARCH-005).
+const (
+	RoleOwner      = "OWNER"
+	RoleDispatcher = "DISPATCHER"
+	RoleTech       = "TECH"
+	RoleCustomer   = "CUSTOMER"
+)
+
+// Role sets routes are guarded with.
+var (
+	// AdminRoles may manage the system: users, sessions, webhooks, audit.
+	AdminRoles = []string{RoleOwner}
+	// StaffRoles are internal operations roles: office and field staff.
+	StaffRoles = []string{RoleOwner, RoleDispatcher, RoleTech}
+	// AllRoles includes the customer portal.
+	AllRoles = []string{RoleOwner, RoleDispatcher, RoleTech, RoleCustomer}
+)
+
+// HasRole reports whether p holds any of roles.
+func (p Principal) HasRole(roles ...string) bool {
+	for _, r := range roles {
+		if slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// JobAssignment routes a job to a technician.
+type JobAssignment struct {
+	JobID        string `json:"job_id" example:"job-123"`
+	TechnicianID string `json:"technician_id" example:"u-42"`
+}
+
+// JobAssignmentRepository records which technician a job is routed to. It is the ownership
+// source for job-scoped resources: a TECH may only act on jobs assigned to them.
+type JobAssignmentRepository interface {
+	// AssignedTechnician returns the technician user id for jobID, or ErrNotFound.
+	AssignedTechnician(ctx context.Context, jobID string) (string, error)
+	// Assign routes jobID to technicianID, replacing any previous assignment.
+	Assign(ctx context.Context, jobID, technicianID string) error
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Hash chains make the audit trail and dose log tamper-evident. Every record stores the
+// hash of its predecessor and a hash over its own contents, so editing, inserting or
+// deleting a record breaks every link after it.
+const (
+	ChainAudit = "audit"
+	ChainDose  = "dose"
+)
+
+// ChainHashV1 is the first canonical encoding of chained records. Records store the
+// version their hash was computed with, so a later version can cover new fields while
+// older records still verify.
+const ChainHashV1 = 1
+
+// chainEncoder writes the canonical content of a chained record: the version byte, then a
+// fixed list of fields, each prefixed with its length so that no two lists encode alike.
+// Only fields written here are hashed; adding a field to a struct leaves existing hashes
+// unchanged, and hashing it takes a new version.
+type chainEncoder struct {
+	buf bytes.Buffer
+	err error
+}
+
+func newChainEncoder(version int) *chainEncoder {
+	e := &chainEncoder{}
+	e.buf.WriteByte(byte(version))
+	return e
+}
+
+func (e *chainEncoder) bytes(b []byte) {
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(len(b))))
+	e.buf.Write(b)
+}
+
+func (e *chainEncoder) string(s string) { e.bytes([]byte(s)) }
+
+func (e *chainEncoder) int(n int64) { e.bytes(binary.BigEndian.AppendUint64(nil, uint64(n))) }
+
+// float writes v's IEEE 754 bits, or an empty field for nil.
+func (e *chainEncoder) float(v *float64) {
+	if v == nil {
+		e.bytes(nil)
+		return
+	}
+	e.bytes(binary.BigEndian.AppendUint64(nil, math.Float64bits(*v)))
+}
+
+// floatMap writes the number of entries in m, then each key and value in key order.
+func (e *chainEncoder) floatMap(m map[string]float64) {
+	keys := slices.Sorted(maps.Keys(m))
+	e.int(int64(len(keys)))
+	for _, k := range keys {
+		v := m[k]
+		e.string(k)
+		e.float(&v)
+	}
+}
+
+func (e *chainEncoder) time(t time.Time) { e.string(t.UTC().Format(time.RFC3339Nano)) }
+
+// json writes raw compacted, as it reads back after a JSON round trip.
+func (e *chainEncoder) json(raw json.RawMessage) {
+	var compact bytes.Buffer
+	if len(raw) > 0 {
+		if err := json.Compact(&compact, raw); err != nil && e.err == nil {
+			e.err = err
+		}
+	}
+	e.bytes(compact.Bytes())
+}
+
+func (e *chainEncoder) encoded() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+func unknownHashVersion(v int) error {
+	return fmt.Errorf("unknown hash version %d", v)
+}
+
+// ChainHead is the newest link of a chain. An empty chain has Sequence 0.
+type ChainHead struct {
+	Sequence int64  `json:"sequence"`
+	Hash     string `json:"hash"`
+}
+
+// ChainCheckpoint is a signed statement that a chain had Hash at Sequence. Checkpoints
+// detect a chain that was rewritten wholesale (every hash recomputed) or truncated.
+type ChainCheckpoint struct {
+	ID        string    `json:"id"`
+	Chain     string    `json:"chain" example:"audit"`
+	Sequence  int64     `json:"sequence" example:"1024"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	KeyID     string    `json:"key_id"`
+	Signature []byte    `json:"signature" swaggertype:"string" format:"base64"`
+}
+
+// CheckpointRepository is append-only storage for checkpoints.
+type CheckpointRepository interface {
+	Append(ctx context.Context, cp *ChainCheckpoint) error
+	// List returns the checkpoints of chain, oldest first.
+	List(ctx context.Context, chain string) ([]ChainCheckpoint, error)
+}
+
+// ChainExport is everything verify-audit needs to check both chains offline.
+type ChainExport struct {
+	AuditEvents []AuditEvent      `json:"audit_events"`
+	DoseEvents  []DoseEvent       `json:"dose_events"`
+	Checkpoints []ChainCheckpoint `json:"checkpoints"`
+	ExportedAt  time.Time         `json:"exported_at"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DoseParameters are the chemistry parameters a dose can target (E-DOM-003).
+var DoseParameters = []string{"FC", "TC", "pH", "TA", "CH", "CYA", "Salt"}
+
+// IsDoseParameter reports whether p is one of DoseParameters.
+func IsDoseParameter(p string) bool {
+	for _, known := range DoseParameters {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DoseEvent is a chemical dose actually applied during a job (E-DOM-004).
+type DoseEvent struct {
+	ID                string    `json:"id" example:"2c9b1f0e-8d3a-4b57-9f61-0e7d5c4b3a21"`
+	JobID             string    `json:"job_id" example:"job-123"`
+	Parameter         string    `json:"parameter" example:"FC"`
+	RecommendedAmount *float64  `json:"recommended_amount,omitempty" example:"32"`
+	ActualAmount      float64   `json:"actual_amount" example:"30"`
+	ProductID         string    `json:"product_id" example:"liquid-chlorine-12.5"`
+	Unit              string    `json:"unit" example:"oz"`
+	BeforeValue       *float64  `json:"before_value,omitempty" example:"1.2"`
+	AfterValue        *float64  `json:"after_value,omitempty" example:"3.0"`
+	UserID            string    `json:"user_id" example:"tech-42"`
+	CreatedAt         time.Time `json:"created_at"`
+	// Sequence, PrevHash, Hash and HashVersion are assigned by the repository on Create (see
+	// ChainDose).
+	Sequence    int64  `json:"sequence" example:"1"`
+	PrevHash    string `json:"prev_hash"`
+	Hash        string `json:"hash"`
+	HashVersion int    `json:"hash_version" example:"1"`
+}
+
+// ChainSequence, ChainPrevHash, ChainHash and ChainContent link DoseEvent into a hash chain.
+func (e DoseEvent) ChainSequence() int64  { return e.Sequence }
+func (e DoseEvent) ChainPrevHash() string { return e.PrevHash }
+func (e DoseEvent) ChainHash() string     { return e.Hash }
+
+// ChainContent is the canonical encoding of e under its HashVersion.
+func (e DoseEvent) ChainContent() ([]byte, error) {
+	if e.HashVersion != ChainHashV1 {
+		return nil, unknownHashVersion(e.HashVersion)
+	}
+	enc := newChainEncoder(ChainHashV1)
+	enc.string(e.ID)
+	enc.string(e.JobID)
+	enc.string(e.Parameter)
+	enc.float(e.RecommendedAmount)
+	enc.float(&e.ActualAmount)
+	enc.string(e.ProductID)
+	enc.string(e.Unit)
+	enc.float(e.BeforeValue)
+	enc.float(e.AfterValue)
+	enc.string(e.UserID)
+	enc.time(e.CreatedAt)
+	enc.int(e.Sequence)
+	enc.string(e.PrevHash)
+	return enc.encoded()
+}
+
+// Dose event sort keys. The default order is oldest first.
+const (
+	DoseSortCreatedAt = "created_at"
+	DoseSortParameter = "parameter"
+)
+
+// PagePosition implements Pageable.
+func (e DoseEvent) PagePosition(sort string) PagePosition {
+	if sort == DoseSortParameter {
+		return PagePosition{Key: e.Parameter, ID: e.ID}
+	}
+	return PagePosition{Key: SortTime(e.CreatedAt), ID: e.ID}
+}
+
+// DoseEventRepository persists dose events. Create chains ev to the current head.
+type DoseEventRepository interface {
+	Create(ctx context.Context, ev *DoseEvent) error
+	// Get returns the dose with the given id or ErrNotFound.
+	Get(ctx context.Context, id string) (*DoseEvent, error)
+	// ListByJob returns a page of the doses recorded for a job, oldest first by default.
+	ListByJob(ctx context.Context, jobID string, page PageRequest) ([]DoseEvent, error)
+	// Head returns the newest link of the dose chain.
+	Head(ctx context.Context) (ChainHead, error)
+	// ListChain returns every dose in sequence order, for verification and export.
+	ListChain(ctx context.Context) ([]DoseEvent, error)
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by repositories and use cases when a requested entity does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidInput is wrapped by use cases when caller-supplied data fails validation.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrConflict is returned when a write collides with existing state, such as a duplicate id.
+var ErrConflict = errors.New("conflict")
+
+// ErrUnavailable is returned when a dependency the operation needs is not configured or
+// not reachable; retrying later may succeed.
+var ErrUnavailable = errors.New("unavailable")
+
+// ErrRateLimited is returned when a caller has used up a rate limit budget; retrying after
+// the budget refills may succeed.
+var ErrRateLimited = errors.New("rate limited")
+
+// FieldError is one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field" example:"pool_volume_gallons"`
+	Message string `json:"message" example:"must be positive"`
+}
+
+// Error is a typed domain error. Kind is one of the sentinel errors (ErrNotFound,
+// ErrInvalidInput, ErrConflict, ErrForbidden, ErrUnavailable, ErrRateLimited), so errors.Is works the same
+// for typed errors and for sentinels wrapped with fmt.Errorf. Message is written for API
+// clients and may be shown as is.
+type Error struct {
+	Kind    error
+	Message string
+	// Fields lists the invalid fields of a validation error.
+	Fields []FieldError
+}
+
+// Error formats like fmt.Errorf("%w: message", kind).
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && len(e.Fields) > 0 {
+		parts := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			parts[i] = f.Field + " " + f.Message
+		}
+		msg = strings.Join(parts, "; ")
+	}
+	if msg == "" {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + msg
+}
+
+// Unwrap returns Kind.
+func (e *Error) Unwrap() error { return e.Kind }
+
+// NotFound reports that the named resource does not exist.
+func NotFound(resource, id string) *Error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf("%s %q not found", resource, id)}
+}
+
+// Validation reports invalid input. Message may be empty when fields say it all.
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrInvalidInput, Message: message, Fields: fields}
+}
+
+// Conflict reports a write that collides with existing state.
+func Conflict(message string) *Error { return &Error{Kind: ErrConflict, Message: message} }
+
+// Forbidden reports an action the caller may not perform.
+func Forbidden(message string) *Error { return &Error{Kind: ErrForbidden, Message: message} }
+
+// Unavailable reports a missing or unreachable dependency.
+func Unavailable(message string) *Error { return &Error{Kind: ErrUnavailable, Message: message} }
+
+// RateLimited reports a caller that has used up a rate limit budget.
+func RateLimited(message string) *Error { return &Error{Kind: ErrRateLimited, Message: message} }
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbound domain event types. Each has one or more versioned JSON Schemas in internal/events/schemas.
+const (
+	EventJobCompleted = "JobCompleted"
+	EventDoseRecorded = "DoseRecorded"
+	EventInvoicePaid  = "InvoicePaid"
+	EventAlertRaised  = "AlertRaised"
+)
+
+// EventTypes lists every outbound event type, for validating webhook subscriptions.
+var EventTypes = []string{EventJobCompleted, EventDoseRecorded, EventInvoicePaid, EventAlertRaised}
+
+// Event is the envelope every outbound domain event is published in. Data must validate
+// against the schema registered for (Type, SchemaVersion).
+type Event struct {
+	ID            string          `json:"id" example:"9d2b5c8e-0a4e-4a3b-8b71-3f1c2e4d5a6b"`
+	Type          string          `json:"type" example:"DoseRecorded"`
+	SchemaVersion int             `json:"schema_version" example:"1"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// JobCompletedV1 is the data of a JobCompleted v1 event.
+type JobCompletedV1 struct {
+	JobID        string    `json:"job_id"`
+	PoolID       string    `json:"pool_id"`
+	TechnicianID string    `json:"technician_id"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// DoseRecordedV1 is the data of a DoseRecorded v1 event (fields follow E-DOM-004).
+type DoseRecordedV1 struct {
+	DoseEventID       string    `json:"dose_event_id"`
+	JobID             string    `json:"job_id"`
+	Parameter         string    `json:"parameter"`
+	ProductID         string    `json:"product_id"`
+	Unit              string    `json:"unit"`
+	RecommendedAmount *float64  `json:"recommended_amount,omitempty"`
+	ActualAmount      float64   `json:"actual_amount"`
+	RecordedBy        string    `json:"recorded_by"`
+	RecordedAt        time.Time `json:"recorded_at"`
+}
+
+// InvoicePaidV1 is the data of an InvoicePaid v1 event.
+type InvoicePaidV1 struct {
+	InvoiceID   string    `json:"invoice_id"`
+	CustomerID  string    `json:"customer_id"`
+	AmountCents int64     `json:"amount_cents"`
+	Currency    string    `json:"currency"`
+	PaidAt      time.Time `json:"paid_at"`
+}
+
+// AlertRaisedV1 is the data of an AlertRaised v1 event (fields follow E-DOM-006).
+type AlertRaisedV1 struct {
+	AlertID      string    `json:"alert_id"`
+	JobReadingID string    `json:"job_reading_id"`
+	AlertType    string    `json:"alert_type"`
+	Severity     string    `json:"severity"`
+	RaisedAt     time.Time `json:"raised_at"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyTTL is how long a client may retry a request with the same Idempotency-Key and
+// get the stored response back (E-API-005).
+const IdempotencyTTL = 24 * time.Hour
+
+// IdempotencyRecord is the outcome of a request sent with an Idempotency-Key. Key is a hash
+// of the client's key, the principal and the route, so clients can not collide with each
+// other. Until Completed is set the original request is still running.
+type IdempotencyRecord struct {
+	Key string `json:"key"`
+	// RequestHash is the SHA-256 of the request body; a retry must send the same body.
+	RequestHash string `json:"request_hash"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	// Header holds the response headers that are replayed, such as Content-Type.
+	Header    map[string]string `json:"header,omitempty"`
+	Body      []byte            `json:"body,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// IdempotencyRepository persists idempotency records.
+type IdempotencyRepository interface {
+	// Create stores rec, or returns ErrConflict when a record with its key exists and has
+	// not expired at rec.CreatedAt. Create is atomic: of two concurrent requests with the
+	// same key exactly one succeeds.
+	Create(ctx context.Context, rec *IdempotencyRecord) error
+	// Get returns the record with the given key or ErrNotFound.
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Update replaces a record or returns ErrNotFound.
+	Update(ctx context.Context, rec *IdempotencyRecord) error
+	// Delete removes a record; deleting a missing one is not an error.
+	Delete(ctx context.Context, key string) error
+}
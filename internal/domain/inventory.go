@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// InventoryItem is the on-hand stock of one chemical product.
+type InventoryItem struct {
+	ProductID string    `json:"product_id" example:"liquid-chlorine-12.5"`
+	Unit      string    `json:"unit" example:"oz"`
+	OnHand    float64   `json:"on_hand" example:"512"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InventoryMovement changes on-hand stock. Quantity is negative for consumption. ID is the
+// idempotency key: applying the same movement twice has no further effect.
+type InventoryMovement struct {
+	ID         string
+	ProductID  string
+	Unit       string
+	Quantity   float64
+	Reason     string
+	OccurredAt time.Time
+}
+
+// InventoryRepository stores stock levels.
+type InventoryRepository interface {
+	Get(ctx context.Context, productID string) (*InventoryItem, error)
+	// ApplyMovement adjusts stock and reports whether the movement was new.
+	ApplyMovement(ctx context.Context, m InventoryMovement) (bool, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MFAChallenge is a login that passed the password check and still needs a second factor.
+// The client holds an opaque token; only its SHA-256 is stored. Attempts counts the codes
+// checked so a 6-digit code can not be brute forced within one challenge.
+type MFAChallenge struct {
+	ID        string    `json:"id"`
+	TokenHash string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Attempts  int       `json:"attempts"`
+}
+
+// MFAChallengeRepository persists pending MFA challenges.
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, c *MFAChallenge) error
+	// GetByHash returns the challenge with the given token hash or ErrNotFound.
+	GetByHash(ctx context.Context, hash string) (*MFAChallenge, error)
+	// Modify applies fn to the stored challenge and saves the result unless fn returns an
+	// error, which Modify returns. Concurrent Modify calls on a challenge run one at a time.
+	// It returns the saved challenge or ErrNotFound.
+	Modify(ctx context.Context, id string, fn func(*MFAChallenge) error) (*MFAChallenge, error)
+	// Delete removes a challenge; deleting a missing one is not an error.
+	Delete(ctx context.Context, id string) error
+}
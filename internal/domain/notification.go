@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// Notification is a message for staff, independent of the channel that carries it.
+type Notification struct {
+	Subject  string
+	Body     string
+	Severity string
+}
+
+// Notifier delivers notifications (email, SMS, push, ...).
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMessage is a domain event persisted in the same transaction as the state change that
+// produced it. A relay later hands it to every sink; DeliveredTo records which sinks have
+// accepted it so a partially relayed message is never sent twice to the same sink.
+type OutboxMessage struct {
+	ID          string     `json:"id"`
+	Event       Event      `json:"event"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredTo []string   `json:"delivered_to"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// NextAttemptAt is when a message that a sink refused is retried; nil means now.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	// DeadLetteredAt is set when the relay gives up on the message. It stays in the outbox,
+	// with LastError, for an operator to inspect.
+	DeadLetteredAt *time.Time `json:"dead_lettered_at,omitempty"`
+	// RequestID and TraceID correlate the message with the request that produced it. They
+	// are restored into the relay's context but are not part of the partner-facing envelope.
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// OutboxRepository persists outbox messages.
+type OutboxRepository interface {
+	// Add stores a new message; it joins the caller's transaction when one is active, and
+	// ListUnpublished does not return it until that transaction commits.
+	Add(ctx context.Context, msg *OutboxMessage) error
+	// ListUnpublished returns messages without PublishedAt, oldest first.
+	ListUnpublished(ctx context.Context, limit int) ([]OutboxMessage, error)
+	// ListDue returns the unpublished messages the relay should try at now, oldest first:
+	// those not dead-lettered whose NextAttemptAt is unset or not after now.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]OutboxMessage, error)
+	// Update replaces an existing message (relay progress, errors, PublishedAt).
+	Update(ctx context.Context, msg *OutboxMessage) error
+}
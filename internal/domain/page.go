@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// PageRequest selects one page of a list (E-API-004). Lists are keyset paginated: items are
+// ordered by the sort key Sort names and then by id, and a page starts after the position of
+// the previous page's last item, so rows inserted meanwhile neither repeat nor skip items.
+type PageRequest struct {
+	// Sort names the sort key; empty means the list's default order.
+	Sort string
+	Desc bool
+	// After is the position of the last item already seen; nil for the first page.
+	After *PagePosition
+	// Limit caps the number of items; 0 means no limit.
+	Limit int
+}
+
+// OrDefault returns r with the list's default order when r names none.
+func (r PageRequest) OrDefault(sort string, desc bool) PageRequest {
+	if r.Sort == "" {
+		r.Sort, r.Desc = sort, desc
+	}
+	return r
+}
+
+// PagePosition is an item's place in a sorted list.
+type PagePosition struct {
+	Key string `json:"k"`
+	ID  string `json:"i"`
+}
+
+// Pageable is implemented by list items. PagePosition returns the item's sort key for the
+// named sort and its id; keys compare as strings, see SortTime and SortInt.
+type Pageable interface {
+	PagePosition(sort string) PagePosition
+}
+
+// Paginate orders items as r asks and returns the page after r.After. Repositories without
+// a query language use it; others translate PageRequest into their own.
+func Paginate[T Pageable](items []T, r PageRequest) []T {
+	compare := func(a, b PagePosition) int {
+		c := cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.ID, b.ID))
+		if r.Desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortStableFunc(items, func(a, b T) int {
+		return compare(a.PagePosition(r.Sort), b.PagePosition(r.Sort))
+	})
+	if r.After != nil {
+		start, _ := slices.BinarySearchFunc(items, *r.After, func(item T, after PagePosition) int {
+			if compare(item.PagePosition(r.Sort), after) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		items = items[start:]
+	}
+	if r.Limit > 0 && len(items) > r.Limit {
+		items = items[:r.Limit]
+	}
+	return items
+}
+
+// SortTime formats t as a sort key that orders like time.
+func SortTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z")
+}
+
+// SortInt formats a non-negative n as a sort key that orders like the number.
+func SortInt(n int64) string {
+	return fmt.Sprintf("%020d", n)
+}
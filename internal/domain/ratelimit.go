@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit is a token bucket budget: up to Burst requests at once, refilled at
+// Burst requests per Period. Name tells budgets apart, so one principal has a separate
+// bucket per budget.
+type RateLimit struct {
+	Name   string
+	Burst  int
+	Period time.Duration
+}
+
+// RateLimitResult is the state of a bucket after a request took, or failed to take, a token.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token is available; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore holds token buckets. Take must refill and take from the bucket atomically;
+// a store shared by several replicas (e.g. Redis with a script) enforces one budget across
+// all of them.
+type RateLimitStore interface {
+	// Take takes one token for key from a bucket shaped by limit at time now.
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
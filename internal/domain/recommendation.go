@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DoseRecommendationInput is what the dosing engine bases a recommendation on.
+type DoseRecommendationInput struct {
+	JobID             string             `json:"job_id" example:"job-123"`
+	PoolVolumeGallons float64            `json:"pool_volume_gallons" example:"15000"`
+	Readings          map[string]float64 `json:"readings"`
+	Targets           map[string]float64 `json:"targets,omitempty"`
+}
+
+// RecommendedDose is one product the engine recommends adding.
+type RecommendedDose struct {
+	Parameter string  `json:"parameter" example:"FC"`
+	ProductID string  `json:"product_id" example:"liquid-chlorine-12.5"`
+	Unit      string  `json:"unit" example:"oz"`
+	Amount    float64 `json:"amount" example:"32"`
+}
+
+// DosingEngine computes dose recommendations. Version identifies the algorithm and is
+// signed with every recommendation it produces.
+type DosingEngine interface {
+	Version() string
+	Recommend(ctx context.Context, in DoseRecommendationInput) ([]RecommendedDose, error)
+}
+
+// RecommendationSignatureV1 is the first canonical encoding of a signed recommendation.
+// Documents store the version they were signed under, so a field added to
+// DoseRecommendation later leaves existing signatures valid; signing it takes a new version.
+const RecommendationSignatureV1 = 1
+
+// DoseRecommendation is a signed engine result. The signature covers the id, inputs, engine
+// version, outputs and creation time, so any change to them after the fact is detectable.
+type DoseRecommendation struct {
+	ID               string                  `json:"id" example:"7a1e2b3c-4d5e-6f70-8192-a3b4c5d6e7f8"`
+	Inputs           DoseRecommendationInput `json:"inputs"`
+	EngineVersion    string                  `json:"engine_version" example:"2025.10.1"`
+	Outputs          []RecommendedDose       `json:"outputs"`
+	CreatedAt        time.Time               `json:"created_at"`
+	SignatureVersion int                     `json:"signature_version" example:"1"`
+	KeyID            string                  `json:"key_id" example:"3f2a9c1b7d4e5f60"`
+	Signature        []byte                  `json:"signature" swaggertype:"string" format:"base64"`
+}
+
+// SigningPayload is the canonical byte string that is signed, in the encoding named by
+// r.SignatureVersion. Like ChainContent it writes a fixed list of length-prefixed fields,
+// maps in key order.
+func (r DoseRecommendation) SigningPayload() ([]byte, error) {
+	if r.SignatureVersion != RecommendationSignatureV1 {
+		return nil, fmt.Errorf("unknown signature version %d", r.SignatureVersion)
+	}
+	enc := newChainEncoder(RecommendationSignatureV1)
+	enc.string("dose_recommendation")
+	enc.string(r.ID)
+	enc.string(r.Inputs.JobID)
+	enc.float(&r.Inputs.PoolVolumeGallons)
+	enc.floatMap(r.Inputs.Readings)
+	enc.floatMap(r.Inputs.Targets)
+	enc.string(r.EngineVersion)
+	enc.int(int64(len(r.Outputs)))
+	for _, o := range r.Outputs {
+		enc.string(o.Parameter)
+		enc.string(o.ProductID)
+		enc.string(o.Unit)
+		enc.float(&o.Amount)
+	}
+	enc.time(r.CreatedAt)
+	return enc.encoded()
+}
+
+// DoseRecommendationRepository persists signed recommendations.
+type DoseRecommendationRepository interface {
+	Create(ctx context.Context, r *DoseRecommendation) error
+	Get(ctx context.Context, id string) (*DoseRecommendation, error)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCredentials is returned when a login or refresh is refused. It deliberately does
+// not say whether the user, password or token was the problem.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Principal is an authenticated identity and the roles it currently holds.
+type Principal struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
+// Authenticator checks credentials and resolves the current roles of a user.
+type Authenticator interface {
+	// Authenticate returns the principal for valid credentials, or ErrInvalidCredentials.
+	Authenticate(ctx context.Context, username, password string) (*Principal, error)
+	// Principal returns the current principal for userID, or ErrInvalidCredentials when the
+	// user no longer exists or may not sign in. It is consulted on every refresh so role
+	// changes and deactivation take effect without a new login.
+	Principal(ctx context.Context, userID string) (*Principal, error)
+}
+
+// RefreshToken is a stored refresh token. Only the SHA-256 of the token is kept. Every token
+// minted by rotating a session's refresh token shares the FamilyID of the login that started
+// it, so a replayed token can revoke the whole session.
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	FamilyID  string     `json:"family_id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RefreshTokenRepository persists refresh tokens.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t *RefreshToken) error
+	// GetByHash returns the token with the given hash or ErrNotFound.
+	GetByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	// MarkUsed sets UsedAt on an unused token and reports whether it did. It returns false
+	// when the token was already used, which is how a replay is detected.
+	MarkUsed(ctx context.Context, id string, at time.Time) (bool, error)
+	// RevokeFamily revokes every unrevoked token in a family.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// RevokeUser revokes every unrevoked token of a user and returns how many families
+	// (sessions) were affected.
+	RevokeUser(ctx context.Context, userID string, at time.Time) (int, error)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SSOLoginState is a single sign-on login between the redirect to the identity provider and
+// its callback. Only a hash of the state parameter is stored; Nonce binds the ID token to
+// this login and CodeVerifier is the PKCE secret for the code exchange.
+type SSOLoginState struct {
+	StateHash    string    `json:"-"`
+	Nonce        string    `json:"-"`
+	CodeVerifier string    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SSOLoginStateRepository persists pending single sign-on logins.
+type SSOLoginStateRepository interface {
+	Create(ctx context.Context, s *SSOLoginState) error
+	// Take returns and removes the login with the given state hash, or ErrNotFound, so a
+	// callback can be completed only once.
+	Take(ctx context.Context, stateHash string) (*SSOLoginState, error)
+}
+
+// SSOHandoff is a completed single sign-on login waiting for the front end to collect its
+// session. The browser carries only a one-time code; its hash is stored, and the session
+// tokens are issued when the code is redeemed.
+type SSOHandoff struct {
+	CodeHash  string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	Roles     []string  `json:"roles"`
+	Issuer    string    `json:"issuer"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SSOHandoffRepository persists completed logins until they are redeemed.
+type SSOHandoffRepository interface {
+	Create(ctx context.Context, h *SSOHandoff) error
+	// Take returns and removes the handoff with the given code hash, or ErrNotFound, so a
+	// code can be redeemed only once.
+	Take(ctx context.Context, codeHash string) (*SSOHandoff, error)
+}
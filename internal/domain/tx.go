@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// TxManager runs fn inside a transaction. Repositories called with the ctx passed to fn
+// join that transaction; if fn returns an error every write made through it is rolled back.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
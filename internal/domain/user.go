@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// User is an account that signs in with a username and password, or through single sign-on.
+// PasswordHash is an Argon2id PHC string (E-SEC-005); the plain password is never stored.
+type User struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	// ExternalIssuer and ExternalSubject identify an account provisioned by an OpenID
+	// Connect provider. Such accounts have no password.
+	ExternalIssuer  string   `json:"external_issuer,omitempty"`
+	ExternalSubject string   `json:"external_subject,omitempty"`
+	Roles           []string `json:"roles"`
+	Disabled        bool     `json:"disabled"`
+	// FailedLogins counts consecutive failed logins; it resets on success and when the
+	// account is locked.
+	FailedLogins int        `json:"failed_logins"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	// TOTPSecret is the base32 secret of a confirmed authenticator; empty means no MFA.
+	// TOTPPendingSecret holds a secret during enrollment until its first code is verified.
+	TOTPSecret        string `json:"totp_secret,omitempty"`
+	TOTPPendingSecret string `json:"totp_pending_secret,omitempty"`
+	// TOTPLastStep is the time step of the last accepted code, so a code can not be replayed.
+	TOTPLastStep int64 `json:"totp_last_step,omitempty"`
+	// RecoveryCodeHashes are SHA-256 hashes of the unused single-use recovery codes.
+	RecoveryCodeHashes []string  `json:"recovery_code_hashes,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// External reports whether the account signs in through single sign-on.
+func (u *User) External() bool { return u.ExternalIssuer != "" }
+
+// MFAEnrolled reports whether the user has a confirmed second factor.
+func (u *User) MFAEnrolled() bool { return u.TOTPSecret != "" }
+
+// Locked reports whether the account is locked out at now.
+func (u *User) Locked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// Principal returns the identity a session for u carries.
+func (u *User) Principal() *Principal {
+	return &Principal{UserID: u.ID, Roles: append([]string(nil), u.Roles...)}
+}
+
+// UserRepository persists users. Usernames are unique, compared case-insensitively.
+type UserRepository interface {
+	// Create stores a new user or returns ErrConflict when the id or username is taken.
+	Create(ctx context.Context, u *User) error
+	// Get returns the user with the given id or ErrNotFound.
+	Get(ctx context.Context, id string) (*User, error)
+	// GetByUsername returns the user with the given username or ErrNotFound.
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	// GetByExternalID returns the user provisioned for issuer and subject or ErrNotFound.
+	GetByExternalID(ctx context.Context, issuer, subject string) (*User, error)
+	// Update replaces an existing user or returns ErrNotFound.
+	Update(ctx context.Context, u *User) error
+	// Modify applies fn to the stored user with the given id and saves the result unless fn
+	// returns an error, which Modify returns. Concurrent Modify calls on a user run one at a
+	// time, so a read-modify-write such as counting a failed login cannot lose an update or
+	// overwrite fields fn does not touch. It returns the saved user or ErrNotFound.
+	Modify(ctx context.Context, id string, fn func(*User) error) (*User, error)
+	// List returns every user ordered by username.
+	List(ctx context.Context) ([]User, error)
+}
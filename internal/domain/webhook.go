@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription is a partner endpoint registered to receive outbound events (E-API-001).
+// After its secret is rotated, PreviousSecret stays set until PreviousSecretExpiresAt and
+// deliveries are signed with both so receivers can switch over without downtime.
+type WebhookSubscription struct {
+	ID                      string     `json:"id" example:"5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"`
+	TargetURL               string     `json:"target_url" example:"https://partner.example.com/hooks/pool"`
+	Secret                  string     `json:"-"`
+	PreviousSecret          string     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+	EventTypes              []string   `json:"event_types" example:"JobCompleted,DoseRecorded"`
+	Active                  bool       `json:"active" example:"true"`
+	CreatedAt               time.Time  `json:"created_at"`
+}
+
+// SigningSecrets returns the secrets a delivery at now is signed with, newest first.
+func (s WebhookSubscription) SigningSecrets(now time.Time) []string {
+	if s.PreviousSecret == "" || (s.PreviousSecretExpiresAt != nil && !now.Before(*s.PreviousSecretExpiresAt)) {
+		return []string{s.Secret}
+	}
+	return []string{s.Secret, s.PreviousSecret}
+}
+
+// Matches reports whether the subscription is active and listens for the given event type.
+// An empty EventTypes list subscribes to every event.
+func (s WebhookSubscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the lifecycle state of a single webhook delivery (E-API-002).
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is a delivery that has not been attempted yet.
+	WebhookDeliveryPending WebhookDeliveryStatus = "PENDING"
+	// WebhookDeliveryDelivered is a delivery the receiver acknowledged with a 2xx response.
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// WebhookDeliveryFailed is a delivery whose last attempt failed and that is scheduled for retry.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "FAILED"
+	// WebhookDeliveryDeadLettered is a delivery that exhausted its retries and will not be attempted again.
+	WebhookDeliveryDeadLettered WebhookDeliveryStatus = "DEAD_LETTERED"
+)
+
+// Retryable reports whether the dispatcher should still attempt deliveries in this state.
+func (s WebhookDeliveryStatus) Retryable() bool {
+	return s == WebhookDeliveryPending || s == WebhookDeliveryFailed
+}
+
+// WebhookDeliveryAttempt records the outcome of one HTTP attempt for a delivery.
+type WebhookDeliveryAttempt struct {
+	Number      int       `json:"number" example:"1"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty" example:"502"`
+	Error       string    `json:"error,omitempty" example:"unexpected status 502"`
+	DurationMS  int64     `json:"duration_ms" example:"120"`
+}
+
+// WebhookDelivery is one event sent to one subscription, persisted with its retry state.
+type WebhookDelivery struct {
+	ID             string                   `json:"id" example:"0b9d7c1e-3f5d-4e0b-9a35-6a8a1c1f2d44"`
+	SubscriptionID string                   `json:"subscription_id" example:"5f0c6c7e-9a3c-4a53-8d2a-2f1f4f7b9e10"`
+	EventID        string                   `json:"event_id" example:"evt_123"`
+	EventType      string                   `json:"event_type" example:"JobCompleted"`
+	Payload        json.RawMessage          `json:"payload" swaggertype:"object"`
+	Status         WebhookDeliveryStatus    `json:"status" example:"PENDING"`
+	AttemptCount   int                      `json:"attempt_count" example:"0"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastError      string                   `json:"last_error,omitempty"`
+	RedeliveryOf   string                   `json:"redelivery_of,omitempty"`
+	Attempts       []WebhookDeliveryAttempt `json:"attempts"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	DeadLetteredAt *time.Time               `json:"dead_lettered_at,omitempty"`
+}
+
+// Webhook delivery sort keys. The default order is newest first.
+const (
+	WebhookDeliverySortCreatedAt     = "created_at"
+	WebhookDeliverySortNextAttemptAt = "next_attempt_at"
+)
+
+// PagePosition implements Pageable.
+func (d WebhookDelivery) PagePosition(sort string) PagePosition {
+	if sort == WebhookDeliverySortNextAttemptAt {
+		return PagePosition{Key: SortTime(d.NextAttemptAt), ID: d.ID}
+	}
+	return PagePosition{Key: SortTime(d.CreatedAt), ID: d.ID}
+}
+
+// WebhookDeliveryFilter narrows a delivery history listing. Zero values mean "any".
+type WebhookDeliveryFilter struct {
+	SubscriptionID string
+	Status         WebhookDeliveryStatus
+	PageRequest
+}
+
+// WebhookSubscriptionRepository persists webhook subscriptions.
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, sub *WebhookSubscription) error
+	Get(ctx context.Context, id string) (*WebhookSubscription, error)
+	// List returns every subscription, newest first.
+	List(ctx context.Context) ([]WebhookSubscription, error)
+	// Modify applies fn to the subscription atomically and stores the result, or returns
+	// ErrNotFound. An error from fn leaves the subscription unchanged.
+	Modify(ctx context.Context, id string, fn func(*WebhookSubscription) error) (*WebhookSubscription, error)
+	// Delete removes a subscription, or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+	ListForEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error)
+}
+
+// WebhookDeliveryRepository persists webhook deliveries and their attempt history.
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, d *WebhookDelivery) error
+	Get(ctx context.Context, id string) (*WebhookDelivery, error)
+	Update(ctx context.Context, d *WebhookDelivery) error
+	// List returns a page of the deliveries matching the filter, newest first by default.
+	List(ctx context.Context, filter WebhookDeliveryFilter) ([]WebhookDelivery, error)
+	// ListDue returns retryable deliveries whose next attempt is at or before now, oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+}
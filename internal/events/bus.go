@@ -0,0 +1,343 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultQueueSize is the per-subscriber buffer used when SubscribeOptions.QueueSize is zero.
+const DefaultQueueSize = 256
+
+// ErrBusClosed is returned by Subscribe and by a sink's HandleEvent after Close.
+var ErrBusClosed = errors.New("event bus closed")
+
+// ErrDuplicateSubscriber is returned when a subscriber name is registered twice.
+var ErrDuplicateSubscriber = errors.New("duplicate subscriber name")
+
+// OverflowPolicy decides how a subscriber's sink hands events to it, and so whether the
+// subscriber may miss events.
+type OverflowPolicy int
+
+const (
+	// DropNewest is best effort: the sink queues the message and returns at once, so a slow
+	// handler never holds up the relay. A full queue drops the message and counts it as
+	// dropped; handler failures are logged and counted but not retried.
+	DropNewest OverflowPolicy = iota
+	// Block acknowledges an event only once it is handled: the sink waits for queue space
+	// and for the handler, and returns its error so the relay retries. Use it for
+	// subscribers that must not miss events, such as audit.
+	Block
+)
+
+// Message is a typed event delivered to subscribers. Payload is one of the domain event
+// structs (for example domain.DoseRecordedV1); ID is the envelope id, so handlers can
+// deduplicate the at-least-once deliveries of the outbox relay.
+type Message[T any] struct {
+	ID         string
+	Type       string
+	OccurredAt time.Time
+	Payload    T
+}
+
+// Handler processes one message. A returned error or a panic is logged and counted; the
+// subscriber keeps running either way.
+type Handler[T any] func(ctx context.Context, msg Message[T]) error
+
+// SubscribeOptions tunes a single subscriber.
+type SubscribeOptions struct {
+	QueueSize int
+	Overflow  OverflowPolicy
+}
+
+// Bus is an in-process publish/subscribe bus fed by the outbox relay through Sinks. Each
+// subscriber owns a bounded queue and a goroutine, so a panicking subscriber cannot take its
+// peers down. Subscriptions are keyed by the Go type of the payload.
+type Bus struct {
+	logger  *zap.Logger
+	metrics *busMetrics
+
+	mu     sync.RWMutex
+	closed bool
+	names  map[string]bool
+	topics map[reflect.Type][]*subscriber
+	subs   []*subscriber
+	wg     sync.WaitGroup
+}
+
+type queued struct {
+	ctx context.Context
+	msg any
+	// done, when set, receives the handler's outcome.
+	done chan<- error
+}
+
+type subscriber struct {
+	name     string
+	topic    reflect.Type
+	queue    chan queued
+	overflow OverflowPolicy
+	handle   func(ctx context.Context, msg any) error
+}
+
+// NewBus creates a bus and registers its per-subscriber metrics with reg (may be nil).
+func NewBus(logger *zap.Logger, reg prometheus.Registerer) *Bus {
+	return &Bus{
+		logger:  logger,
+		metrics: newBusMetrics(reg),
+		names:   make(map[string]bool),
+		topics:  make(map[reflect.Type][]*subscriber),
+	}
+}
+
+// Subscribe registers h for messages whose payload type is T and starts its goroutine.
+// name labels logs and metrics and must be unique on the bus.
+func Subscribe[T any](b *Bus, name string, h Handler[T], opts SubscribeOptions) error {
+	topic := reflect.TypeFor[T]()
+	size := opts.QueueSize
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	sub := &subscriber{
+		name:     name,
+		topic:    topic,
+		queue:    make(chan queued, size),
+		overflow: opts.Overflow,
+		handle: func(ctx context.Context, msg any) error {
+			return h(ctx, msg.(Message[T]))
+		},
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+	if b.names[name] {
+		return fmt.Errorf("%w: %s", ErrDuplicateSubscriber, name)
+	}
+	b.names[name] = true
+	b.topics[topic] = append(b.topics[topic], sub)
+	b.subs = append(b.subs, sub)
+	b.wg.Add(1)
+	go b.run(sub)
+	return nil
+}
+
+// enqueue queues item for sub, applying sub's overflow policy when the queue is full.
+func (b *Bus) enqueue(ctx context.Context, sub *subscriber, item queued) error {
+	select {
+	case sub.queue <- item:
+		b.metrics.queueDepth.WithLabelValues(sub.name).Set(float64(len(sub.queue)))
+		return nil
+	default:
+	}
+	if sub.overflow == Block {
+		select {
+		case sub.queue <- item:
+			b.metrics.queueDepth.WithLabelValues(sub.name).Set(float64(len(sub.queue)))
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("deliver to %s: %w", sub.name, ctx.Err())
+		}
+	}
+	b.metrics.handled.WithLabelValues(sub.name, outcomeDropped).Inc()
+	requestctx.Enrich(ctx, b.logger).Warn("event bus queue full; message dropped", zap.String("subscriber", sub.name), zap.Int("queue_size", cap(sub.queue)))
+	return nil
+}
+
+// run drains one subscriber's queue until Close.
+func (b *Bus) run(sub *subscriber) {
+	defer b.wg.Done()
+	for item := range sub.queue {
+		b.metrics.queueDepth.WithLabelValues(sub.name).Set(float64(len(sub.queue)))
+		b.dispatch(sub, item)
+	}
+}
+
+// dispatch runs the handler for one message, converting a panic into a counted failure,
+// and reports the outcome to item.done if set.
+func (b *Bus) dispatch(sub *subscriber, item queued) {
+	start := time.Now()
+	outcome := outcomeOK
+	var err error
+	defer func() {
+		if p := recover(); p != nil {
+			outcome = outcomePanic
+			err = fmt.Errorf("subscriber %s panicked: %v", sub.name, p)
+			requestctx.Enrich(item.ctx, b.logger).Error("event subscriber panicked", zap.String("subscriber", sub.name), zap.Any("panic", p), zap.Stack("stack"))
+		}
+		b.metrics.duration.WithLabelValues(sub.name).Observe(time.Since(start).Seconds())
+		b.metrics.handled.WithLabelValues(sub.name, outcome).Inc()
+		if item.done != nil {
+			item.done <- err
+		}
+	}()
+	if err = sub.handle(item.ctx, item.msg); err != nil {
+		outcome = outcomeError
+		requestctx.Enrich(item.ctx, b.logger).Error("event subscriber failed", zap.String("subscriber", sub.name), zap.Error(err))
+	}
+}
+
+// Close stops accepting messages, lets every subscriber drain its queue and waits for them.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, subs := range b.topics {
+		for _, sub := range subs {
+			close(sub.queue)
+		}
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+// Sinks returns one outbox relay sink per subscriber, in the order they subscribed. The
+// relay keeps a separate cursor for each sink, so an event is acknowledged to a subscriber
+// only once its handler has finished, and a failing subscriber is retried on its own. Call
+// it after every Subscribe.
+func (b *Bus) Sinks() []*SubscriberSink {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	sinks := make([]*SubscriberSink, len(b.subs))
+	for i, sub := range b.subs {
+		sinks[i] = &SubscriberSink{bus: b, sub: sub}
+	}
+	return sinks
+}
+
+// SubscriberSink relays committed outbox events to one subscriber, so in-process
+// subscribers only ever see committed events.
+type SubscriberSink struct {
+	bus *Bus
+	sub *subscriber
+}
+
+// Name identifies the subscriber as an outbox relay sink.
+func (s *SubscriberSink) Name() string { return "bus:" + s.sub.name }
+
+// HandleEvent decodes a relayed envelope into its typed payload and queues it for the
+// subscriber. For a Block subscriber it waits for the handler and returns its error, or a
+// panic as an error, so the relay retries the event; for a DropNewest subscriber it returns
+// once the message is queued or dropped. Events of other types are accepted untouched.
+func (s *SubscriberSink) HandleEvent(ctx context.Context, ev domain.Event) error {
+	env, ok := envelopes[ev.Type]
+	if !ok || ev.SchemaVersion != 1 {
+		requestctx.Enrich(ctx, s.bus.logger).Debug("no typed payload for event; skipped", zap.String("event_type", ev.Type), zap.Int("schema_version", ev.SchemaVersion))
+		return nil
+	}
+	if env.topic != s.sub.topic {
+		return nil
+	}
+	msg, err := env.decode(ev)
+	if err != nil {
+		return err
+	}
+	return s.bus.deliver(ctx, s.sub, msg)
+}
+
+// deliver queues msg for sub under sub's overflow policy. For a Block subscriber it then
+// waits for the handler and returns its outcome. Handlers run with ctx's values but not its
+// cancellation.
+func (b *Bus) deliver(ctx context.Context, sub *subscriber, msg any) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("deliver to %s: %w", sub.name, err)
+	}
+	item := queued{ctx: context.WithoutCancel(ctx), msg: msg}
+	var done chan error
+	if sub.overflow == Block {
+		done = make(chan error, 1)
+		item.done = done
+	}
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	err := b.enqueue(ctx, sub, item)
+	b.mu.RUnlock()
+	if err != nil || done == nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("deliver to %s: %w", sub.name, ctx.Err())
+	}
+}
+
+// envelope decodes one envelope type into the Message of its v1 payload struct.
+type envelope struct {
+	topic  reflect.Type
+	decode func(domain.Event) (any, error)
+}
+
+// envelopes maps an envelope type to its v1 payload struct.
+var envelopes = map[string]envelope{
+	domain.EventJobCompleted: envelopeOf[domain.JobCompletedV1](),
+	domain.EventDoseRecorded: envelopeOf[domain.DoseRecordedV1](),
+	domain.EventInvoicePaid:  envelopeOf[domain.InvoicePaidV1](),
+	domain.EventAlertRaised:  envelopeOf[domain.AlertRaisedV1](),
+}
+
+func envelopeOf[T any]() envelope {
+	return envelope{
+		topic: reflect.TypeFor[T](),
+		decode: func(ev domain.Event) (any, error) {
+			var payload T
+			if err := json.Unmarshal(ev.Data, &payload); err != nil {
+				return nil, fmt.Errorf("decode %s %s: %w", ev.Type, ev.ID, err)
+			}
+			return Message[T]{ID: ev.ID, Type: ev.Type, OccurredAt: ev.OccurredAt, Payload: payload}, nil
+		},
+	}
+}
+
+const (
+	outcomeOK      = "ok"
+	outcomeError   = "error"
+	outcomePanic   = "panic"
+	outcomeDropped = "dropped"
+)
+
+type busMetrics struct {
+	handled    *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	queueDepth *prometheus.GaugeVec
+}
+
+func newBusMetrics(reg prometheus.Registerer) *busMetrics {
+	m := &busMetrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_bus_messages_total",
+			Help: "Messages seen by each event bus subscriber, by outcome (ok, error, panic, dropped).",
+		}, []string{"subscriber", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "event_bus_handler_duration_seconds",
+			Help:    "Time each event bus subscriber spent handling a message.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"subscriber"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "event_bus_queue_depth",
+			Help: "Messages waiting in each event bus subscriber's queue.",
+		}, []string{"subscriber"}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.handled, m.duration, m.queueDepth)
+	}
+	return m
+}
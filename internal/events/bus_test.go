@@ -0,0 +1,210 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type collector[T any] struct {
+	mu  sync.Mutex
+	got []Message[T]
+}
+
+func (c *collector[T]) handle(_ context.Context, msg Message[T]) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.got = append(c.got, msg)
+	return nil
+}
+
+func (c *collector[T]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.got)
+}
+
+// publish hands msg to every subscriber of T, one after another, as their sinks would.
+func publish[T any](ctx context.Context, b *Bus, msg Message[T]) error {
+	b.mu.RLock()
+	subs := b.topics[reflect.TypeFor[T]()]
+	b.mu.RUnlock()
+	for _, sub := range subs {
+		if err := b.deliver(ctx, sub, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func alertEvent(t *testing.T, id string) domain.Event {
+	t.Helper()
+	data, err := json.Marshal(domain.AlertRaisedV1{AlertID: id})
+	require.NoError(t, err)
+	return domain.Event{ID: "e-" + id, Type: domain.EventAlertRaised, SchemaVersion: 1, Data: data}
+}
+
+func TestBus_DeliversByPayloadType(t *testing.T) {
+	bus := NewBus(zap.NewNop(), nil)
+	doses, more, jobs := &collector[domain.DoseRecordedV1]{}, &collector[domain.DoseRecordedV1]{}, &collector[domain.JobCompletedV1]{}
+	require.NoError(t, Subscribe(bus, "doses", doses.handle, SubscribeOptions{}))
+	require.NoError(t, Subscribe(bus, "more-doses", more.handle, SubscribeOptions{}))
+	require.NoError(t, Subscribe(bus, "jobs", jobs.handle, SubscribeOptions{}))
+
+	require.NoError(t, publish(context.Background(), bus, Message[domain.DoseRecordedV1]{ID: "e1", Payload: domain.DoseRecordedV1{DoseEventID: "d1"}}))
+	bus.Close()
+
+	require.Equal(t, 1, doses.len())
+	assert.Equal(t, "d1", doses.got[0].Payload.DoseEventID)
+	assert.Equal(t, 1, more.len())
+	assert.Equal(t, 0, jobs.len())
+}
+
+func TestBus_PanicAndErrorAreIsolated(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	bus := NewBus(zap.NewNop(), reg)
+	calls := 0
+	require.NoError(t, Subscribe(bus, "flaky", func(_ context.Context, msg Message[string]) error {
+		calls++
+		switch msg.Payload {
+		case "panic":
+			panic("boom")
+		case "fail":
+			return errors.New("nope")
+		}
+		return nil
+	}, SubscribeOptions{}))
+	healthy := &collector[string]{}
+	require.NoError(t, Subscribe(bus, "healthy", healthy.handle, SubscribeOptions{}))
+
+	for _, p := range []string{"panic", "fail", "ok"} {
+		require.NoError(t, publish(context.Background(), bus, Message[string]{Payload: p}))
+	}
+	bus.Close()
+
+	assert.Equal(t, 3, calls, "subscriber keeps running after a panic")
+	assert.Equal(t, 3, healthy.len())
+	m := bus.metrics.handled
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.WithLabelValues("flaky", outcomePanic)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.WithLabelValues("flaky", outcomeError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.WithLabelValues("flaky", outcomeOK)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.WithLabelValues("healthy", outcomeOK)))
+}
+
+func TestBus_OverflowPolicies(t *testing.T) {
+	bus := NewBus(zap.NewNop(), nil)
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+	slow := func(context.Context, Message[domain.AlertRaisedV1]) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	require.NoError(t, Subscribe(bus, "dropper", slow, SubscribeOptions{QueueSize: 1}))
+	require.NoError(t, Subscribe(bus, "blocker", slow, SubscribeOptions{QueueSize: 1, Overflow: Block}))
+	sinks := bus.Sinks()
+	dropper, blocker := sinks[0], sinks[1]
+	ctx := context.Background()
+
+	require.NoError(t, dropper.HandleEvent(ctx, alertEvent(t, "a1")), "handed over without waiting for the handler")
+	<-started
+	require.NoError(t, dropper.HandleEvent(ctx, alertEvent(t, "a2"))) // fills the queue
+	require.NoError(t, dropper.HandleEvent(ctx, alertEvent(t, "a3")), "a full best-effort queue drops the message")
+	assert.Equal(t, 1.0, testutil.ToFloat64(bus.metrics.handled.WithLabelValues("dropper", outcomeDropped)))
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := blocker.HandleEvent(short, alertEvent(t, "a1"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a blocking subscriber is acknowledged only once handled")
+
+	close(release)
+	bus.Close()
+}
+
+func TestBus_SubscribeRules(t *testing.T) {
+	bus := NewBus(zap.NewNop(), nil)
+	noop := func(context.Context, Message[int]) error { return nil }
+	require.NoError(t, Subscribe(bus, "a", noop, SubscribeOptions{}))
+	assert.ErrorIs(t, Subscribe(bus, "a", noop, SubscribeOptions{}), ErrDuplicateSubscriber)
+	bus.Close()
+	assert.ErrorIs(t, Subscribe(bus, "b", noop, SubscribeOptions{}), ErrBusClosed)
+	assert.ErrorIs(t, publish(context.Background(), bus, Message[int]{}), ErrBusClosed)
+}
+
+func TestBus_SinkDecodesEnvelope(t *testing.T) {
+	bus := NewBus(zap.NewNop(), nil)
+	alerts := &collector[domain.AlertRaisedV1]{}
+	doses := &collector[domain.DoseRecordedV1]{}
+	require.NoError(t, Subscribe(bus, "alerts", alerts.handle, SubscribeOptions{Overflow: Block}))
+	require.NoError(t, Subscribe(bus, "doses", doses.handle, SubscribeOptions{Overflow: Block}))
+	sinks := bus.Sinks()
+	require.Len(t, sinks, 2)
+	assert.Equal(t, "bus:alerts", sinks[0].Name())
+	assert.Equal(t, "bus:doses", sinks[1].Name())
+
+	data, _ := json.Marshal(domain.AlertRaisedV1{AlertID: "a1", Severity: "CRITICAL"})
+	ev := domain.Event{ID: "e1", Type: domain.EventAlertRaised, SchemaVersion: 1, Data: data}
+	for _, sink := range sinks {
+		require.NoError(t, sink.HandleEvent(context.Background(), ev))
+	}
+	require.NoError(t, sinks[0].HandleEvent(context.Background(), domain.Event{ID: "e2", Type: "Unregistered", SchemaVersion: 1}))
+	assert.Error(t, sinks[0].HandleEvent(context.Background(), domain.Event{ID: "e3", Type: domain.EventAlertRaised, SchemaVersion: 1, Data: []byte("{")}))
+	require.NoError(t, sinks[1].HandleEvent(context.Background(), domain.Event{ID: "e4", Type: domain.EventAlertRaised, SchemaVersion: 1, Data: []byte("{")}),
+		"events for other subscribers are accepted untouched")
+
+	require.Equal(t, 1, alerts.len(), "the sink returned only after the handler ran")
+	assert.Equal(t, "e1", alerts.got[0].ID)
+	assert.Equal(t, "a1", alerts.got[0].Payload.AlertID)
+	assert.Zero(t, doses.len())
+	bus.Close()
+	assert.ErrorIs(t, sinks[0].HandleEvent(context.Background(), ev), ErrBusClosed)
+}
+
+func TestBus_SinkAcknowledgesOnlyAfterHandlerFinishes(t *testing.T) {
+	bus := NewBus(zap.NewNop(), nil)
+	release := make(chan struct{})
+	fail := true
+	require.NoError(t, Subscribe(bus, "slow", func(context.Context, Message[domain.AlertRaisedV1]) error {
+		<-release
+		if fail {
+			return errors.New("store down")
+		}
+		return nil
+	}, SubscribeOptions{Overflow: Block}))
+	require.NoError(t, Subscribe(bus, "panicky", func(context.Context, Message[domain.AlertRaisedV1]) error {
+		panic("boom")
+	}, SubscribeOptions{Overflow: Block}))
+	defer bus.Close()
+	sinks := bus.Sinks()
+	data, _ := json.Marshal(domain.AlertRaisedV1{AlertID: "a1"})
+	ev := domain.Event{ID: "e1", Type: domain.EventAlertRaised, SchemaVersion: 1, Data: data}
+
+	result := make(chan error, 1)
+	go func() { result <- sinks[0].HandleEvent(context.Background(), ev) }()
+	select {
+	case <-result:
+		t.Fatal("acknowledged while the handler was still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	assert.EqualError(t, <-result, "store down", "a failed handler is retried by the relay")
+	fail = false
+	assert.NoError(t, sinks[0].HandleEvent(context.Background(), ev))
+
+	assert.ErrorContains(t, sinks[1].HandleEvent(context.Background(), ev), "panicked")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, sinks[0].HandleEvent(ctx, ev), context.Canceled)
+}
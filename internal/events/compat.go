@@ -0,0 +1,182 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CheckCompatible compares the current revision of a schema against the revision that was
+// published to consumers and returns every breaking change it finds (empty means compatible).
+//
+// Per the CRS promise "stable event schema; additive changes non-breaking", the following are
+// allowed within a version: new optional properties, making an optional property required, new
+// enum values, tighter bounds, and documentation edits. Everything that can invalidate a
+// consumer's assumptions is breaking: removing or retyping a property, dropping it from
+// "required", changing its format, and loosening a constraint, i.e. removing an enum or enum
+// values, removing or changing a pattern, or removing or widening a length, item count or
+// numeric bound. Breaking changes need a new version file (vN+1).
+func CheckCompatible(published, current []byte) ([]string, error) {
+	var oldDoc, newDoc map[string]any
+	if err := json.Unmarshal(published, &oldDoc); err != nil {
+		return nil, fmt.Errorf("published schema: %w", err)
+	}
+	if err := json.Unmarshal(current, &newDoc); err != nil {
+		return nil, fmt.Errorf("current schema: %w", err)
+	}
+	var problems []string
+	compareNode("$", oldDoc, newDoc, &problems)
+	return problems, nil
+}
+
+func compareNode(at string, oldN, newN map[string]any, problems *[]string) {
+	report := func(format string, args ...any) {
+		*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	if o, n := typeSet(oldN["type"]), typeSet(newN["type"]); !equalSets(o, n) {
+		report("type changed from %v to %v", sortedKeys(o), sortedKeys(n))
+	}
+	if o, n := oldN["format"], newN["format"]; o != nil && o != n {
+		report("format changed from %v to %v", o, n)
+	}
+	if oldEnum, ok := oldN["enum"].([]any); ok {
+		newEnum := enumSet(newN["enum"])
+		if newEnum == nil {
+			report("enum constraint removed")
+		} else {
+			for _, v := range oldEnum {
+				if !newEnum[fmt.Sprint(v)] {
+					report("enum value %v removed", v)
+				}
+			}
+		}
+	}
+
+	if o, ok := oldN["pattern"].(string); ok {
+		if n, ok := newN["pattern"].(string); !ok {
+			report("pattern constraint removed")
+		} else if n != o {
+			report("pattern changed from %q to %q", o, n)
+		}
+	}
+	for _, b := range bounds {
+		o, ok := oldN[b.keyword].(float64)
+		if !ok {
+			continue
+		}
+		n, ok := newN[b.keyword].(float64)
+		switch {
+		case !ok:
+			report("%s constraint removed", b.keyword)
+		case b.upper && n > o:
+			report("%s raised from %v to %v", b.keyword, o, n)
+		case !b.upper && n < o:
+			report("%s lowered from %v to %v", b.keyword, o, n)
+		}
+	}
+
+	newRequired := stringSet(newN["required"])
+	for _, r := range stringList(oldN["required"]) {
+		if !newRequired[r] {
+			report("property %q is no longer required", r)
+		}
+	}
+
+	oldProps, _ := oldN["properties"].(map[string]any)
+	newProps, _ := newN["properties"].(map[string]any)
+	for _, name := range sortedKeys(oldProps) {
+		np, ok := newProps[name].(map[string]any)
+		if !ok {
+			report("property %q removed", name)
+			continue
+		}
+		if op, ok := oldProps[name].(map[string]any); ok {
+			compareNode(at+"."+name, op, np, problems)
+		}
+	}
+
+	if oi, ok := oldN["items"].(map[string]any); ok {
+		if ni, ok := newN["items"].(map[string]any); ok {
+			compareNode(at+"[]", oi, ni, problems)
+		} else {
+			report("items schema removed")
+		}
+	}
+}
+
+// bounds are the numeric constraints a schema may tighten but not loosen: an upper bound may
+// only go down and a lower bound only up.
+var bounds = []struct {
+	keyword string
+	upper   bool
+}{
+	{"maxLength", true},
+	{"minLength", false},
+	{"maxItems", true},
+	{"minItems", false},
+	{"maximum", true},
+	{"minimum", false},
+}
+
+func typeSet(v any) map[string]bool {
+	switch t := v.(type) {
+	case string:
+		return map[string]bool{t: true}
+	case []any:
+		return stringSet(t)
+	}
+	return map[string]bool{}
+}
+
+func enumSet(v any) map[string]bool {
+	vals, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make(map[string]bool, len(vals))
+	for _, e := range vals {
+		out[fmt.Sprint(e)] = true
+	}
+	return out
+}
+
+func stringList(v any) []string {
+	vals, _ := v.([]any)
+	out := make([]string, 0, len(vals))
+	for _, e := range vals {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func stringSet(v any) map[string]bool {
+	out := make(map[string]bool)
+	for _, s := range stringList(v) {
+		out[s] = true
+	}
+	return out
+}
+
+func equalSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
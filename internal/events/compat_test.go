@@ -0,0 +1,105 @@
+package events
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update refreshes testdata/published from schemas/ when a release ships new schema revisions:
+//
+//	go test ./internal/events -run TestPublishedSchemas -update
+var update = flag.Bool("update", false, "refresh published schema snapshots")
+
+const baseSchema = `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "string"},
+    "code": {"type": "string", "pattern": "^[A-Z]+$", "minLength": 2, "maxLength": 8},
+    "severity": {"type": "string", "enum": ["INFO", "CRITICAL"]},
+    "at": {"type": "string", "format": "date-time"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["id", "at"]
+}`
+
+func TestCheckCompatible(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  string
+		breaking string // substring of the expected problem; empty means compatible
+	}{
+		{"identical", baseSchema, ""},
+		{"new optional property", strings.Replace(baseSchema, `"id": {"type": "string"},`, `"id": {"type": "string"}, "note": {"type": "string"},`, 1), ""},
+		{"optional becomes required", strings.Replace(baseSchema, `["id", "at"]`, `["id", "at", "severity"]`, 1), ""},
+		{"enum value added", strings.Replace(baseSchema, `["INFO", "CRITICAL"]`, `["INFO", "WARNING", "CRITICAL"]`, 1), ""},
+		{"property removed", strings.Replace(baseSchema, `"id": {"type": "string"},`, ``, 1), `property "id" removed`},
+		{"type changed", strings.Replace(baseSchema, `"id": {"type": "string"}`, `"id": {"type": "integer"}`, 1), "$.id: type changed"},
+		{"no longer required", strings.Replace(baseSchema, `["id", "at"]`, `["id"]`, 1), `"at" is no longer required`},
+		{"enum value removed", strings.Replace(baseSchema, `["INFO", "CRITICAL"]`, `["INFO"]`, 1), "enum value CRITICAL removed"},
+		{"format changed", strings.Replace(baseSchema, `"date-time"`, `"date"`, 1), "format changed"},
+		{"length bounds tightened", strings.Replace(baseSchema, `"minLength": 2, "maxLength": 8`, `"minLength": 3, "maxLength": 6`, 1), ""},
+		{"enum removed", strings.Replace(baseSchema, `, "enum": ["INFO", "CRITICAL"]`, ``, 1), "$.severity: enum constraint removed"},
+		{"pattern removed", strings.Replace(baseSchema, `"pattern": "^[A-Z]+$", `, ``, 1), "$.code: pattern constraint removed"},
+		{"pattern changed", strings.Replace(baseSchema, `"^[A-Z]+$"`, `"^[A-Z0-9]+$"`, 1), "pattern changed"},
+		{"maxLength raised", strings.Replace(baseSchema, `"maxLength": 8`, `"maxLength": 16`, 1), "$.code: maxLength raised from 8 to 16"},
+		{"maxLength removed", strings.Replace(baseSchema, `, "maxLength": 8`, ``, 1), "maxLength constraint removed"},
+		{"minLength lowered", strings.Replace(baseSchema, `"minLength": 2`, `"minLength": 1`, 1), "minLength lowered from 2 to 1"},
+		{"array item type changed", strings.Replace(baseSchema, `"items": {"type": "string"}`, `"items": {"type": "number"}`, 1), "$.tags[]: type changed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, err := CheckCompatible([]byte(baseSchema), []byte(tc.current))
+			require.NoError(t, err)
+			if tc.breaking == "" {
+				assert.Empty(t, problems)
+				return
+			}
+			require.NotEmpty(t, problems)
+			assert.Contains(t, strings.Join(problems, "\n"), tc.breaking)
+		})
+	}
+}
+
+// TestPublishedSchemasStayCompatible is the CI gate for event contracts: every schema that has
+// been published (snapshotted in testdata/published) must still exist and must only have
+// changed additively. A breaking change belongs in a new <Type>.v<N+1>.json file.
+func TestPublishedSchemasStayCompatible(t *testing.T) {
+	const publishedDir = "testdata/published"
+	if *update {
+		current, err := filepath.Glob("schemas/*.json")
+		require.NoError(t, err)
+		for _, f := range current {
+			raw, err := os.ReadFile(f)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(publishedDir, filepath.Base(f)), raw, 0o644))
+		}
+	}
+
+	published, err := filepath.Glob(filepath.Join(publishedDir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, published, "no published snapshots found")
+
+	for _, p := range published {
+		name := filepath.Base(p)
+		t.Run(name, func(t *testing.T) {
+			old, err := os.ReadFile(p)
+			require.NoError(t, err)
+			cur, err := os.ReadFile(filepath.Join("schemas", name))
+			if os.IsNotExist(err) {
+				t.Fatalf("published schema %s was deleted; published versions must keep being served", name)
+			}
+			require.NoError(t, err)
+			problems, err := CheckCompatible(old, cur)
+			require.NoError(t, err)
+			if len(problems) > 0 {
+				t.Fatalf("breaking change(s) to published schema %s; add a new version instead:\n  %s", name, strings.Join(problems, "\n  "))
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package events holds the versioned JSON Schemas for outbound domain events and
+// validates payloads against them before they leave the service.
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// schemaFileRe matches "<EventType>.v<N>.json".
+var schemaFileRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)\.v([1-9][0-9]*)\.json$`)
+
+// ErrUnknownSchema is returned when no schema is registered for an event type/version.
+var ErrUnknownSchema = errors.New("unknown event schema")
+
+// ValidationError reports a payload that does not conform to its schema.
+type ValidationError struct {
+	Type    string
+	Version int
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("event %s v%d failed schema validation: %v", e.Type, e.Version, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Schema is one published version of an event schema.
+type Schema struct {
+	Type    string          `json:"type" example:"DoseRecorded"`
+	Version int             `json:"version" example:"1"`
+	Raw     json.RawMessage `json:"schema" swaggertype:"object"`
+
+	compiled *jsonschema.Schema
+}
+
+// Registry resolves and validates event schemas.
+type Registry struct {
+	schemas map[string]map[int]*Schema
+}
+
+// NewRegistry loads and compiles every schema embedded in the binary.
+func NewRegistry() (*Registry, error) {
+	sub, err := fs.Sub(schemaFS, "schemas")
+	if err != nil {
+		return nil, err
+	}
+	return LoadRegistry(sub)
+}
+
+// MustNewRegistry is NewRegistry that panics on error; the embedded schemas are covered by tests.
+func MustNewRegistry() *Registry {
+	r, err := NewRegistry()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadRegistry compiles every "<Type>.v<N>.json" file at the root of fsys.
+func LoadRegistry(fsys fs.FS) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{schemas: make(map[string]map[int]*Schema)}
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	type pending struct {
+		s   *Schema
+		url string
+	}
+	var toCompile []pending
+	for _, e := range entries {
+		m := schemaFileRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[2])
+		raw, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		url := "mem:///" + path.Clean(e.Name())
+		if err := c.AddResource(url, doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		s := &Schema{Type: m[1], Version: version, Raw: json.RawMessage(raw)}
+		if r.schemas[s.Type] == nil {
+			r.schemas[s.Type] = make(map[int]*Schema)
+		}
+		r.schemas[s.Type][version] = s
+		toCompile = append(toCompile, pending{s: s, url: url})
+	}
+	for _, p := range toCompile {
+		compiled, err := c.Compile(p.url)
+		if err != nil {
+			return nil, fmt.Errorf("compile %s v%d: %w", p.s.Type, p.s.Version, err)
+		}
+		p.s.compiled = compiled
+	}
+	return r, nil
+}
+
+// Get returns the schema for eventType at version.
+func (r *Registry) Get(eventType string, version int) (*Schema, error) {
+	s, ok := r.schemas[eventType][version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnknownSchema, eventType, version)
+	}
+	return s, nil
+}
+
+// Latest returns the highest registered version for eventType.
+func (r *Registry) Latest(eventType string) (*Schema, error) {
+	var latest *Schema
+	for _, s := range r.schemas[eventType] {
+		if latest == nil || s.Version > latest.Version {
+			latest = s
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSchema, eventType)
+	}
+	return latest, nil
+}
+
+// List returns every registered schema ordered by type then version.
+func (r *Registry) List() []Schema {
+	out := make([]Schema, 0)
+	for _, versions := range r.schemas {
+		for _, s := range versions {
+			out = append(out, *s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// Validate checks data (the event's JSON "data" member) against the schema for eventType/version.
+func (r *Registry) Validate(eventType string, version int, data []byte) error {
+	s, err := r.Get(eventType, version)
+	if err != nil {
+		return err
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return &ValidationError{Type: eventType, Version: version, Err: err}
+	}
+	if err := s.compiled.Validate(v); err != nil {
+		return &ValidationError{Type: eventType, Version: version, Err: err}
+	}
+	return nil
+}
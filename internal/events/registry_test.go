@@ -0,0 +1,62 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_LoadsEveryDomainEvent(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+
+	for _, eventType := range []string{domain.EventJobCompleted, domain.EventDoseRecorded, domain.EventInvoicePaid, domain.EventAlertRaised} {
+		s, err := r.Latest(eventType)
+		require.NoError(t, err, eventType)
+		assert.GreaterOrEqual(t, s.Version, 1)
+		assert.True(t, json.Valid(s.Raw))
+	}
+	assert.NotEmpty(t, r.List())
+}
+
+func TestRegistry_ValidatesTypedPayloads(t *testing.T) {
+	r := MustNewRegistry()
+	now := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	payloads := map[string]any{
+		domain.EventJobCompleted: domain.JobCompletedV1{JobID: "j1", PoolID: "p1", TechnicianID: "u1", CompletedAt: now},
+		domain.EventDoseRecorded: domain.DoseRecordedV1{DoseEventID: "d1", JobID: "j1", Parameter: "FC", ProductID: "chlorine-12.5", Unit: "oz", ActualAmount: 32, RecordedBy: "u1", RecordedAt: now},
+		domain.EventInvoicePaid:  domain.InvoicePaidV1{InvoiceID: "i1", CustomerID: "c1", AmountCents: 12500, Currency: "USD", PaidAt: now},
+		domain.EventAlertRaised:  domain.AlertRaisedV1{AlertID: "a1", JobReadingID: "r1", AlertType: "LOW_FC", Severity: "CRITICAL", RaisedAt: now},
+	}
+	for eventType, p := range payloads {
+		data, err := json.Marshal(p)
+		require.NoError(t, err)
+		assert.NoError(t, r.Validate(eventType, 1, data), eventType)
+	}
+}
+
+func TestRegistry_RejectsInvalidPayloads(t *testing.T) {
+	r := MustNewRegistry()
+	cases := map[string]string{
+		"missing required":   `{"job_id":"j1"}`,
+		"unknown property":   `{"job_id":"j1","pool_id":"p1","technician_id":"u1","completed_at":"2025-10-05T12:00:00Z","extra":1}`,
+		"bad date-time":      `{"job_id":"j1","pool_id":"p1","technician_id":"u1","completed_at":"yesterday"}`,
+		"wrong type":         `{"job_id":1,"pool_id":"p1","technician_id":"u1","completed_at":"2025-10-05T12:00:00Z"}`,
+		"not even an object": `[]`,
+	}
+	for name, data := range cases {
+		err := r.Validate(domain.EventJobCompleted, 1, []byte(data))
+		var vErr *ValidationError
+		assert.True(t, errors.As(err, &vErr), "%s: expected ValidationError, got %v", name, err)
+	}
+
+	err := r.Validate("NoSuchEvent", 1, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrUnknownSchema)
+	_, err = r.Get(domain.EventJobCompleted, 99)
+	assert.ErrorIs(t, err, ErrUnknownSchema)
+}
@@ -0,0 +1,158 @@
+// Package hashchain links records into a SHA-256 hash chain and signs periodic Ed25519
+// checkpoints over the chain head, so that any edit, insertion, deletion or truncation of
+// the stored history can be proven.
+package hashchain
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+)
+
+// Genesis is the PrevHash of the first record in a chain.
+const Genesis = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Record is a chained entry. ChainContent must cover every stored field except the
+// record's own hash, and must be deterministic.
+type Record interface {
+	ChainSequence() int64
+	ChainPrevHash() string
+	ChainHash() string
+	ChainContent() ([]byte, error)
+}
+
+// Compute returns hex(SHA-256(prevHash || content)).
+func Compute(prevHash string, content []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Break describes the first point at which a chain fails verification.
+type Break struct {
+	Chain    string
+	Sequence int64
+	Reason   string
+}
+
+func (b *Break) Error() string {
+	return fmt.Sprintf("%s chain broken at sequence %d: %s", b.Chain, b.Sequence, b.Reason)
+}
+
+// Verify walks records (oldest first) and returns the first break, or nil if every link holds.
+func Verify[R Record](chain string, records []R) *Break {
+	prev := Genesis
+	for i, r := range records {
+		want := int64(i + 1)
+		if r.ChainSequence() != want {
+			return &Break{Chain: chain, Sequence: want, Reason: fmt.Sprintf("expected sequence %d, found %d (record missing or inserted)", want, r.ChainSequence())}
+		}
+		if r.ChainPrevHash() != prev {
+			return &Break{Chain: chain, Sequence: want, Reason: "prev_hash does not match the previous record's hash"}
+		}
+		content, err := r.ChainContent()
+		if err != nil {
+			return &Break{Chain: chain, Sequence: want, Reason: "cannot encode record: " + err.Error()}
+		}
+		if Compute(prev, content) != r.ChainHash() {
+			return &Break{Chain: chain, Sequence: want, Reason: "hash does not match record contents (record altered)"}
+		}
+		prev = r.ChainHash()
+	}
+	return nil
+}
+
+// VerifyCheckpoints checks each checkpoint's signature and that records still contain the
+// hash it vouches for. It assumes Verify already passed. pub may be nil to skip signatures.
+func VerifyCheckpoints[R Record](chain string, records []R, checkpoints []domain.ChainCheckpoint, pub ed25519.PublicKey) *Break {
+	for _, cp := range checkpoints {
+		if cp.Chain != chain {
+			continue
+		}
+		if pub != nil {
+			if err := VerifyCheckpoint(pub, cp); err != nil {
+				return &Break{Chain: chain, Sequence: cp.Sequence, Reason: fmt.Sprintf("checkpoint %s: %v", cp.ID, err)}
+			}
+		}
+		if cp.Sequence == 0 {
+			continue
+		}
+		if cp.Sequence > int64(len(records)) {
+			return &Break{Chain: chain, Sequence: int64(len(records)) + 1, Reason: fmt.Sprintf("checkpoint %s covers %d records but only %d exist (chain truncated)", cp.ID, cp.Sequence, len(records))}
+		}
+		if records[cp.Sequence-1].ChainHash() != cp.Hash {
+			return &Break{Chain: chain, Sequence: cp.Sequence, Reason: fmt.Sprintf("hash differs from signed checkpoint %s (chain rewritten)", cp.ID)}
+		}
+	}
+	return nil
+}
+
+// ErrBadSignature is returned when a checkpoint signature does not verify.
+var ErrBadSignature = signing.ErrBadSignature
+
+// Signer signs checkpoints with an Ed25519 key.
+type Signer struct {
+	*signing.Signer
+}
+
+// NewSigner builds a Signer from a 32-byte Ed25519 seed.
+func NewSigner(seed []byte) (*Signer, error) {
+	s, err := signing.NewSigner(seed)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint key: %w", err)
+	}
+	return &Signer{Signer: s}, nil
+}
+
+// SignCheckpoint fills in cp.KeyID and cp.Signature.
+func (s *Signer) SignCheckpoint(cp *domain.ChainCheckpoint) {
+	cp.KeyID = s.KeyID()
+	cp.Signature = s.Sign(checkpointMessage(*cp))
+}
+
+// VerifyCheckpoint checks cp's signature against pub.
+func VerifyCheckpoint(pub ed25519.PublicKey, cp domain.ChainCheckpoint) error {
+	return signing.Verify(pub, cp.KeyID, checkpointMessage(cp), cp.Signature)
+}
+
+func checkpointMessage(cp domain.ChainCheckpoint) []byte {
+	return fmt.Appendf(nil, "pool-maintenance checkpoint v1\n%s\n%s\n%d\n%s\n%s",
+		cp.ID, cp.Chain, cp.Sequence, cp.Hash, cp.CreatedAt.UTC().Format(time.RFC3339Nano))
+}
+
+// Result is the outcome of verifying one chain of an export.
+type Result struct {
+	Chain       string
+	Records     int
+	Checkpoints int
+	Break       *Break
+}
+
+// VerifyExport verifies both chains of exp, links first and then checkpoints. pub may be
+// nil to skip signature checks.
+func VerifyExport(exp *domain.ChainExport, pub ed25519.PublicKey) []Result {
+	count := func(chain string) int {
+		n := 0
+		for _, cp := range exp.Checkpoints {
+			if cp.Chain == chain {
+				n++
+			}
+		}
+		return n
+	}
+	audit := Result{Chain: domain.ChainAudit, Records: len(exp.AuditEvents), Checkpoints: count(domain.ChainAudit)}
+	if audit.Break = Verify(domain.ChainAudit, exp.AuditEvents); audit.Break == nil {
+		audit.Break = VerifyCheckpoints(domain.ChainAudit, exp.AuditEvents, exp.Checkpoints, pub)
+	}
+	dose := Result{Chain: domain.ChainDose, Records: len(exp.DoseEvents), Checkpoints: count(domain.ChainDose)}
+	if dose.Break = Verify(domain.ChainDose, exp.DoseEvents); dose.Break == nil {
+		dose.Break = VerifyCheckpoints(domain.ChainDose, exp.DoseEvents, exp.Checkpoints, pub)
+	}
+	return []Result{audit, dose}
+}
@@ -0,0 +1,199 @@
+package hashchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildChain links n audit events the same way the repositories do.
+func buildChain(t *testing.T, n int) []domain.AuditEvent {
+	t.Helper()
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	out := make([]domain.AuditEvent, 0, n)
+	prev := Genesis
+	for i := 0; i < n; i++ {
+		e := domain.AuditEvent{
+			ID: string(rune('a' + i)), ActorID: "u1", ActionType: "DOSE_RECORDED", EntityType: "dose_event",
+			Metadata: []byte(`{"n":1}`), OccurredAt: base.Add(time.Duration(i) * time.Minute),
+			Sequence: int64(i + 1), PrevHash: prev, HashVersion: domain.ChainHashV1,
+		}
+		content, err := e.ChainContent()
+		require.NoError(t, err)
+		e.Hash = Compute(prev, content)
+		prev = e.Hash
+		out = append(out, e)
+	}
+	return out
+}
+
+func testSigner(t *testing.T) *Signer {
+	t.Helper()
+	s, err := NewSigner(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	return s
+}
+
+func checkpointAt(s *Signer, chain []domain.AuditEvent, seq int64) domain.ChainCheckpoint {
+	cp := domain.ChainCheckpoint{ID: "cp", Chain: domain.ChainAudit, Sequence: seq, Hash: chain[seq-1].Hash, CreatedAt: time.Unix(1759665600, 0)}
+	s.SignCheckpoint(&cp)
+	return cp
+}
+
+func TestVerify_IntactChain(t *testing.T) {
+	assert.Nil(t, Verify(domain.ChainAudit, buildChain(t, 5)))
+	assert.Nil(t, Verify(domain.ChainAudit, []domain.AuditEvent{}))
+}
+
+func TestVerify_ReportsFirstBreak(t *testing.T) {
+	cases := map[string]struct {
+		tamper func([]domain.AuditEvent) []domain.AuditEvent
+		seq    int64
+	}{
+		"edited field": {func(c []domain.AuditEvent) []domain.AuditEvent { c[2].ActorID = "mallory"; return c }, 3},
+		"edited metadata": {func(c []domain.AuditEvent) []domain.AuditEvent {
+			c[1].Metadata = []byte(`{"n":2}`)
+			return c
+		}, 2},
+		"deleted record": {func(c []domain.AuditEvent) []domain.AuditEvent { return append(c[:1], c[2:]...) }, 2},
+		"reordered":      {func(c []domain.AuditEvent) []domain.AuditEvent { c[3], c[4] = c[4], c[3]; return c }, 4},
+		"relinked after edit": {func(c []domain.AuditEvent) []domain.AuditEvent {
+			c[1].ActorID = "mallory"
+			content, _ := c[1].ChainContent()
+			c[1].Hash = Compute(c[1].PrevHash, content)
+			return c
+		}, 3},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := Verify(domain.ChainAudit, tc.tamper(buildChain(t, 5)))
+			require.NotNil(t, b)
+			assert.Equal(t, tc.seq, b.Sequence, b.Error())
+		})
+	}
+}
+
+func TestVerifyCheckpoints(t *testing.T) {
+	s := testSigner(t)
+	chain := buildChain(t, 5)
+	cps := []domain.ChainCheckpoint{checkpointAt(s, chain, 3), checkpointAt(s, chain, 5)}
+	assert.Nil(t, VerifyCheckpoints(domain.ChainAudit, chain, cps, s.PublicKey()))
+
+	// Truncating the tail leaves a valid chain that the last checkpoint no longer matches.
+	b := VerifyCheckpoints(domain.ChainAudit, chain[:4], cps, s.PublicKey())
+	require.NotNil(t, b)
+	assert.Contains(t, b.Reason, "truncated")
+
+	// Rewriting the whole chain from record 2 keeps links valid but contradicts the checkpoint.
+	rewritten := buildChain(t, 5)
+	rewritten[1].ActorID = "mallory"
+	prev := rewritten[0].Hash
+	for i := 1; i < len(rewritten); i++ {
+		rewritten[i].PrevHash = prev
+		content, _ := rewritten[i].ChainContent()
+		rewritten[i].Hash = Compute(prev, content)
+		prev = rewritten[i].Hash
+	}
+	require.Nil(t, Verify(domain.ChainAudit, rewritten))
+	b = VerifyCheckpoints(domain.ChainAudit, rewritten, cps, s.PublicKey())
+	require.NotNil(t, b)
+	assert.Equal(t, int64(3), b.Sequence)
+
+	// A forged checkpoint fails its signature.
+	forged := cps[0]
+	forged.Sequence = 2
+	forged.Hash = chain[1].Hash
+	b = VerifyCheckpoints(domain.ChainAudit, chain, []domain.ChainCheckpoint{forged}, s.PublicKey())
+	require.NotNil(t, b)
+	assert.Contains(t, b.Reason, ErrBadSignature.Error())
+
+	other, _ := NewSigner(bytes.Repeat([]byte{9}, 32))
+	assert.ErrorIs(t, VerifyCheckpoint(other.PublicKey(), cps[0]), ErrBadSignature)
+}
+
+func TestNewSigner_RejectsShortSeed(t *testing.T) {
+	_, err := NewSigner([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestVerifyExport(t *testing.T) {
+	s := testSigner(t)
+	chain := buildChain(t, 3)
+	exp := &domain.ChainExport{AuditEvents: chain, Checkpoints: []domain.ChainCheckpoint{checkpointAt(s, chain, 3)}}
+	results := VerifyExport(exp, s.PublicKey())
+	require.Len(t, results, 2)
+	assert.Nil(t, results[0].Break)
+	assert.Equal(t, 3, results[0].Records)
+	assert.Equal(t, 1, results[0].Checkpoints)
+	assert.Nil(t, results[1].Break)
+}
+
+// TestChainContent_IsStable pins the v1 hashes of one audit event and one dose. Adding a
+// field to either struct must leave them unchanged, or every stored chain would stop
+// verifying; hashing a new field takes a new HashVersion. The hashes also survive a JSON
+// round trip, as in an export.
+func TestChainContent_IsStable(t *testing.T) {
+	at := time.Date(2025, 10, 5, 12, 0, 0, 123456789, time.UTC)
+	recommended, before, after := 32.0, 1.2, 3.0
+	records := []struct {
+		name   string
+		record interface{ ChainContent() ([]byte, error) }
+		decode func([]byte) (interface{ ChainContent() ([]byte, error) }, error)
+		want   string
+	}{
+		{
+			name: "audit event",
+			record: domain.AuditEvent{
+				ID: "0b8f6c2e", ActorID: "tech-42", ActorRole: "TECH", ActionType: "DOSE_RECORDED",
+				EntityType: "dose_event", EntityID: "2c9b1f0e", Metadata: []byte(`{"b": 1, "a": [true]}`),
+				OccurredAt: at, RequestID: "req-1", TraceID: "4bf92f35", Sequence: 7, PrevHash: Genesis,
+				HashVersion: domain.ChainHashV1,
+			},
+			decode: func(b []byte) (interface{ ChainContent() ([]byte, error) }, error) {
+				var e domain.AuditEvent
+				err := json.Unmarshal(b, &e)
+				return e, err
+			},
+			want: "92708661988dc991777e3493fb1de54cf5e08c778d731170fd7aadadb8ab9d58",
+		},
+		{
+			name: "dose event",
+			record: domain.DoseEvent{
+				ID: "2c9b1f0e", JobID: "job-123", Parameter: "FC", RecommendedAmount: &recommended,
+				ActualAmount: 30, ProductID: "liquid-chlorine-12.5", Unit: "oz", BeforeValue: &before,
+				AfterValue: &after, UserID: "tech-42", CreatedAt: at, Sequence: 3, PrevHash: Genesis,
+				HashVersion: domain.ChainHashV1,
+			},
+			decode: func(b []byte) (interface{ ChainContent() ([]byte, error) }, error) {
+				var e domain.DoseEvent
+				err := json.Unmarshal(b, &e)
+				return e, err
+			},
+			want: "0a75e013891fbc9d1d53ea22bcc9db56ea8a805817ccb28c868890ee8b384319",
+		},
+	}
+	for _, tc := range records {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := tc.record.ChainContent()
+			require.NoError(t, err)
+			assert.Equal(t, byte(domain.ChainHashV1), content[0], "content starts with the version")
+			assert.Equal(t, tc.want, Compute(Genesis, content))
+
+			encoded, err := json.Marshal(tc.record)
+			require.NoError(t, err)
+			decoded, err := tc.decode(encoded)
+			require.NoError(t, err)
+			content, err = decoded.ChainContent()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, Compute(Genesis, content), "after a JSON round trip")
+		})
+	}
+
+	_, err := domain.AuditEvent{HashVersion: 99}.ChainContent()
+	assert.ErrorContains(t, err, "unknown hash version")
+}
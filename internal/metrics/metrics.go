@@ -0,0 +1,77 @@
+// Package metrics defines the domain metrics of the service (E-OBS-004/005) in one place,
+// so their names and labels are reviewed together and tests can assert that they move.
+// HTTP metrics live with their middleware; these describe what the business does.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a dosing engine call.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Metrics holds the domain metrics. Use cases receive it in their constructor.
+type Metrics struct {
+	// DosingEngineLatency is the time the dosing engine took per recommendation, by outcome.
+	// The dosing SLO is p95 < 1s.
+	DosingEngineLatency *prometheus.HistogramVec
+	// DoseEvents counts recorded DoseEvents by chemistry parameter.
+	DoseEvents *prometheus.CounterVec
+	// Alerts counts alerts by severity and acknowledged: an alert counts once as "false"
+	// when it is raised and once as "true" when it is acknowledged. The difference is the
+	// number still open.
+	Alerts *prometheus.CounterVec
+}
+
+// New creates the domain metrics and registers them with reg unless reg is nil; tests pass
+// nil and read the fields directly.
+func New(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		DosingEngineLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "dosing_engine_latency_seconds",
+			Help:    "Time the dosing engine took to compute a recommendation, by outcome (ok, error).",
+			Buckets: []float64{.05, .1, .25, .5, .75, 1, 1.5, 2.5, 5, 10},
+		}, []string{"outcome"}),
+		DoseEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dose_events_total",
+			Help: "Dose events recorded, by chemistry parameter.",
+		}, []string{"parameter"}),
+		Alerts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alerts_total",
+			Help: "Alerts by severity; acknowledged=false counts raised alerts, acknowledged=true acknowledged ones.",
+		}, []string{"severity", "acknowledged"}),
+	}
+	if reg != nil {
+		reg.MustRegister(m.DosingEngineLatency, m.DoseEvents, m.Alerts)
+	}
+	return m
+}
+
+// ObserveDosingEngine records one dosing engine call that started at start.
+func (m *Metrics) ObserveDosingEngine(start time.Time, err error) {
+	outcome := OutcomeOK
+	if err != nil {
+		outcome = OutcomeError
+	}
+	m.DosingEngineLatency.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
+
+// DoseRecorded counts a recorded dose of parameter.
+func (m *Metrics) DoseRecorded(parameter string) {
+	m.DoseEvents.WithLabelValues(parameter).Inc()
+}
+
+// AlertRaised counts a raised alert.
+func (m *Metrics) AlertRaised(severity string) {
+	m.Alerts.WithLabelValues(severity, "false").Inc()
+}
+
+// AlertAcknowledged counts an acknowledged alert.
+func (m *Metrics) AlertAcknowledged(severity string) {
+	m.Alerts.WithLabelValues(severity, "true").Inc()
+}
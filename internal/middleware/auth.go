@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// Gin context keys set by Auth. ContextScopes is only set for API keys.
+const (
+	ContextUserID = "user_id"
+	ContextRoles  = "roles"
+	ContextScopes = "scopes"
+)
+
+// APIKeyAuthenticator resolves an API key to its record; *usecase.APIKeyService implements it.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, raw string) (*domain.APIKey, error)
+}
+
+// PublicPaths are served without authentication (E-SEC-004): probes, metrics, API docs and
+// the token endpoints, which authenticate with credentials or a refresh token instead.
+var PublicPaths = []string{"/health", "/metrics", "/swagger", "/api/v1/auth"}
+
+// Auth returns a Gin middleware that requires a valid bearer access token or API key on
+// every request except those under publicPaths (matched as path prefixes). On success the
+// user id and roles are stored in the gin context and mirrored into the request context.
+// An API key authenticates as user "apikey:<id>" with the single role
+// domain.RoleIntegration and its scopes under ContextScopes; apiKeys may be nil to accept
+// access tokens only.
+func Auth(logger *zap.Logger, verifier *auth.Verifier, apiKeys APIKeyAuthenticator, publicPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isPublicPath(c.Request.URL.Path, publicPaths) {
+			c.Next()
+			return
+		}
+		raw, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			unauthorized(c, "missing bearer token")
+			return
+		}
+		if auth.IsAPIKey(raw) {
+			authenticateAPIKey(c, logger, apiKeys, raw)
+			return
+		}
+		claims, err := verifier.Verify(raw)
+		if err != nil {
+			requestctx.Enrich(c.Request.Context(), logger).Debug("access token rejected", zap.Error(err), zap.String("path", c.Request.URL.Path))
+			unauthorized(c, "invalid or expired token")
+			return
+		}
+		c.Set(ContextUserID, claims.Subject)
+		c.Set(ContextRoles, claims.Roles)
+		c.Request = c.Request.WithContext(requestctx.WithUser(c.Request.Context(), claims.Subject, claims.Roles))
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, logger *zap.Logger, apiKeys APIKeyAuthenticator, raw string) {
+	if apiKeys == nil {
+		unauthorized(c, "invalid or expired token")
+		return
+	}
+	k, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), raw)
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		requestctx.Enrich(c.Request.Context(), logger).Debug("api key rejected", zap.String("api_key_prefix", auth.APIKeyPrefixOf(raw)),
+			zap.Error(err), zap.String("path", c.Request.URL.Path))
+		unauthorized(c, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		requestctx.Enrich(c.Request.Context(), logger).Error("api key lookup failed", zap.Error(err))
+		AbortWithError(c, err)
+		return
+	}
+	userID, roles := "apikey:"+k.ID, []string{domain.RoleIntegration}
+	c.Set(ContextUserID, userID)
+	c.Set(ContextRoles, roles)
+	c.Set(ContextScopes, k.Scopes)
+	c.Request = c.Request.WithContext(requestctx.WithUser(c.Request.Context(), userID, roles))
+	c.Next()
+}
+
+func isPublicPath(path string, public []string) bool {
+	for _, p := range public {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	AbortWithError(c, &domain.Error{Kind: domain.ErrInvalidCredentials, Message: msg})
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testAuthConfig = auth.Config{Issuer: "test-issuer", Audience: "test-api"}
+
+func newAuthTestRouter(t *testing.T, logger *zap.Logger) (*gin.Engine, *auth.Issuer) {
+	t.Helper()
+	return newAuthTestRouterWithKeys(t, logger, nil)
+}
+
+func newAuthTestRouterWithKeys(t *testing.T, logger *zap.Logger, keys APIKeyAuthenticator) (*gin.Engine, *auth.Issuer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	r := gin.New()
+	r.Use(ZapLogger(logger))
+	r.Use(Auth(zap.NewNop(), auth.NewVerifier(testAuthConfig, signer.PublicKey()), keys, PublicPaths...))
+	return r, auth.NewIssuer(signer, testAuthConfig)
+}
+
+func TestAuth_RejectsMissingAndInvalidTokens(t *testing.T) {
+	r, _ := newAuthTestRouter(t, zap.NewNop())
+	r.GET("/api/v1/things", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for name, header := range map[string]string{
+		"missing":    "",
+		"wrong type": "Basic dXNlcjpwYXNz",
+		"garbage":    "Bearer not.a.token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/things", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+		})
+	}
+}
+
+func TestAuth_PublicPathsBypass(t *testing.T) {
+	r, _ := newAuthTestRouter(t, zap.NewNop())
+	for _, p := range []string{"/health", "/health/live", "/metrics", "/swagger/index.html", "/healthz"} {
+		r.GET(p, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	for path, want := range map[string]int{
+		"/health":             http.StatusOK,
+		"/health/live":        http.StatusOK,
+		"/metrics":            http.StatusOK,
+		"/swagger/index.html": http.StatusOK,
+		"/healthz":            http.StatusUnauthorized, // prefix match is per path segment
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, path)
+	}
+}
+
+func TestAuth_SetsUserAndLogsUserID(t *testing.T) {
+	logger, logs := testLogger()
+	r, issuer := newAuthTestRouter(t, logger)
+	var ginUser, ctxUser string
+	var ginRoles, ctxRoles []string
+	r.GET("/api/v1/me", func(c *gin.Context) {
+		ginUser = c.GetString(ContextUserID)
+		ginRoles = c.GetStringSlice(ContextRoles)
+		ctxUser = requestctx.UserID(c.Request.Context())
+		ctxRoles = requestctx.Roles(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	token, _, err := issuer.Issue("u-42", []string{"MANAGER"})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u-42", ginUser)
+	assert.Equal(t, "u-42", ctxUser)
+	assert.Equal(t, []string{"MANAGER"}, ginRoles)
+	assert.Equal(t, []string{"MANAGER"}, ctxRoles)
+
+	entries := logs.FilterMessage("request completed").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "u-42", entries[0].ContextMap()["user_id"])
+}
+
+// staticKeys authenticates the API keys it holds, keyed by plain value.
+type staticKeys map[string]*domain.APIKey
+
+func (k staticKeys) AuthenticateAPIKey(_ context.Context, raw string) (*domain.APIKey, error) {
+	if key, ok := k[raw]; ok {
+		return key, nil
+	}
+	return nil, domain.ErrInvalidCredentials
+}
+
+const testAPIKey = "pmk_0badcafe_secret"
+
+func TestAuth_APIKey(t *testing.T) {
+	keys := staticKeys{testAPIKey: {ID: "k1", Prefix: "pmk_0badcafe", Scopes: []string{domain.ScopeJobsRead}}}
+	r, _ := newAuthTestRouterWithKeys(t, zap.NewNop(), keys)
+	var user string
+	var roles, scopes []string
+	r.GET("/api/v1/me", func(c *gin.Context) {
+		user = requestctx.UserID(c.Request.Context())
+		roles = requestctx.Roles(c.Request.Context())
+		scopes = c.GetStringSlice(ContextScopes)
+		c.Status(http.StatusOK)
+	})
+	get := func(key string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/me", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, get(testAPIKey))
+	assert.Equal(t, "apikey:k1", user)
+	assert.Equal(t, []string{domain.RoleIntegration}, roles)
+	assert.Equal(t, []string{domain.ScopeJobsRead}, scopes)
+
+	assert.Equal(t, http.StatusUnauthorized, get("pmk_0badcafe_wrong"))
+
+	r, _ = newAuthTestRouter(t, zap.NewNop())
+	r.GET("/api/v1/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIKey)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "keys are refused when no key store is configured")
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// Error codes of the E-API-003 envelope. Clients branch on the code, not on the message.
+const (
+	CodeValidation   = "VALIDATION_FAILED"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeUnavailable  = "SERVICE_UNAVAILABLE"
+	CodeRateLimited  = "RATE_LIMITED"
+	CodeInternal     = "INTERNAL"
+)
+
+// ErrorBody is the content of the E-API-003 error envelope. CorrelationID is the request
+// id ZapLogger logs, so a client report can be matched to the server log line.
+type ErrorBody struct {
+	Code          string              `json:"code" example:"NOT_FOUND"`
+	Message       string              `json:"message" example:"webhook delivery \"d-1\" not found"`
+	CorrelationID string              `json:"correlation_id" example:"f3b1a6d9099f4f42e8e97d5d6d3fe0c2"`
+	Fields        []domain.FieldError `json:"fields,omitempty"`
+}
+
+// ErrorResponse is the body of every error response (E-API-003).
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Errors returns a Gin middleware that renders the last error a handler recorded with
+// c.Error as an ErrorResponse, unless the handler already chose a status or wrote a
+// response. Errors that map to 5xx are logged with the request id; their message is never
+// shown to the client.
+func Errors(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		renderError(c, logger)
+	}
+}
+
+// renderError writes the envelope for the last recorded error unless the response is already
+// chosen. Errors calls it after the chain; middleware that inspects the response, such as
+// Idempotency, calls it first so it sees what the client will get.
+func renderError(c *gin.Context, logger *zap.Logger) {
+	if len(c.Errors) == 0 || c.Writer.Written() || c.Writer.Status() != http.StatusOK {
+		return
+	}
+	writeError(c, logger, c.Errors.Last().Err)
+}
+
+// AbortWithError records err, writes its envelope immediately and stops the chain. It is
+// for middleware, which runs before Errors could render anything.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	writeError(c, nil, err)
+	c.Abort()
+}
+
+// Recovery returns a Gin middleware that turns a panic into a logged 500 envelope. It
+// replaces gin.Recovery, whose plain-text response breaks clients expecting JSON; register
+// it after ZapLogger so the request id is set and the request is still logged.
+func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				requestctx.Enrich(c.Request.Context(), logger).Error("panic recovered",
+					zap.Any("panic", rec),
+					zap.String("path", c.Request.URL.Path),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				if !c.Writer.Written() {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, envelope(c, CodeInternal, "internal error", nil))
+					return
+				}
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
+
+// NotFound renders the envelope for unknown routes; register it with gin's NoRoute.
+func NotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, envelope(c, CodeNotFound, "no route for "+c.Request.Method+" "+c.Request.URL.Path, nil))
+}
+
+// StatusOf returns the HTTP status and envelope code for err.
+func StatusOf(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest, CodeValidation
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return http.StatusUnauthorized, CodeUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, CodeForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, CodeConflict
+	case errors.Is(err, domain.ErrUnavailable):
+		return http.StatusServiceUnavailable, CodeUnavailable
+	case errors.Is(err, domain.ErrRateLimited):
+		return http.StatusTooManyRequests, CodeRateLimited
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
+
+func writeError(c *gin.Context, logger *zap.Logger, err error) {
+	status, code := StatusOf(err)
+	msg, fields := publicMessage(status, err)
+	if status >= http.StatusInternalServerError && logger != nil {
+		requestctx.Enrich(c.Request.Context(), logger).Error("request failed",
+			zap.Error(err),
+			zap.String("path", c.Request.URL.Path),
+		)
+	}
+	c.JSON(status, envelope(c, code, msg, fields))
+}
+
+// publicMessage returns what the client may see. Only typed domain errors carry a message
+// written for clients. Any other error, including a sentinel wrapped with fmt.Errorf, gets
+// a fixed text per status: wrapped text names internals (repository calls, ids, locked
+// accounts) and is for the server log only.
+func publicMessage(status int, err error) (string, []domain.FieldError) {
+	var de *domain.Error
+	if errors.As(err, &de) && status != http.StatusInternalServerError {
+		msg := de.Message
+		if msg == "" {
+			msg = de.Error()
+		}
+		return msg, de.Fields
+	}
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid input", nil
+	case http.StatusNotFound:
+		return "not found", nil
+	case http.StatusConflict:
+		return "conflict", nil
+	case http.StatusUnauthorized:
+		return "invalid credentials", nil
+	case http.StatusForbidden:
+		return "forbidden", nil
+	case http.StatusServiceUnavailable:
+		return "service unavailable", nil
+	case http.StatusTooManyRequests:
+		return "rate limit exceeded", nil
+	default:
+		return "internal error", nil
+	}
+}
+
+func envelope(c *gin.Context, code, msg string, fields []domain.FieldError) ErrorResponse {
+	return ErrorResponse{Error: ErrorBody{
+		Code:          code,
+		Message:       msg,
+		CorrelationID: c.GetString("request_id"),
+		Fields:        fields,
+	}}
+}
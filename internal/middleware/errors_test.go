@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return resp.Error
+}
+
+func newErrorsTestRouter(logger *zap.Logger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ZapLogger(zap.NewNop()), Recovery(logger), Errors(logger))
+	r.NoRoute(NotFound)
+	return r
+}
+
+func TestErrors_MapsDomainErrorsToEnvelope(t *testing.T) {
+	cases := []struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{domain.NotFound("webhook delivery", "d-1"), http.StatusNotFound, CodeNotFound, `webhook delivery "d-1" not found`},
+		{domain.Conflict("an authenticator is already enrolled"), http.StatusConflict, CodeConflict, "an authenticator is already enrolled"},
+		{domain.Forbidden("cannot record a dose for another user"), http.StatusForbidden, CodeForbidden, "cannot record a dose for another user"},
+		{domain.Unavailable("dosing engine not configured"), http.StatusServiceUnavailable, CodeUnavailable, "dosing engine not configured"},
+		{domain.Validation("user id is required"), http.StatusBadRequest, CodeValidation, "user id is required"},
+		{fmt.Errorf("load job %s: %w", "job-9", domain.Validation("job id is malformed")), http.StatusBadRequest, CodeValidation, "job id is malformed"},
+		{fmt.Errorf("%w: user id is required", domain.ErrInvalidInput), http.StatusBadRequest, CodeValidation, "invalid input"},
+		{fmt.Errorf("load dose %s: %w", "d-1", domain.ErrNotFound), http.StatusNotFound, CodeNotFound, "not found"},
+		{fmt.Errorf("append audit event a-1: %w", domain.ErrConflict), http.StatusConflict, CodeConflict, "conflict"},
+		{fmt.Errorf("%w: account locked", domain.ErrInvalidCredentials), http.StatusUnauthorized, CodeUnauthorized, "invalid credentials"},
+		{fmt.Errorf("%w: internal reason", domain.ErrForbidden), http.StatusForbidden, CodeForbidden, "forbidden"},
+		{domain.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded"},
+		{errors.New("connection refused"), http.StatusInternalServerError, CodeInternal, "internal error"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			core, logs := observer.New(zap.ErrorLevel)
+			r := newErrorsTestRouter(zap.New(core))
+			r.GET("/x", func(c *gin.Context) { _ = c.Error(tc.err) })
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/x", nil)
+			req.Header.Set(requestIDHeader, "req-123")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			body := decodeError(t, w)
+			assert.Equal(t, tc.code, body.Code)
+			assert.Equal(t, tc.message, body.Message)
+			assert.Equal(t, "req-123", body.CorrelationID)
+			if tc.status >= http.StatusInternalServerError && tc.status != http.StatusServiceUnavailable {
+				assert.Equal(t, 1, logs.Len(), "server errors are logged")
+			}
+		})
+	}
+}
+
+func TestErrors_ValidationFields(t *testing.T) {
+	r := newErrorsTestRouter(zap.NewNop())
+	r.POST("/x", func(c *gin.Context) {
+		_ = c.Error(domain.Validation("", domain.FieldError{Field: "unit", Message: "is required"}))
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/x", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	body := decodeError(t, w)
+	assert.Equal(t, CodeValidation, body.Code)
+	assert.Equal(t, "invalid input: unit is required", body.Message)
+	assert.Equal(t, []domain.FieldError{{Field: "unit", Message: "is required"}}, body.Fields)
+	assert.NotEmpty(t, body.CorrelationID, "a generated request id is used")
+}
+
+func TestErrors_KeepsWrittenResponse(t *testing.T) {
+	r := newErrorsTestRouter(zap.NewNop())
+	r.GET("/x", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.Status(http.StatusAccepted)
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/x", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestRecovery_RendersEnvelope(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	r := newErrorsTestRouter(zap.New(core))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(requestIDHeader, "req-9")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	body := decodeError(t, w)
+	assert.Equal(t, ErrorBody{Code: CodeInternal, Message: "internal error", CorrelationID: "req-9"}, body)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "panic recovered", logs.All()[0].Message)
+	assert.NotContains(t, w.Body.String(), "boom")
+}
+
+func TestNotFound_RendersEnvelope(t *testing.T) {
+	r := newErrorsTestRouter(zap.NewNop())
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/nope", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, CodeNotFound, decodeError(t, w).Code)
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader carries the client's key; IdempotentReplayedHeader marks a response
+// served from the store instead of the handler.
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds the key; a UUID is 36 characters.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored with a response and replayed with it.
+var replayedHeaders = []string{"Content-Type", "Location", "Cache-Control"}
+
+// Idempotency returns a Gin middleware that makes a route safe to retry (E-API-005). The
+// first request with an Idempotency-Key runs and its response is stored under the key, the
+// principal and the route for ttl; a retry with the same body gets the stored response back
+// with Idempotent-Replayed: true, a retry with a different body gets 409, and a retry
+// while the first request still runs gets 409 with Retry-After. 5xx responses and panics
+// are not stored, so a retry after a server failure runs again. Requests without the header pass
+// through. It must run after Auth.
+func Idempotency(logger *zap.Logger, store domain.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientKey := c.GetHeader(IdempotencyKeyHeader)
+		if clientKey == "" {
+			c.Next()
+			return
+		}
+		if len(clientKey) > maxIdempotencyKeyLength {
+			AbortWithError(c, domain.Validation("invalid Idempotency-Key", domain.FieldError{
+				Field: IdempotencyKeyHeader, Message: "must be at most " + strconv.Itoa(maxIdempotencyKeyLength) + " characters",
+			}))
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			AbortWithError(c, domain.Validation("request body could not be read"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		sum := sha256.Sum256(body)
+		rec := &domain.IdempotencyRecord{
+			Key:         idempotencyStoreKey(c.GetString(ContextUserID), c.Request.Method+" "+c.Request.URL.Path, clientKey),
+			RequestHash: hex.EncodeToString(sum[:]),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		err = store.Create(ctx, rec)
+		if errors.Is(err, domain.ErrConflict) {
+			replay(c, store, rec)
+			return
+		}
+		if err != nil {
+			requestctx.Enrich(c.Request.Context(), logger).Error("idempotency store failed", zap.Error(err))
+			AbortWithError(c, err)
+			return
+		}
+
+		// The request context may be cancelled once the client is gone; the outcome must
+		// still be recorded or released.
+		storeCtx := context.WithoutCancel(ctx)
+		release := func() {
+			if err := store.Delete(storeCtx, rec.Key); err != nil {
+				requestctx.Enrich(c.Request.Context(), logger).Error("idempotency release failed", zap.Error(err))
+			}
+		}
+		// A panicking handler would otherwise leave the key "in progress" until it expires.
+		defer func() {
+			if p := recover(); p != nil {
+				release()
+				panic(p)
+			}
+		}()
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		renderError(c, logger)
+
+		if w.Status() >= http.StatusInternalServerError {
+			release()
+			return
+		}
+		rec.Completed = true
+		rec.StatusCode = w.Status()
+		rec.Body = w.body.Bytes()
+		rec.Header = make(map[string]string)
+		for _, h := range replayedHeaders {
+			if v := w.Header().Get(h); v != "" {
+				rec.Header[h] = v
+			}
+		}
+		if err := store.Update(storeCtx, rec); err != nil {
+			// A retry runs again rather than waiting on a record that never completes.
+			requestctx.Enrich(c.Request.Context(), logger).Error("idempotency record failed", zap.Error(err))
+			release()
+		}
+	}
+}
+
+// replay answers a request whose key is already taken.
+func replay(c *gin.Context, store domain.IdempotencyRepository, rec *domain.IdempotencyRecord) {
+	stored, err := store.Get(c.Request.Context(), rec.Key)
+	if errors.Is(err, domain.ErrNotFound) {
+		// The first request failed and released the key in between; let the client retry.
+		err = domain.Conflict("a request with this Idempotency-Key just failed; retry")
+	}
+	switch {
+	case err != nil:
+		AbortWithError(c, err)
+	case stored.RequestHash != rec.RequestHash:
+		AbortWithError(c, domain.Conflict("Idempotency-Key was already used with a different request body"))
+	case !stored.Completed:
+		c.Header("Retry-After", "1")
+		AbortWithError(c, domain.Conflict("a request with this Idempotency-Key is still in progress"))
+	default:
+		for h, v := range stored.Header {
+			c.Header(h, v)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(stored.StatusCode, stored.Header["Content-Type"], stored.Body)
+		c.Abort()
+	}
+}
+
+// idempotencyStoreKey scopes a client key to the principal and route.
+func idempotencyStoreKey(principal, route, key string) string {
+	sum := sha256.Sum256([]byte(principal + "\x00" + route + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordingWriter copies the response body as it is written.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
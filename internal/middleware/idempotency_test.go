@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newIdempotencyTestRouter serves POST /doses and /other behind Idempotency. The user comes
+// from the X-User header, standing in for Auth; calls counts handler runs.
+func newIdempotencyTestRouter(ttl time.Duration, status *int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	r := gin.New()
+	r.Use(Errors(zap.NewNop()), func(c *gin.Context) {
+		c.Set(ContextUserID, c.GetHeader("X-User"))
+	}, Idempotency(zap.NewNop(), repository.NewInMemoryIdempotencyRepository(), ttl))
+	handler := func(c *gin.Context) {
+		calls++
+		if *status >= http.StatusInternalServerError {
+			_ = c.Error(domain.Unavailable("engine down"))
+			return
+		}
+		c.Header("Location", "/doses/1")
+		c.JSON(*status, gin.H{"call": calls})
+	}
+	r.POST("/doses", handler)
+	r.POST("/other", handler)
+	return r, &calls
+}
+
+func postIdempotent(r *gin.Engine, path, user, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := newIdempotencyTestRouter(time.Hour, &status)
+
+	first := postIdempotent(r, "/doses", "u1", "k1", `{"amount":1}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	retry := postIdempotent(r, "/doses", "u1", "k1", `{"amount":1}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/doses/1", retry.Header().Get("Location"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, *calls, "the handler runs once")
+
+	postIdempotent(r, "/doses", "u1", "", `{"amount":1}`)
+	postIdempotent(r, "/doses", "u1", "", `{"amount":1}`)
+	assert.Equal(t, 3, *calls, "requests without a key are not deduplicated")
+}
+
+func TestIdempotency_RejectsKeyReusedWithOtherBody(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := newIdempotencyTestRouter(time.Hour, &status)
+
+	postIdempotent(r, "/doses", "u1", "k1", `{"amount":1}`)
+	w := postIdempotent(r, "/doses", "u1", "k1", `{"amount":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, CodeConflict, decodeError(t, w).Code)
+	assert.Equal(t, 1, *calls)
+
+	w = postIdempotent(r, "/doses", "u1", strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotency_ScopesKeysToPrincipalAndRoute(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := newIdempotencyTestRouter(time.Hour, &status)
+
+	postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	assert.Equal(t, http.StatusCreated, postIdempotent(r, "/doses", "u2", "k1", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, postIdempotent(r, "/other", "u1", "k1", `{}`).Code)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	r, calls := newIdempotencyTestRouter(time.Hour, &status)
+
+	w := postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	status = http.StatusCreated
+	w = postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	r := gin.New()
+	r.Use(Recovery(zap.NewNop()), Errors(zap.NewNop()),
+		Idempotency(zap.NewNop(), repository.NewInMemoryIdempotencyRepository(), time.Hour))
+	r.POST("/doses", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	w := postIdempotent(r, "/doses", "", "k1", `{}`)
+	require.Equal(t, http.StatusInternalServerError, w.Code, "the panic still reaches Recovery")
+	w = postIdempotent(r, "/doses", "", "k1", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code, "the key is not left in progress")
+	assert.Equal(t, 2, calls)
+}
+
+// failingUpdateStore loses every Update, as a store that went away mid-request would.
+type failingUpdateStore struct {
+	*repository.InMemoryIdempotencyRepository
+}
+
+func (failingUpdateStore) Update(context.Context, *domain.IdempotencyRecord) error {
+	return errors.New("store unavailable")
+}
+
+func TestIdempotency_FailedRecordReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	r := gin.New()
+	r.Use(Errors(zap.NewNop()),
+		Idempotency(zap.NewNop(), failingUpdateStore{repository.NewInMemoryIdempotencyRepository()}, time.Hour))
+	r.POST("/doses", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	postIdempotent(r, "/doses", "", "k1", `{}`)
+	w := postIdempotent(r, "/doses", "", "k1", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code, "a retry runs instead of waiting forever")
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_StoresClientErrors(t *testing.T) {
+	status := http.StatusBadRequest
+	r, calls := newIdempotencyTestRouter(time.Hour, &status)
+
+	postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	status = http.StatusCreated
+	w := postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a retry gets the original answer")
+	assert.Equal(t, 1, *calls)
+}
+
+func TestIdempotency_KeysExpire(t *testing.T) {
+	status := http.StatusCreated
+	r, calls := newIdempotencyTestRouter(time.Nanosecond, &status)
+
+	postIdempotent(r, "/doses", "u1", "k1", `{}`)
+	time.Sleep(time.Millisecond)
+	w := postIdempotent(r, "/doses", "u1", "k1", `{"amount":2}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, *calls)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing random paths
+// can not blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+// Metrics returns a Gin middleware that records RED metrics per request (E-OBS-003):
+// http_requests_total, http_request_errors_total (5xx responses) and the
+// http_request_duration_seconds histogram, labelled by method, route template and status
+// class. Register it before Recovery so panics are counted as the 500s they become.
+func Metrics(reg prometheus.Registerer) gin.HandlerFunc {
+	labels := []string{"method", "route", "status_class"}
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route template and status class.",
+	}, labels)
+	errs := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_request_errors_total",
+		Help: "HTTP requests answered with a 5xx status, by method, route template and status class.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to serve HTTP requests, by method, route template and status class.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, labels)
+	if reg != nil {
+		reg.MustRegister(requests, errs, duration)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := c.Writer.Status()
+		values := []string{c.Request.Method, route, strconv.Itoa(status/100) + "xx"}
+		requests.WithLabelValues(values...).Inc()
+		duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		if status >= 500 {
+			errs.WithLabelValues(values...).Inc()
+		}
+	}
+}
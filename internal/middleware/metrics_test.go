@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMetrics_RecordsREDByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := prometheus.NewRegistry()
+	r := gin.New()
+	r.Use(Metrics(reg), Recovery(zap.NewNop()))
+	r.NoRoute(NotFound)
+	r.GET("/jobs/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/boom", func(c *gin.Context) { panic("boom") })
+
+	for _, path := range []string{"/jobs/1", "/jobs/2", "/boom", "/nope"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	count := func(name string, labels ...string) float64 {
+		for _, f := range families {
+			if f.GetName() != name {
+				continue
+			}
+			for _, m := range f.GetMetric() {
+				got := map[string]string{}
+				for _, l := range m.GetLabel() {
+					got[l.GetName()] = l.GetValue()
+				}
+				if got["method"] == labels[0] && got["route"] == labels[1] && got["status_class"] == labels[2] {
+					if h := m.GetHistogram(); h != nil {
+						return float64(h.GetSampleCount())
+					}
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	assert.Equal(t, 2.0, count("http_requests_total", "GET", "/jobs/:id", "2xx"), "raw paths are folded into the template")
+	assert.Equal(t, 2.0, count("http_request_duration_seconds", "GET", "/jobs/:id", "2xx"))
+	assert.Equal(t, 1.0, count("http_requests_total", "GET", "/boom", "5xx"), "panics count as 500s")
+	assert.Equal(t, 1.0, count("http_request_errors_total", "GET", "/boom", "5xx"))
+	assert.Equal(t, 1.0, count("http_requests_total", "GET", unmatchedRoute, "4xx"))
+	assert.Equal(t, 1, testutil.CollectAndCount(reg, "http_request_errors_total"), "only 5xx are errors")
+}
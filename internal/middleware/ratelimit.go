@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// contextRateLimitRemaining holds the lowest remaining count reported so far, so that when a
+// request is charged to several budgets the headers describe the tightest one.
+const contextRateLimitRemaining = "ratelimit_remaining"
+
+// RateLimiter charges requests to per-principal token buckets. A principal is the API key or
+// user Auth resolved, or the client IP for unauthenticated requests, so one partner's
+// runaway client drains only its own budget.
+type RateLimiter struct {
+	logger    *zap.Logger
+	store     domain.RateLimitStore
+	now       func() time.Time
+	decisions *prometheus.CounterVec
+}
+
+// NewRateLimiter creates a limiter backed by store. Its metrics are registered with reg
+// unless reg is nil.
+func NewRateLimiter(logger *zap.Logger, store domain.RateLimitStore, reg prometheus.Registerer) *RateLimiter {
+	decisions := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_requests_total",
+		Help: "Requests checked against each rate limit budget, by outcome (allowed, limited, error).",
+	}, []string{"limit", "outcome"})
+	if reg != nil {
+		reg.MustRegister(decisions)
+	}
+	return &RateLimiter{logger: logger, store: store, now: time.Now, decisions: decisions}
+}
+
+// Limit returns a Gin middleware that charges each request to limit. It sets the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers and
+// rejects a request with 429 and Retry-After once the bucket is empty. If the store fails
+// the request is let through: an outage of the limiter must not become an outage of the
+// API. It must run after Auth.
+func (l *RateLimiter) Limit(limit domain.RateLimit) gin.HandlerFunc {
+	policy := fmt.Sprintf("%d;w=%d", limit.Burst, int(limit.Period.Seconds()))
+	return func(c *gin.Context) {
+		principal := c.GetString(ContextUserID)
+		if principal == "" {
+			principal = "ip:" + c.ClientIP()
+		}
+		res, err := l.store.Take(c.Request.Context(), limit.Name+"|"+principal, limit, l.now())
+		if err != nil {
+			l.decisions.WithLabelValues(limit.Name, "error").Inc()
+			requestctx.Enrich(c.Request.Context(), l.logger).Error("rate limit store failed", zap.String("limit", limit.Name), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if prev, ok := c.Get(contextRateLimitRemaining); !ok || res.Remaining <= prev.(int) || !res.Allowed {
+			c.Set(contextRateLimitRemaining, res.Remaining)
+			c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			c.Header("RateLimit-Policy", policy)
+		}
+		if !res.Allowed {
+			l.decisions.WithLabelValues(limit.Name, "limited").Inc()
+			retry := ceilSeconds(res.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retry))
+			AbortWithError(c, domain.RateLimited(fmt.Sprintf("%s rate limit exceeded; retry in %d seconds", limit.Name, retry)))
+			return
+		}
+		l.decisions.WithLabelValues(limit.Name, "allowed").Inc()
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, at least 1 for a positive d.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
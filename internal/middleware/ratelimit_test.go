@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var (
+	testDefaultLimit = domain.RateLimit{Name: "default", Burst: 3, Period: time.Minute}
+	testExpensive    = domain.RateLimit{Name: "expensive", Burst: 1, Period: time.Minute}
+)
+
+// newRateLimitTestRouter serves GET /cheap under the default budget and GET /expensive
+// under both budgets. The user comes from the X-User header, standing in for Auth.
+func newRateLimitTestRouter(store domain.RateLimitStore) (*gin.Engine, *RateLimiter, *time.Time) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(zap.NewNop(), store, prometheus.NewRegistry())
+	l.now = func() time.Time { return now }
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if u := c.GetHeader("X-User"); u != "" {
+			c.Set(ContextUserID, u)
+		}
+	}, l.Limit(testDefaultLimit))
+	r.GET("/cheap", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.GET("/expensive", l.Limit(testExpensive), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r, l, &now
+}
+
+func getAs(r *gin.Engine, path, user string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_EnforcesBudgetPerPrincipal(t *testing.T) {
+	r, l, now := newRateLimitTestRouter(repository.NewInMemoryRateLimitStore())
+
+	for i := range 3 {
+		w := getAs(r, "/cheap", "apikey:k1")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, []string{"2", "1", "0"}[i], w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "3;w=60", w.Header().Get("RateLimit-Policy"))
+	}
+	w := getAs(r, "/cheap", "apikey:k1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, CodeRateLimited, decodeError(t, w).Code)
+	assert.Equal(t, "20", w.Header().Get("Retry-After"), "one token refills every 20s")
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusNoContent, getAs(r, "/cheap", "user-1").Code, "other principals keep their budget")
+	assert.Equal(t, http.StatusNoContent, getAs(r, "/cheap", "").Code, "anonymous requests are keyed by IP")
+
+	*now = now.Add(20 * time.Second)
+	assert.Equal(t, http.StatusNoContent, getAs(r, "/cheap", "apikey:k1").Code, "bucket refilled")
+	assert.Equal(t, http.StatusTooManyRequests, getAs(r, "/cheap", "apikey:k1").Code)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(l.decisions.WithLabelValues("default", "limited")))
+	assert.Equal(t, 6.0, testutil.ToFloat64(l.decisions.WithLabelValues("default", "allowed")))
+}
+
+func TestRateLimit_ExpensiveRoutesHaveSeparateBudget(t *testing.T) {
+	r, _, _ := newRateLimitTestRouter(repository.NewInMemoryRateLimitStore())
+
+	w := getAs(r, "/expensive", "user-1")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"), "headers describe the tightest budget")
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = getAs(r, "/expensive", "user-1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, decodeError(t, w).Message, "expensive rate limit exceeded")
+	assert.Equal(t, http.StatusNoContent, getAs(r, "/cheap", "user-1").Code, "cheap routes are unaffected")
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, domain.RateLimit, time.Time) (domain.RateLimitResult, error) {
+	return domain.RateLimitResult{}, errors.New("redis down")
+}
+
+func TestRateLimit_FailsOpenWhenStoreFails(t *testing.T) {
+	r, l, _ := newRateLimitTestRouter(failingRateLimitStore{})
+	for range 5 {
+		assert.Equal(t, http.StatusNoContent, getAs(r, "/cheap", "user-1").Code)
+	}
+	assert.Equal(t, 5.0, testutil.ToFloat64(l.decisions.WithLabelValues("default", "error")))
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// Require returns a Gin middleware that admits only callers holding at least one of roles
+// (E-SEC-002). It must run after Auth: a request without an authenticated user gets 401,
+// one whose token lacks every role gets 403. API keys hold no user role, so routes guarded
+// by Require alone refuse them.
+func Require(roles ...string) gin.HandlerFunc {
+	return Allow("", roles...)
+}
+
+// Allow is Require for routes that integration partners may also call: users need one of
+// roles, API keys need scope. An empty scope admits no API key.
+func Allow(scope string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(ContextUserID) == "" {
+			unauthorized(c, "missing bearer token")
+			return
+		}
+		if scopes, isKey := c.Get(ContextScopes); isKey {
+			granted, _ := scopes.([]string)
+			switch {
+			case scope == "":
+				AbortWithError(c, domain.Forbidden("api keys are not accepted on this route"))
+			case !slices.Contains(granted, scope):
+				AbortWithError(c, domain.Forbidden(fmt.Sprintf("api key lacks scope %q", scope)))
+			default:
+				c.Next()
+			}
+			return
+		}
+		granted := c.GetStringSlice(ContextRoles)
+		for _, r := range roles {
+			if slices.Contains(granted, r) {
+				c.Next()
+				return
+			}
+		}
+		AbortWithError(c, domain.Forbidden("your role may not use this route"))
+	}
+}
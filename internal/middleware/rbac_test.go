@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRequire_WithoutAuthIs401(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/staff", Require(domain.StaffRoles...), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/staff", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAllow_APIKeyNeedsScope(t *testing.T) {
+	keys := staticKeys{testAPIKey: {ID: "k1", Scopes: []string{domain.ScopeJobsRead}}}
+	r, issuer := newAuthTestRouterWithKeys(t, zap.NewNop(), keys)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/jobs", Allow(domain.ScopeJobsRead, domain.StaffRoles...), ok)
+	r.POST("/jobs", Allow(domain.ScopeJobsWrite, domain.StaffRoles...), ok)
+	r.GET("/admin", Require(domain.AdminRoles...), ok)
+	techToken, _, err := issuer.Issue("u-tech", []string{domain.RoleTech})
+	require.NoError(t, err)
+
+	do := func(method, path, credential string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+credential)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("GET", "/jobs", testAPIKey).Code)
+	w := do("POST", "/jobs", testAPIKey)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `api key lacks scope "jobs:write"`, decodeError(t, w).Message)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/admin", testAPIKey).Code, "routes without a scope refuse keys")
+
+	assert.Equal(t, http.StatusOK, do("GET", "/jobs", techToken).Code, "users are still checked by role")
+	assert.Equal(t, http.StatusOK, do("POST", "/jobs", techToken).Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/admin", techToken).Code)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/mgmacri/pool-maintenance-app/internal/middleware"
+
+// Gin context keys set by Tracing (E-OBS-001): the server span's id, whether the trace is
+// sampled, and the W3C tracestate inherited from the caller.
+const (
+	ContextSpanID       = "span_id"
+	ContextTraceSampled = "trace_sampled"
+	ContextTraceState   = "tracestate"
+)
+
+// traceResponseHeader returns the server span to the caller (W3C Trace Context Level 2), so
+// a client that started no trace can still find the request's trace.
+const traceResponseHeader = "traceresponse"
+
+// Tracing returns a Gin middleware that wraps each request in an OpenTelemetry server span
+// (E-OBS-002). The span continues the trace of incoming propagation headers, such as a W3C
+// traceparent or B3, or starts a new trace, and is stored in the request context where
+// ZapLogger, use cases and outbound HTTP clients pick it up. The span id, sampled flag and
+// tracestate are also set in the gin context, and the span is echoed in a traceresponse
+// header. Register it first so the span covers the whole request.
+func Tracing(tp trace.TracerProvider, prop propagation.TextMapPropagator) gin.HandlerFunc {
+	tracer := tp.Tracer(tracerName)
+	return func(c *gin.Context) {
+		ctx := prop.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		name, route := c.Request.Method, c.FullPath()
+		attrs := []attribute.KeyValue{
+			attribute.String("http.request.method", c.Request.Method),
+			attribute.String("url.path", c.Request.URL.Path),
+			attribute.String("client.address", c.ClientIP()),
+			attribute.String("user_agent.original", c.Request.UserAgent()),
+		}
+		if route != "" {
+			name += " " + route
+			attrs = append(attrs, attribute.String("http.route", route))
+		}
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		sc := span.SpanContext()
+		c.Set(ContextSpanID, sc.SpanID().String())
+		c.Set(ContextTraceSampled, sc.IsSampled())
+		c.Set(ContextTraceState, sc.TraceState().String())
+		if sc.IsValid() {
+			c.Header(traceResponseHeader, "00-"+sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sc.TraceFlags().String())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if last := c.Errors.Last(); last != nil {
+				span.RecordError(last.Err)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestTracing_ServerSpans(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	r := gin.New()
+	r.Use(Tracing(tp, tracing.Propagator()), Errors(zap.NewNop()))
+	r.GET("/jobs/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.GET("/down", func(c *gin.Context) { _ = c.Error(domain.Unavailable("engine down")) })
+
+	req, _ := http.NewRequest("GET", "/jobs/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", "/down", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	continued, failed := ended[0], ended[1]
+	assert.Equal(t, "GET /jobs/:id", continued.Name())
+	assert.Equal(t, trace.SpanKindServer, continued.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", continued.SpanContext().TraceID().String(), "continues the caller's trace")
+	assert.Equal(t, "00f067aa0ba902b7", continued.Parent().SpanID().String())
+	assert.Contains(t, continued.Attributes(), attribute.String("http.route", "/jobs/:id"))
+	assert.Contains(t, continued.Attributes(), attribute.Int("http.response.status_code", http.StatusNoContent))
+	assert.Equal(t, codes.Unset, continued.Status().Code)
+
+	assert.False(t, failed.Parent().IsValid(), "starts a new trace")
+	assert.NotEqual(t, continued.SpanContext().TraceID(), failed.SpanContext().TraceID())
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	require.Len(t, failed.Events(), 1, "the error is recorded")
+}
+
+func TestTracing_PropagatesToOutboundCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var gotTraceparent string
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+	}))
+	defer partner.Close()
+
+	tp := sdktrace.NewTracerProvider()
+	client := &http.Client{Transport: tracing.Transport(tp, tracing.Propagator(), nil)}
+	r := gin.New()
+	r.Use(Tracing(tp, tracing.Propagator()))
+	r.GET("/sync", func(c *gin.Context) {
+		out, _ := http.NewRequestWithContext(c.Request.Context(), "GET", partner.URL, nil)
+		resp, err := client.Do(out)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "/sync", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t, "^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$", gotTraceparent)
+	assert.NotContains(t, gotTraceparent, "00f067aa0ba902b7", "the outbound call's parent is the client span")
+}
+
+func TestTracing_ExposesSpanInGinContextAndTraceresponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Tracing(sdktrace.NewTracerProvider(), tracing.Propagator()))
+	var spanID, state string
+	var sampled bool
+	r.GET("/x", func(c *gin.Context) {
+		spanID, state, sampled = c.GetString(ContextSpanID), c.GetString(ContextTraceState), c.GetBool(ContextTraceSampled)
+		c.Status(http.StatusNoContent)
+	})
+
+	for flags, wantSampled := range map[string]bool{"01": true, "00": false} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/x", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-"+flags)
+		req.Header.Set("tracestate", "vendor=abc")
+		r.ServeHTTP(w, req)
+
+		assert.Regexp(t, "^[0-9a-f]{16}$", spanID)
+		assert.NotEqual(t, "00f067aa0ba902b7", spanID, "the server span, not the caller's")
+		assert.Equal(t, wantSampled, sampled, "the caller's sampling decision is kept")
+		assert.Equal(t, "vendor=abc", state)
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spanID+"-"+flags, w.Header().Get("traceresponse"))
+	}
+}
+
+func TestTracing_ContinuesB3(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spans := tracetest.NewSpanRecorder()
+	r := gin.New()
+	r.Use(Tracing(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)), tracing.Propagator()))
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := map[string]struct {
+		headers map[string]string
+		trace   string
+		parent  string
+	}{
+		"multi header": {map[string]string{
+			"X-B3-TraceId": "463ac35c9f6413ad48485a3953bb6124", "X-B3-SpanId": "a2fb4a1d1a96d312", "X-B3-Sampled": "1",
+		}, "463ac35c9f6413ad48485a3953bb6124", "a2fb4a1d1a96d312"},
+		"single header": {map[string]string{
+			"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+		}, "80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1"},
+		"64-bit trace id": {map[string]string{
+			"b3": "64fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+		}, "000000000000000064fe8b2a57d3eff7", "e457b5a2e4d86bd1"},
+		"traceparent wins": {map[string]string{
+			"b3":          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		}, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spans.Reset()
+			req, _ := http.NewRequest("GET", "/x", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			ended := spans.Ended()
+			require.Len(t, ended, 1)
+			assert.Equal(t, tc.trace, ended[0].SpanContext().TraceID().String())
+			assert.Equal(t, tc.parent, ended[0].Parent().SpanID().String())
+		})
+	}
+}
@@ -3,11 +3,12 @@ package middleware
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/redact"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -20,34 +21,41 @@ func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
 
 	// Correlation: request_id from incoming header or generated.
 	reqID := getOrCreateRequestID(c)
-	traceID := extractTraceID(c)
+	// The span Tracing started is in the request context; its trace id is logged.
+	traceID := traceIDFromContext(c)
 	c.Set("trace_id", traceID)
+	// Mirror the ids into the request context for use cases that never see gin.
+	ctx := requestctx.WithTraceID(requestctx.WithRequestID(c.Request.Context(), reqID), traceID)
+	// Code without a logger of its own logs through requestctx.Logger.
+	ctx = requestctx.WithLogger(ctx, logger)
+	c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
 
 		latency := time.Since(start)
 		status := c.Writer.Status()
-	// traceID already extracted (empty if none provided)
+	// traceID already taken from the span (empty if Tracing is not registered)
 		logger.Info("request completed",
 			zap.Int("status", status),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
-			zap.String("query", query),
+			zap.String("query", queryRedactor.Query(query)),
 			zap.String("ip", c.ClientIP()),
 			zap.String("user_agent", c.Request.UserAgent()),
 			zap.Duration("latency", latency),
 			zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()),
 			zap.String("request_id", reqID),
 			zap.String("trace_id", traceID),
+			zap.String("span_id", c.GetString(ContextSpanID)),
+			zap.String("user_id", c.GetString(ContextUserID)),
 		)
 	}
 }
 
+// queryRedactor masks tokens, passwords and PII passed as query parameters (E-SEC-007).
+var queryRedactor = redact.Default()
+
 const requestIDHeader = "X-Request-ID"
-const (
-	traceParentHeader = "traceparent"     // W3C
-	b3TraceIDHeader   = "X-B3-TraceId"    // B3 single header variant (only trace id here)
-)
 
 // getOrCreateRequestID returns the incoming request id or generates a new one.
 // The chosen id is set on the response header and stored in the Gin context.
@@ -71,36 +79,11 @@ func newRequestID() string {
 	return hex.EncodeToString(b)
 }
 
-var (
-	reTraceParent = regexp.MustCompile(`^[ \t]*([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})(?:-.*)?$`)
-	reHex16Or32   = regexp.MustCompile(`^[0-9a-f]{16}|[0-9a-f]{32}$`)
-)
-
-// extractTraceID attempts to pull a trace id from standard propagation headers.
-// Precedence: W3C traceparent (if valid) > B3 TraceId > empty string.
-func extractTraceID(c *gin.Context) string {
-	if tp := c.GetHeader(traceParentHeader); tp != "" {
-		if id := parseTraceParent(tp); id != "" { return id }
-	}
-	if b3 := c.GetHeader(b3TraceIDHeader); b3 != "" {
-		b3 = strings.TrimSpace(b3)
-		if reHex16Or32.MatchString(b3) { return b3 }
+// traceIDFromContext returns the trace id of the span in the request context, or "".
+func traceIDFromContext(c *gin.Context) string {
+	sc := trace.SpanContextFromContext(c.Request.Context())
+	if !sc.HasTraceID() {
+		return ""
 	}
-	return ""
-}
-
-// parseTraceParent validates and returns the 32-hex trace-id from a W3C traceparent header.
-func parseTraceParent(headerVal string) string {
-	m := reTraceParent.FindStringSubmatch(strings.TrimSpace(headerVal))
-	if len(m) != 5 { return "" }
-	version, traceID, parentID, flags := m[1], m[2], m[3], m[4]
-	// Basic sanity: ensure not all zeros (per spec recommendation)
-	if allZeros(traceID) || allZeros(parentID) { return "" }
-	_ = version; _ = flags // not used now, but parse retained for future logic
-	return traceID
-}
-
-func allZeros(s string) bool {
-	for _, r := range s { if r != '0' { return false } }
-	return true
+	return sc.TraceID().String()
 }
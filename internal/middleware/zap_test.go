@@ -7,7 +7,11 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/internal/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -52,11 +56,11 @@ func TestZapLogger_GeneratesRequestID(t *testing.T) {
 	assert.True(t, found, "expected to find request completed log entry")
 }
 
-func TestZapLogger_ExtractsTraceID_FromTraceparent(t *testing.T) {
+func TestZapLogger_LogsTraceAndSpanID_FromTraceparent(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger, logs := testLogger()
 	r := gin.New()
-	r.Use(ZapLogger(logger))
+	r.Use(Tracing(sdktrace.NewTracerProvider(), propagation.TraceContext{}), ZapLogger(logger))
 	r.GET("/tp", func(c *gin.Context) { c.String(200, "ok") })
 
 	w := httptest.NewRecorder()
@@ -73,6 +77,33 @@ func TestZapLogger_ExtractsTraceID_FromTraceparent(t *testing.T) {
 			var m map[string]interface{}
 			_ = json.Unmarshal(b, &m)
 			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", m["trace_id"])
+			assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+m["span_id"].(string)+"-01", w.Header().Get("traceresponse"),
+				"span_id is the server span echoed in traceresponse")
+			return
+		}
+	}
+	t.Fatalf("trace_id not found in logs")
+}
+
+func TestZapLogger_LogsTraceID_OfNewTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, logs := testLogger()
+	r := gin.New()
+	r.Use(Tracing(sdktrace.NewTracerProvider(), propagation.TraceContext{}), ZapLogger(logger))
+	r.GET("/new", func(c *gin.Context) { c.String(200, "ok") })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/new", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	entries := logs.All()
+	for _, e := range entries {
+		if e.Message == "request completed" {
+			b, _ := json.Marshal(e.ContextMap())
+			var m map[string]interface{}
+			_ = json.Unmarshal(b, &m)
+			assert.Regexp(t, "^[0-9a-f]{32}$", m["trace_id"], "requests without trace headers start a trace")
 			return
 		}
 	}
@@ -83,7 +114,7 @@ func TestZapLogger_ExtractsTraceID_FromB3(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger, logs := testLogger()
 	r := gin.New()
-	r.Use(ZapLogger(logger))
+	r.Use(Tracing(sdktrace.NewTracerProvider(), tracing.Propagator()), ZapLogger(logger))
 	r.GET("/b3", func(c *gin.Context) { c.String(200, "ok") })
 
 	w := httptest.NewRecorder()
@@ -92,8 +123,7 @@ func TestZapLogger_ExtractsTraceID_FromB3(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, 200, w.Code)
 
-	entries := logs.All()
-	for _, e := range entries {
+	for _, e := range logs.All() {
 		if e.Message == "request completed" {
 			b, _ := json.Marshal(e.ContextMap())
 			var m map[string]interface{}
@@ -175,3 +205,69 @@ func TestZapLogger_DebugLogFiltering(t *testing.T) {
 		t.Fatalf("expected both debug and info logs at debug level (got debug=%v info=%v)", dbg, info)
 	}
 }
+
+func TestZapLogger_PropagatesIDsToRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, _ := testLogger()
+	r := gin.New()
+	r.Use(Tracing(sdktrace.NewTracerProvider(), propagation.TraceContext{}), ZapLogger(logger))
+	var gotReq, gotTrace string
+	r.GET("/ctx", func(c *gin.Context) {
+		gotReq = requestctx.RequestID(c.Request.Context())
+		gotTrace = requestctx.TraceID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ctx", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-42", gotReq)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", gotTrace)
+}
+
+func TestZapLogger_RequestScopedLoggerJoinsAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, logs := testLogger()
+	r := gin.New()
+	r.Use(Tracing(sdktrace.NewTracerProvider(), propagation.TraceContext{}), ZapLogger(logger))
+	r.GET("/deep", func(c *gin.Context) {
+		c.Set(ContextUserID, "u1")
+		ctx := requestctx.WithUser(c.Request.Context(), "u1", nil)
+		requestctx.Logger(ctx).Info("business event")
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "/deep", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 2) {
+		deep, access := entries[0].ContextMap(), entries[1].ContextMap()
+		assert.Equal(t, "business event", entries[0].Message)
+		for _, key := range []string{"request_id", "trace_id", "span_id", "user_id"} {
+			assert.NotEmpty(t, deep[key], key)
+			assert.Equal(t, access[key], deep[key], key)
+		}
+	}
+}
+
+func TestZapLogger_RedactsQueryString(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger, logs := testLogger()
+	r := gin.New()
+	r.Use(ZapLogger(logger))
+	r.GET("/cb", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req, _ := http.NewRequest("GET", "/cb?state=abc&access_token=s3cr3t&email=pat%40example.com&limit=10", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "state=abc&access_token=[REDACTED]&email=[REDACTED]&limit=10", entries[0].ContextMap()["query"])
+	}
+}
@@ -0,0 +1,192 @@
+// Package pagination turns list query strings into domain.PageRequest values and pages
+// into opaque, signed cursors (E-API-004).
+//
+// A list accepts only the filters and sort keys its Spec whitelists:
+//
+//	GET /api/v1/audit?actor_id=tech-42&sort=-occurred_at&limit=50
+//	GET /api/v1/audit?actor_id=tech-42&sort=-occurred_at&limit=50&cursor=<next_cursor>
+//
+// A leading "-" sorts descending. The cursor records the sort key and id of the last item
+// returned, plus a fingerprint of the sort and filters; it is HMAC signed so clients can
+// neither forge positions nor reuse a cursor with a different query.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// Reserved query parameters; everything else is a filter.
+const (
+	ParamSort   = "sort"
+	ParamLimit  = "limit"
+	ParamCursor = "cursor"
+)
+
+// Spec describes what a list endpoint accepts.
+type Spec struct {
+	// Filters are the query parameters the list may be filtered by.
+	Filters []string
+	// Sorts are the sort keys the list may be ordered by.
+	Sorts []string
+	// DefaultSort applies when the query has no sort, e.g. "-created_at".
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Query is a parsed list request.
+type Query struct {
+	// Filters holds the non-empty whitelisted filter parameters.
+	Filters map[string]string
+	Page    domain.PageRequest
+	// fingerprint binds cursors to the sort and filters they were issued for.
+	fingerprint string
+}
+
+// Request returns the page request to pass to the repository. It asks for one item more
+// than the page size so Next can tell whether another page follows.
+func (q Query) Request() domain.PageRequest {
+	r := q.Page
+	r.Limit++
+	return r
+}
+
+// cursor is the signed content of a next_cursor.
+type cursor struct {
+	Fingerprint string              `json:"f"`
+	After       domain.PagePosition `json:"a"`
+}
+
+// Codec parses list queries and signs cursors with an HMAC-SHA256 key.
+type Codec struct {
+	key []byte
+}
+
+// NewCodec returns a Codec signing with key. Cursors stay valid across restarts only when
+// the key does.
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: append([]byte(nil), key...)}
+}
+
+// Parse validates values against spec. Unknown parameters, sort keys outside the whitelist,
+// out-of-range limits and cursors that are forged or belong to another query are reported
+// as a domain validation error naming the offending parameters.
+func (c *Codec) Parse(values url.Values, spec Spec) (Query, error) {
+	var fields []domain.FieldError
+	invalid := func(field, msg string) {
+		fields = append(fields, domain.FieldError{Field: field, Message: msg})
+	}
+	q := Query{Filters: make(map[string]string), Page: domain.PageRequest{Limit: spec.DefaultLimit}}
+
+	for name := range values {
+		switch {
+		case name == ParamSort || name == ParamLimit || name == ParamCursor:
+		case slices.Contains(spec.Filters, name):
+			if v := values.Get(name); v != "" {
+				q.Filters[name] = v
+			}
+		default:
+			invalid(name, "is not a supported query parameter")
+		}
+	}
+
+	sort := values.Get(ParamSort)
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	q.Page.Sort, q.Page.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	if !slices.Contains(spec.Sorts, q.Page.Sort) {
+		invalid(ParamSort, "must be one of "+strings.Join(spec.Sorts, ", ")+", optionally prefixed with -")
+	}
+
+	if raw := values.Get(ParamLimit); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > spec.MaxLimit {
+			invalid(ParamLimit, fmt.Sprintf("must be an integer from 1 to %d", spec.MaxLimit))
+		}
+		q.Page.Limit = n
+	}
+
+	q.fingerprint = fingerprint(sort, q.Filters)
+	if raw := values.Get(ParamCursor); raw != "" {
+		cur, err := c.decode(raw)
+		switch {
+		case err != nil:
+			invalid(ParamCursor, "is not a valid cursor")
+		case cur.Fingerprint != q.fingerprint:
+			invalid(ParamCursor, "was issued for a different sort or filter")
+		default:
+			q.Page.After = &cur.After
+		}
+	}
+
+	if len(fields) > 0 {
+		slices.SortFunc(fields, func(a, b domain.FieldError) int { return strings.Compare(a.Field, b.Field) })
+		return Query{}, domain.Validation("", fields...)
+	}
+	return q, nil
+}
+
+// Next trims items, fetched with q.Request, to the page size and returns the cursor of the
+// following page, or "" on the last page.
+func Next[T domain.Pageable](c *Codec, q Query, items []T) ([]T, string) {
+	if q.Page.Limit <= 0 || len(items) <= q.Page.Limit {
+		return items, ""
+	}
+	items = items[:q.Page.Limit]
+	last := items[len(items)-1].PagePosition(q.Page.Sort)
+	return items, c.encode(cursor{Fingerprint: q.fingerprint, After: last})
+}
+
+func (c *Codec) encode(cur cursor) string {
+	payload, _ := json.Marshal(cur) // strings only; can not fail
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.mac(payload))
+}
+
+func (c *Codec) decode(s string) (cursor, error) {
+	var cur cursor
+	rawPayload, rawMAC, ok := strings.Cut(s, ".")
+	if !ok {
+		return cur, errors.New("malformed cursor")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(rawPayload)
+	if err != nil {
+		return cur, err
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(rawMAC)
+	if err != nil {
+		return cur, err
+	}
+	if !hmac.Equal(mac, c.mac(payload)) {
+		return cur, errors.New("cursor signature mismatch")
+	}
+	return cur, json.Unmarshal(payload, &cur)
+}
+
+func (c *Codec) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write(payload)
+	return h.Sum(nil)[:16]
+}
+
+// fingerprint hashes the sort and filters in a canonical order.
+func fingerprint(sort string, filters map[string]string) string {
+	h := sha256.New()
+	h.Write([]byte(sort))
+	for _, name := range slices.Sorted(maps.Keys(filters)) {
+		fmt.Fprintf(h, "\x00%s=%s", name, filters[name])
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:12])
+}
@@ -0,0 +1,99 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSpec = Spec{
+	Filters:      []string{"job_id"},
+	Sorts:        []string{domain.AlertSortCreatedAt, domain.AlertSortSeverity},
+	DefaultSort:  "-" + domain.AlertSortCreatedAt,
+	DefaultLimit: 2,
+	MaxLimit:     10,
+}
+
+func testAlerts() []domain.Alert {
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	return []domain.Alert{
+		{ID: "a", JobID: "j1", Severity: domain.SeverityWarning, CreatedAt: base},
+		{ID: "b", JobID: "j1", Severity: domain.SeverityCritical, CreatedAt: base},
+		{ID: "c", JobID: "j2", Severity: domain.SeverityWarning, CreatedAt: base.Add(time.Minute)},
+		{ID: "d", JobID: "j1", Severity: domain.SeverityCritical, CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "e", JobID: "j1", Severity: domain.SeverityInfo, CreatedAt: base.Add(3 * time.Minute)},
+	}
+}
+
+// walk pages through testAlerts with query and returns the ids in the order served.
+func walk(t *testing.T, c *Codec, query string) []string {
+	t.Helper()
+	var ids []string
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	for range 10 {
+		q, err := c.Parse(values, testSpec)
+		require.NoError(t, err)
+		page, next := Next(c, q, domain.Paginate(testAlerts(), q.Request()))
+		for _, a := range page {
+			ids = append(ids, a.ID)
+		}
+		if next == "" {
+			return ids
+		}
+		values.Set(ParamCursor, next)
+	}
+	t.Fatal("paging did not terminate")
+	return nil
+}
+
+func TestPaging_OrdersByKeyThenID(t *testing.T) {
+	c := NewCodec([]byte("k"))
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, walk(t, c, ""), "default sort, ties broken by id")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, walk(t, c, "sort=created_at&limit=1"))
+	assert.Equal(t, []string{"e", "a", "c", "b", "d"}, walk(t, c, "sort=severity&limit=3"), "by urgency, then time")
+}
+
+func TestParse_RejectsWhatSpecDoesNotAllow(t *testing.T) {
+	c := NewCodec([]byte("k"))
+	for query, field := range map[string]string{
+		"sort=value":     "sort",
+		"sort=-":         "sort",
+		"limit=0":        "limit",
+		"limit=11":       "limit",
+		"limit=ten":      "limit",
+		"severity=INFO":  "severity",
+		"cursor=abc":     "cursor",
+		"cursor=e30.AAA": "cursor",
+	} {
+		values, _ := url.ParseQuery(query)
+		_, err := c.Parse(values, testSpec)
+		require.ErrorIs(t, err, domain.ErrInvalidInput, query)
+		var de *domain.Error
+		require.True(t, errors.As(err, &de))
+		require.Len(t, de.Fields, 1, query)
+		assert.Equal(t, field, de.Fields[0].Field, query)
+	}
+}
+
+func TestCursor_IsSignedAndBoundToQuery(t *testing.T) {
+	c := NewCodec([]byte("k"))
+	q, err := c.Parse(url.Values{"job_id": {"j1"}}, testSpec)
+	require.NoError(t, err)
+	_, next := Next(c, q, domain.Paginate(testAlerts(), q.Request()))
+	require.NotEmpty(t, next)
+
+	_, err = c.Parse(url.Values{"job_id": {"j1"}, "cursor": {next}}, testSpec)
+	assert.NoError(t, err)
+	_, err = c.Parse(url.Values{"job_id": {"j2"}, "cursor": {next}}, testSpec)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "other filter")
+	_, err = c.Parse(url.Values{"job_id": {"j1"}, "sort": {"created_at"}, "cursor": {next}}, testSpec)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "other sort")
+	_, err = NewCodec([]byte("other key")).Parse(url.Values{"job_id": {"j1"}, "cursor": {next}}, testSpec)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "other key")
+}
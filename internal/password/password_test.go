@@ -0,0 +1,54 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams keep tests fast; production uses DefaultParams.
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	h, err := Hash("correct horse battery", testParams)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(h, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := Verify("correct horse battery", h)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = Verify("wrong horse battery", h)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	h2, err := Hash("correct horse battery", testParams)
+	require.NoError(t, err)
+	assert.NotEqual(t, h, h2, "salted")
+}
+
+func TestVerify_MalformedHash(t *testing.T) {
+	for _, h := range []string{"", "plain", "$2a$12$abc", "$argon2id$v=19$m=x$salt$key", "$argon2i$v=19$m=1,t=1,p=1$c2FsdA$a2V5"} {
+		_, err := Verify("pw", h)
+		assert.ErrorIs(t, err, ErrMalformedHash, h)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	h, err := Hash("correct horse battery", testParams)
+	require.NoError(t, err)
+	assert.False(t, NeedsRehash(h, testParams))
+	assert.True(t, NeedsRehash(h, DefaultParams))
+}
+
+func TestPolicy(t *testing.T) {
+	ok := []string{"correct horse battery", "Tr0ub4dor&3xyz"}
+	for _, pw := range ok {
+		assert.NoError(t, DefaultPolicy.Check("alice", pw), pw)
+	}
+	bad := []string{"short", "password1234", "my-alice-password", "aaaaaaaaaaaaaa", strings.Repeat("x", 129) + "y"}
+	for _, pw := range bad {
+		assert.ErrorIs(t, DefaultPolicy.Check("alice", pw), ErrPolicy, pw)
+	}
+}
@@ -0,0 +1,46 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrPolicy is wrapped by Policy.Check for every rejected password.
+var ErrPolicy = errors.New("password does not meet policy")
+
+// Policy is the password policy. It follows NIST SP 800-63B: a length floor and a blocklist
+// rather than composition rules.
+type Policy struct {
+	MinLength int
+	MaxLength int
+}
+
+// DefaultPolicy requires 12 to 128 characters.
+var DefaultPolicy = Policy{MinLength: 12, MaxLength: 128}
+
+// commonPasswords are rejected outright. The list is short on purpose; the length floor
+// already rules out most entries of public breach lists.
+var commonPasswords = map[string]bool{
+	"password1234": true, "passwordpassword": true, "123456789012": true, "qwertyuiopas": true,
+	"iloveyou1234": true, "letmein12345": true, "administrator": true, "poolmaintenance": true,
+}
+
+// Check returns an error wrapping ErrPolicy when pw is not acceptable for username.
+func (p Policy) Check(username, pw string) error {
+	n := utf8.RuneCountInString(pw)
+	switch {
+	case n < p.MinLength:
+		return fmt.Errorf("%w: must be at least %d characters", ErrPolicy, p.MinLength)
+	case p.MaxLength > 0 && n > p.MaxLength:
+		return fmt.Errorf("%w: must be at most %d characters", ErrPolicy, p.MaxLength)
+	case commonPasswords[strings.ToLower(pw)]:
+		return fmt.Errorf("%w: too common", ErrPolicy)
+	case username != "" && strings.Contains(strings.ToLower(pw), strings.ToLower(username)):
+		return fmt.Errorf("%w: must not contain the username", ErrPolicy)
+	case n > 1 && strings.Count(pw, string([]rune(pw)[0])) == n:
+		return fmt.Errorf("%w: must not be a single repeated character", ErrPolicy)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Package redact keeps secrets and personal data out of logs (E-SEC-007). A Redactor masks
+// the value of any field whose key names a secret, such as authorization or password, and
+// masks values that look like secrets or PII, such as JWTs, emails and card numbers,
+// wherever they appear in a logged string. NewCore applies it to every line a zap logger
+// writes; Query applies it to a URL query string, parameter by parameter.
+package redact
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// DefaultKeys are the field and query parameter names whose values are always masked. A key
+// matches when, lowercased and without "_" and "-", it contains one of them, so
+// "access_token", "X-Api-Secret" and "card_number" all match.
+var DefaultKeys = []string{"authorization", "token", "password", "passwd", "secret", "cookie", "cardnumber", "creditcard", "cvv"}
+
+// A Pattern finds a sensitive value inside a string.
+type Pattern struct {
+	Name string
+	Re   *regexp.Regexp
+	// Valid, when set, must accept a match before it is masked.
+	Valid func(match string) bool
+}
+
+// Patterns masked by default.
+var (
+	// JWT matches a compact JWS: three base64url segments, the header starting with {".
+	JWT = Pattern{Name: "jwt", Re: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)}
+	// Email matches an email address.
+	Email = Pattern{Name: "email", Re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)}
+	// CardNumber matches 13 to 19 digits, optionally grouped by spaces or dashes, that pass
+	// the Luhn check, so timestamps and other long numbers are left alone.
+	CardNumber = Pattern{Name: "card_number", Re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Valid: luhn}
+)
+
+// DefaultPatterns are the patterns Default masks.
+var DefaultPatterns = []Pattern{JWT, Email, CardNumber}
+
+// Redactor masks sensitive keys and values.
+type Redactor struct {
+	keys     []string
+	patterns []Pattern
+}
+
+// New returns a Redactor masking the values of keys and every match of patterns.
+func New(keys []string, patterns ...Pattern) *Redactor {
+	r := &Redactor{patterns: patterns}
+	for _, k := range keys {
+		if k = normalizeKey(k); k != "" {
+			r.keys = append(r.keys, k)
+		}
+	}
+	return r
+}
+
+// Default returns a Redactor for DefaultKeys and DefaultPatterns.
+func Default() *Redactor {
+	return New(DefaultKeys, DefaultPatterns...)
+}
+
+// SensitiveKey reports whether the value of a field or parameter named key is masked.
+func (r *Redactor) SensitiveKey(key string) bool {
+	key = normalizeKey(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns s with every pattern match masked.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = p.Re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.Valid != nil && !p.Valid(m) {
+				return m
+			}
+			return Mask
+		})
+	}
+	return s
+}
+
+// Query returns a raw URL query with the values of sensitive parameters masked and pattern
+// matches masked in the rest, so a token passed as ?access_token= never reaches a log.
+// Parameter order and escaping of the untouched parts are kept.
+func (r *Redactor) Query(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	params := strings.Split(raw, "&")
+	for i, param := range params {
+		rawKey, rawValue, hasValue := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		switch {
+		case !hasValue:
+			params[i] = r.String(key)
+		case r.SensitiveKey(key):
+			params[i] = rawKey + "=" + Mask
+		default:
+			value, err := url.QueryUnescape(rawValue)
+			if err != nil {
+				value = rawValue
+			}
+			if masked := r.String(value); masked != value {
+				params[i] = rawKey + "=" + masked
+			}
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// Field returns f with its value masked if its key is sensitive, or with pattern matches
+// masked in string, byte string, error and Stringer values. Objects, arrays and reflected
+// values are passed through; log secrets as typed fields, never inside them.
+func (r *Redactor) Field(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.NamespaceType, zapcore.SkipType:
+		return f
+	}
+	if r.SensitiveKey(f.Key) {
+		return zap.String(f.Key, Mask)
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = r.String(f.String)
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			return zap.String(f.Key, r.String(string(b)))
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			if msg := err.Error(); r.String(msg) != msg {
+				return zap.NamedError(f.Key, redactedError{r.String(msg)})
+			}
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return zap.String(f.Key, r.String(stringOf(s)))
+		}
+	}
+	return f
+}
+
+// Fields returns fields with Field applied to each; fields is not modified.
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = r.Field(f)
+	}
+	return out
+}
+
+// NewCore wraps c so every entry's message and fields, including those added with With,
+// are redacted before they are encoded. Install it with zap.WrapCore.
+func NewCore(c zapcore.Core, r *Redactor) zapcore.Core {
+	return &core{Core: c, r: r}
+}
+
+type core struct {
+	zapcore.Core
+	r *Redactor
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{Core: c.Core.With(c.r.Fields(fields)), r: c.r}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.String(ent.Message)
+	return c.Core.Write(ent, c.r.Fields(fields))
+}
+
+// redactedError stands in for an error whose message had to be masked.
+type redactedError struct{ msg string }
+
+func (e redactedError) Error() string { return e.msg }
+
+// stringOf calls s.String, treating a panic (typically a nil receiver) as "<nil>".
+func stringOf(s fmt.Stringer) (out string) {
+	defer func() {
+		if recover() != nil {
+			out = "<nil>"
+		}
+	}()
+	return s.String()
+}
+
+func normalizeKey(k string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(k))
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := s[i]
+		if d < '0' || d > '9' {
+			continue
+		}
+		v := int(d - '0')
+		if n%2 == 1 {
+			if v *= 2; v > 9 {
+				v -= 9
+			}
+		}
+		sum += v
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
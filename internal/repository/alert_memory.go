@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryAlertRepository is a process-local AlertRepository that joins InMemoryTxManager transactions.
+type InMemoryAlertRepository struct {
+	mu    sync.RWMutex
+	items map[string]domain.Alert
+}
+
+// NewInMemoryAlertRepository creates an empty alert store.
+func NewInMemoryAlertRepository() *InMemoryAlertRepository {
+	return &InMemoryAlertRepository{items: make(map[string]domain.Alert)}
+}
+
+// Create stores a new alert.
+func (r *InMemoryAlertRepository) Create(ctx context.Context, a *domain.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[a.ID] = *a
+	id := a.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.items, id)
+	})
+	return nil
+}
+
+// Get returns the alert with the given id or domain.ErrNotFound.
+func (r *InMemoryAlertRepository) Get(_ context.Context, id string) (*domain.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &a, nil
+}
+
+// Modify applies fn to the stored alert under the store's lock and saves the result unless
+// fn returns an error. A rolled back transaction restores the previous alert.
+func (r *InMemoryAlertRepository) Modify(ctx context.Context, id string, fn func(*domain.Alert) error) (*domain.Alert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	a := prev
+	if err := fn(&a); err != nil {
+		return nil, err
+	}
+	r.items[id] = a
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.items[id] = prev
+	})
+	return &a, nil
+}
+
+// List returns a page of the alerts matching f, newest first by default.
+func (r *InMemoryAlertRepository) List(_ context.Context, f domain.AlertFilter) ([]domain.Alert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.Alert, 0)
+	for _, a := range r.items {
+		if f.Matches(a) {
+			out = append(out, a)
+		}
+	}
+	return domain.Paginate(out, f.OrDefault(domain.AlertSortCreatedAt, true)), nil
+}
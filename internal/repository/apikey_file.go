@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// FileAPIKeyRepository is an APIKeyRepository backed by a JSON file, so issued keys survive
+// a restart. Like FileUserRepository it reads the file on every call and replaces it
+// atomically on every write; it suits the few keys one deployment issues.
+type FileAPIKeyRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+// apiKeyRecord is the stored form of a key. domain.APIKey never serialises its hash, so
+// that it can not leak through the API; the file needs it to authenticate.
+type apiKeyRecord struct {
+	domain.APIKey
+	KeyHash string `json:"key_hash"`
+}
+
+// NewFileAPIKeyRepository uses the file at path, which need not exist yet.
+func NewFileAPIKeyRepository(path string) *FileAPIKeyRepository {
+	return &FileAPIKeyRepository{path: path}
+}
+
+// Create stores a key; a duplicate id or hash returns domain.ErrConflict.
+func (r *FileAPIKeyRepository) Create(_ context.Context, k *domain.APIKey) error {
+	return r.modify(func(keys map[string]domain.APIKey) error { return createAPIKey(keys, k) })
+}
+
+// Get returns the key with the given id or domain.ErrNotFound.
+func (r *FileAPIKeyRepository) Get(_ context.Context, id string) (*domain.APIKey, error) {
+	keys, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return getAPIKey(keys, id)
+}
+
+// GetByHash returns the key with the given hash or domain.ErrNotFound.
+func (r *FileAPIKeyRepository) GetByHash(_ context.Context, hash string) (*domain.APIKey, error) {
+	keys, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return getAPIKeyByHash(keys, hash)
+}
+
+// Modify applies fn to the stored key while holding the file lock and saves the result
+// unless fn returns an error.
+func (r *FileAPIKeyRepository) Modify(_ context.Context, id string, fn func(*domain.APIKey) error) (*domain.APIKey, error) {
+	var out *domain.APIKey
+	err := r.modify(func(keys map[string]domain.APIKey) error {
+		k, err := modifyAPIKey(keys, id, fn)
+		out = k
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns every key, newest first.
+func (r *FileAPIKeyRepository) List(_ context.Context) ([]domain.APIKey, error) {
+	keys, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return listAPIKeys(keys), nil
+}
+
+func (r *FileAPIKeyRepository) read() (map[string]domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+func (r *FileAPIKeyRepository) modify(fn func(map[string]domain.APIKey) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys, err := r.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(keys); err != nil {
+		return err
+	}
+	return r.save(keys)
+}
+
+func (r *FileAPIKeyRepository) load() (map[string]domain.APIKey, error) {
+	var records []apiKeyRecord
+	if err := readJSONFile(r.path, "api key file", &records); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]domain.APIKey, len(records))
+	for _, rec := range records {
+		k := rec.APIKey
+		k.KeyHash = rec.KeyHash
+		keys[k.ID] = k
+	}
+	return keys, nil
+}
+
+func (r *FileAPIKeyRepository) save(keys map[string]domain.APIKey) error {
+	list := listAPIKeys(keys)
+	records := make([]apiKeyRecord, len(list))
+	for i, k := range list {
+		records[i] = apiKeyRecord{APIKey: k, KeyHash: k.KeyHash}
+	}
+	return writeJSONFile(r.path, "api key file", records)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileAPIKeyRepository_PersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "api_keys.json")
+	ctx := context.Background()
+	now := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	a := NewFileAPIKeyRepository(path)
+	keys, err := a.List(ctx)
+	require.NoError(t, err, "a missing file is an empty store")
+	assert.Empty(t, keys)
+
+	k := &domain.APIKey{ID: "k1", Prefix: "pmk_1a2b", KeyHash: "hash-1", Name: "Acme", Scopes: []string{domain.ScopeJobsRead}, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, a.Create(ctx, k))
+	assert.ErrorIs(t, a.Create(ctx, &domain.APIKey{ID: "k2", KeyHash: "hash-1"}), domain.ErrConflict)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	b := NewFileAPIKeyRepository(path)
+	got, err := b.GetByHash(ctx, "hash-1")
+	require.NoError(t, err, "the hash survives, so the key still authenticates after a restart")
+	assert.Equal(t, "k1", got.ID)
+	assert.Equal(t, []string{domain.ScopeJobsRead}, got.Scopes)
+
+	_, err = b.Modify(ctx, got.ID, func(k *domain.APIKey) error {
+		k.RevokedAt = &now
+		return nil
+	})
+	require.NoError(t, err)
+	again, err := a.Get(ctx, "k1")
+	require.NoError(t, err)
+	assert.NotNil(t, again.RevokedAt, "writes through one instance are visible to the other")
+
+	_, err = b.Modify(ctx, "nope", func(*domain.APIKey) error { return nil })
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = b.GetByHash(ctx, "nope")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryAPIKeyRepository is a process-local APIKeyRepository.
+type InMemoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+}
+
+// NewInMemoryAPIKeyRepository creates an empty API key store.
+func NewInMemoryAPIKeyRepository() *InMemoryAPIKeyRepository {
+	return &InMemoryAPIKeyRepository{keys: make(map[string]domain.APIKey)}
+}
+
+// Create stores a key; a duplicate id or hash returns domain.ErrConflict.
+func (r *InMemoryAPIKeyRepository) Create(_ context.Context, k *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return createAPIKey(r.keys, k)
+}
+
+// Get returns the key with the given id or domain.ErrNotFound.
+func (r *InMemoryAPIKeyRepository) Get(_ context.Context, id string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return getAPIKey(r.keys, id)
+}
+
+// GetByHash returns the key with the given hash or domain.ErrNotFound.
+func (r *InMemoryAPIKeyRepository) GetByHash(_ context.Context, hash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return getAPIKeyByHash(r.keys, hash)
+}
+
+// Modify applies fn to the stored key under the store's lock and saves the result unless fn
+// returns an error.
+func (r *InMemoryAPIKeyRepository) Modify(_ context.Context, id string, fn func(*domain.APIKey) error) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return modifyAPIKey(r.keys, id, fn)
+}
+
+// List returns every key, newest first.
+func (r *InMemoryAPIKeyRepository) List(_ context.Context) ([]domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return listAPIKeys(r.keys), nil
+}
+
+// The helpers below are shared with FileAPIKeyRepository, which applies them to the map it
+// loads from disk.
+
+func createAPIKey(keys map[string]domain.APIKey, k *domain.APIKey) error {
+	for _, existing := range keys {
+		if existing.ID == k.ID || existing.KeyHash == k.KeyHash {
+			return domain.ErrConflict
+		}
+	}
+	keys[k.ID] = cloneAPIKey(*k)
+	return nil
+}
+
+func getAPIKey(keys map[string]domain.APIKey, id string) (*domain.APIKey, error) {
+	k, ok := keys[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	k = cloneAPIKey(k)
+	return &k, nil
+}
+
+func getAPIKeyByHash(keys map[string]domain.APIKey, hash string) (*domain.APIKey, error) {
+	for _, k := range keys {
+		if k.KeyHash == hash {
+			k = cloneAPIKey(k)
+			return &k, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func modifyAPIKey(keys map[string]domain.APIKey, id string, fn func(*domain.APIKey) error) (*domain.APIKey, error) {
+	stored, ok := keys[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	k := cloneAPIKey(stored)
+	if err := fn(&k); err != nil {
+		return nil, err
+	}
+	k.ID = id
+	keys[id] = cloneAPIKey(k)
+	return &k, nil
+}
+
+func listAPIKeys(keys map[string]domain.APIKey) []domain.APIKey {
+	out := make([]domain.APIKey, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, cloneAPIKey(k))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+func cloneAPIKey(k domain.APIKey) domain.APIKey {
+	k.Scopes = append([]string(nil), k.Scopes...)
+	if k.LastUsedAt != nil {
+		t := *k.LastUsedAt
+		k.LastUsedAt = &t
+	}
+	if k.RevokedAt != nil {
+		t := *k.RevokedAt
+		k.RevokedAt = &t
+	}
+	return k
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+)
+
+// InMemoryAuditRepository is a process-local, append-only AuditRepository. Events are kept
+// in insertion order and never modified.
+type InMemoryAuditRepository struct {
+	mu     sync.RWMutex
+	events []domain.AuditEvent
+	ids    map[string]bool
+}
+
+// NewInMemoryAuditRepository creates an empty audit log.
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{ids: make(map[string]bool)}
+}
+
+// Append links e to the chain head and adds it to the log, or returns domain.ErrConflict if
+// its id is already present. Sequence, PrevHash, Hash and HashVersion are set on e.
+func (r *InMemoryAuditRepository) Append(_ context.Context, e *domain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ids[e.ID] {
+		return domain.ErrConflict
+	}
+	e.HashVersion = domain.ChainHashV1
+	e.Sequence, e.PrevHash = int64(len(r.events))+1, hashchain.Genesis
+	if n := len(r.events); n > 0 {
+		e.PrevHash = r.events[n-1].Hash
+	}
+	content, err := e.ChainContent()
+	if err != nil {
+		return fmt.Errorf("encode audit event: %w", err)
+	}
+	e.Hash = hashchain.Compute(e.PrevHash, content)
+	ev := *e
+	ev.Metadata = append([]byte(nil), e.Metadata...)
+	r.events = append(r.events, ev)
+	r.ids[ev.ID] = true
+	return nil
+}
+
+// Query returns a page of matching events, newest first unless f asks for another order.
+func (r *InMemoryAuditRepository) Query(_ context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.AuditEvent, 0)
+	for _, ev := range r.events {
+		if f.Matches(ev) {
+			out = append(out, ev)
+		}
+	}
+	out = domain.Paginate(out, f.OrDefault(domain.AuditSortOccurredAt, true))
+	for i := range out {
+		out[i].Metadata = append([]byte(nil), out[i].Metadata...)
+	}
+	return out, nil
+}
+
+// Head returns the newest link of the audit chain.
+func (r *InMemoryAuditRepository) Head(_ context.Context) (domain.ChainHead, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.events) == 0 {
+		return domain.ChainHead{Hash: hashchain.Genesis}, nil
+	}
+	last := r.events[len(r.events)-1]
+	return domain.ChainHead{Sequence: last.Sequence, Hash: last.Hash}, nil
+}
+
+// ListChain returns every event in sequence order.
+func (r *InMemoryAuditRepository) ListChain(_ context.Context) ([]domain.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.AuditEvent, len(r.events))
+	for i, ev := range r.events {
+		ev.Metadata = append([]byte(nil), ev.Metadata...)
+		out[i] = ev
+	}
+	return out, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryCheckpointRepository is a process-local, append-only CheckpointRepository.
+type InMemoryCheckpointRepository struct {
+	mu    sync.RWMutex
+	items []domain.ChainCheckpoint
+}
+
+// NewInMemoryCheckpointRepository creates an empty checkpoint store.
+func NewInMemoryCheckpointRepository() *InMemoryCheckpointRepository {
+	return &InMemoryCheckpointRepository{}
+}
+
+// Append stores a checkpoint.
+func (r *InMemoryCheckpointRepository) Append(_ context.Context, cp *domain.ChainCheckpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *cp
+	c.Signature = append([]byte(nil), cp.Signature...)
+	r.items = append(r.items, c)
+	return nil
+}
+
+// List returns the checkpoints of chain, oldest first.
+func (r *InMemoryCheckpointRepository) List(_ context.Context, chain string) ([]domain.ChainCheckpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.ChainCheckpoint, 0)
+	for _, c := range r.items {
+		if c.Chain == chain {
+			c.Signature = append([]byte(nil), c.Signature...)
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
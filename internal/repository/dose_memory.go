@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+)
+
+// InMemoryDoseEventRepository is a process-local DoseEventRepository that joins
+// InMemoryTxManager transactions. Doses are kept in chain order; because transactions are
+// serialized, a rolled-back dose is always the chain head when it is removed.
+type InMemoryDoseEventRepository struct {
+	mu    sync.RWMutex
+	chain []domain.DoseEvent
+}
+
+// NewInMemoryDoseEventRepository creates an empty dose event store.
+func NewInMemoryDoseEventRepository() *InMemoryDoseEventRepository {
+	return &InMemoryDoseEventRepository{}
+}
+
+// Create links ev to the chain head and stores it. Sequence, PrevHash, Hash and HashVersion
+// are set on ev.
+func (r *InMemoryDoseEventRepository) Create(ctx context.Context, ev *domain.DoseEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ev.HashVersion = domain.ChainHashV1
+	ev.Sequence, ev.PrevHash = int64(len(r.chain))+1, hashchain.Genesis
+	if n := len(r.chain); n > 0 {
+		ev.PrevHash = r.chain[n-1].Hash
+	}
+	content, err := ev.ChainContent()
+	if err != nil {
+		return fmt.Errorf("encode dose event: %w", err)
+	}
+	ev.Hash = hashchain.Compute(ev.PrevHash, content)
+	r.chain = append(r.chain, *ev)
+	id := ev.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i := len(r.chain) - 1; i >= 0; i-- {
+			if r.chain[i].ID == id {
+				r.chain = append(r.chain[:i], r.chain[i+1:]...)
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// Get returns the dose with the given id or domain.ErrNotFound.
+func (r *InMemoryDoseEventRepository) Get(_ context.Context, id string) (*domain.DoseEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range r.chain {
+		if r.chain[i].ID == id {
+			ev := r.chain[i]
+			return &ev, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// ListByJob returns a page of the doses recorded for a job, oldest first by default.
+func (r *InMemoryDoseEventRepository) ListByJob(_ context.Context, jobID string, page domain.PageRequest) ([]domain.DoseEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.DoseEvent, 0)
+	for _, ev := range r.chain {
+		if ev.JobID == jobID {
+			out = append(out, ev)
+		}
+	}
+	return domain.Paginate(out, page.OrDefault(domain.DoseSortCreatedAt, false)), nil
+}
+
+// Head returns the newest link of the dose chain.
+func (r *InMemoryDoseEventRepository) Head(_ context.Context) (domain.ChainHead, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.chain) == 0 {
+		return domain.ChainHead{Hash: hashchain.Genesis}, nil
+	}
+	last := r.chain[len(r.chain)-1]
+	return domain.ChainHead{Sequence: last.Sequence, Hash: last.Hash}, nil
+}
+
+// ListChain returns every dose in sequence order.
+func (r *InMemoryDoseEventRepository) ListChain(_ context.Context) ([]domain.DoseEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]domain.DoseEvent(nil), r.chain...), nil
+}
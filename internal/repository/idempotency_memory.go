@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// idempotencyPruneInterval bounds how often Create sweeps expired records.
+const idempotencyPruneInterval = time.Minute
+
+// InMemoryIdempotencyRepository is a process-local IdempotencyRepository. Expired records
+// are swept on Create, at most once per idempotencyPruneInterval.
+type InMemoryIdempotencyRepository struct {
+	mu        sync.Mutex
+	items     map[string]domain.IdempotencyRecord
+	lastPrune time.Time
+}
+
+// NewInMemoryIdempotencyRepository creates an empty idempotency store.
+func NewInMemoryIdempotencyRepository() *InMemoryIdempotencyRepository {
+	return &InMemoryIdempotencyRepository{items: make(map[string]domain.IdempotencyRecord)}
+}
+
+// Create stores rec unless an unexpired record with its key exists.
+func (r *InMemoryIdempotencyRepository) Create(_ context.Context, rec *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := rec.CreatedAt
+	if now.Sub(r.lastPrune) >= idempotencyPruneInterval {
+		for k, v := range r.items {
+			if !now.Before(v.ExpiresAt) {
+				delete(r.items, k)
+			}
+		}
+		r.lastPrune = now
+	}
+	if old, ok := r.items[rec.Key]; ok && now.Before(old.ExpiresAt) {
+		return domain.ErrConflict
+	}
+	r.items[rec.Key] = cloneIdempotencyRecord(*rec)
+	return nil
+}
+
+// Get returns the record with the given key or domain.ErrNotFound.
+func (r *InMemoryIdempotencyRepository) Get(_ context.Context, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.items[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	rec = cloneIdempotencyRecord(rec)
+	return &rec, nil
+}
+
+// Update replaces a record or returns domain.ErrNotFound.
+func (r *InMemoryIdempotencyRepository) Update(_ context.Context, rec *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[rec.Key]; !ok {
+		return domain.ErrNotFound
+	}
+	r.items[rec.Key] = cloneIdempotencyRecord(*rec)
+	return nil
+}
+
+// Delete removes a record.
+func (r *InMemoryIdempotencyRepository) Delete(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, key)
+	return nil
+}
+
+func cloneIdempotencyRecord(rec domain.IdempotencyRecord) domain.IdempotencyRecord {
+	rec.Body = append([]byte(nil), rec.Body...)
+	rec.Header = maps.Clone(rec.Header)
+	return rec
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryInventoryRepository is a process-local InventoryRepository.
+type InMemoryInventoryRepository struct {
+	mu      sync.RWMutex
+	items   map[string]domain.InventoryItem
+	applied map[string]bool
+}
+
+// NewInMemoryInventoryRepository creates an empty stock ledger.
+func NewInMemoryInventoryRepository() *InMemoryInventoryRepository {
+	return &InMemoryInventoryRepository{
+		items:   make(map[string]domain.InventoryItem),
+		applied: make(map[string]bool),
+	}
+}
+
+// Get returns the stock of a product or domain.ErrNotFound.
+func (r *InMemoryInventoryRepository) Get(_ context.Context, productID string) (*domain.InventoryItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	item, ok := r.items[productID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &item, nil
+}
+
+// ApplyMovement adjusts stock once per movement id. Unknown products start at zero, so
+// consumption of untracked stock shows up as a negative balance rather than being lost.
+func (r *InMemoryInventoryRepository) ApplyMovement(_ context.Context, m domain.InventoryMovement) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.applied[m.ID] {
+		return false, nil
+	}
+	item, ok := r.items[m.ProductID]
+	if !ok {
+		item = domain.InventoryItem{ProductID: m.ProductID, Unit: m.Unit}
+	}
+	item.OnHand += m.Quantity
+	item.UpdatedAt = m.OccurredAt
+	r.items[m.ProductID] = item
+	r.applied[m.ID] = true
+	return true, nil
+}
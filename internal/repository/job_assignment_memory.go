@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryJobAssignmentRepository is a process-local JobAssignmentRepository.
+type InMemoryJobAssignmentRepository struct {
+	mu    sync.RWMutex
+	techs map[string]string
+}
+
+// NewInMemoryJobAssignmentRepository creates an empty assignment store.
+func NewInMemoryJobAssignmentRepository() *InMemoryJobAssignmentRepository {
+	return &InMemoryJobAssignmentRepository{techs: make(map[string]string)}
+}
+
+// Assign routes jobID to technicianID, replacing any previous assignment.
+func (r *InMemoryJobAssignmentRepository) Assign(ctx context.Context, jobID, technicianID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, had := r.techs[jobID]
+	r.techs[jobID] = technicianID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if had {
+			r.techs[jobID] = prev
+		} else {
+			delete(r.techs, jobID)
+		}
+	})
+	return nil
+}
+
+// AssignedTechnician returns the technician for jobID or domain.ErrNotFound.
+func (r *InMemoryJobAssignmentRepository) AssignedTechnician(_ context.Context, jobID string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tech, ok := r.techs[jobID]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+	return tech, nil
+}
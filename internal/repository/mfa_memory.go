@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryMFAChallengeRepository is a process-local MFAChallengeRepository.
+type InMemoryMFAChallengeRepository struct {
+	mu    sync.Mutex
+	items map[string]domain.MFAChallenge
+}
+
+// NewInMemoryMFAChallengeRepository creates an empty challenge store.
+func NewInMemoryMFAChallengeRepository() *InMemoryMFAChallengeRepository {
+	return &InMemoryMFAChallengeRepository{items: make(map[string]domain.MFAChallenge)}
+}
+
+// Create stores a challenge; a duplicate id returns domain.ErrConflict.
+func (r *InMemoryMFAChallengeRepository) Create(_ context.Context, c *domain.MFAChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[c.ID]; ok {
+		return domain.ErrConflict
+	}
+	r.items[c.ID] = *c
+	return nil
+}
+
+// GetByHash returns the challenge with the given token hash or domain.ErrNotFound.
+func (r *InMemoryMFAChallengeRepository) GetByHash(_ context.Context, hash string) (*domain.MFAChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.items {
+		if c.TokenHash == hash {
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// Modify applies fn to the stored challenge under the store's lock and saves the result
+// unless fn returns an error.
+func (r *InMemoryMFAChallengeRepository) Modify(_ context.Context, id string, fn func(*domain.MFAChallenge) error) (*domain.MFAChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if err := fn(&c); err != nil {
+		return nil, err
+	}
+	r.items[id] = c
+	return &c, nil
+}
+
+// Delete removes a challenge.
+func (r *InMemoryMFAChallengeRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, id)
+	return nil
+}
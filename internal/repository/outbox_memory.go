@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryOutboxRepository is a process-local OutboxRepository that joins InMemoryTxManager
+// transactions. A message added in a transaction is stored only when the transaction commits.
+type InMemoryOutboxRepository struct {
+	mu    sync.RWMutex
+	items map[string]domain.OutboxMessage
+}
+
+// NewInMemoryOutboxRepository creates an empty outbox.
+func NewInMemoryOutboxRepository() *InMemoryOutboxRepository {
+	return &InMemoryOutboxRepository{items: make(map[string]domain.OutboxMessage)}
+}
+
+// Add stores a new message, or stages it until the caller's transaction commits.
+func (r *InMemoryOutboxRepository) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	staged := cloneOutboxMessage(*msg)
+	onCommit(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.items[staged.ID] = staged
+	})
+	return nil
+}
+
+// ListUnpublished returns messages without PublishedAt, oldest first.
+func (r *InMemoryOutboxRepository) ListUnpublished(_ context.Context, limit int) ([]domain.OutboxMessage, error) {
+	return r.list(limit, func(m domain.OutboxMessage) bool { return m.PublishedAt == nil }), nil
+}
+
+// ListDue returns the unpublished, live messages whose retry time has come, oldest first.
+func (r *InMemoryOutboxRepository) ListDue(_ context.Context, now time.Time, limit int) ([]domain.OutboxMessage, error) {
+	return r.list(limit, func(m domain.OutboxMessage) bool {
+		return m.PublishedAt == nil && m.DeadLetteredAt == nil && (m.NextAttemptAt == nil || !m.NextAttemptAt.After(now))
+	}), nil
+}
+
+func (r *InMemoryOutboxRepository) list(limit int, keep func(domain.OutboxMessage) bool) []domain.OutboxMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.OutboxMessage, 0)
+	for _, m := range r.items {
+		if keep(m) {
+			out = append(out, cloneOutboxMessage(m))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Update replaces an existing message.
+func (r *InMemoryOutboxRepository) Update(_ context.Context, msg *domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[msg.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	r.items[msg.ID] = cloneOutboxMessage(*msg)
+	return nil
+}
+
+func cloneOutboxMessage(m domain.OutboxMessage) domain.OutboxMessage {
+	m.Event.Data = append([]byte(nil), m.Event.Data...)
+	delivered := make([]string, len(m.DeliveredTo))
+	copy(delivered, m.DeliveredTo)
+	m.DeliveredTo = delivered
+	return m
+}
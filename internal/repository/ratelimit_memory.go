@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// rateLimitPruneInterval bounds how often Take sweeps buckets that have refilled.
+const rateLimitPruneInterval = time.Minute
+
+// InMemoryRateLimitStore is a process-local RateLimitStore. Each replica enforces its own
+// budget, so it only suits a single replica. Full buckets carry no state and are swept at
+// most once per rateLimitPruneInterval.
+type InMemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// NewInMemoryRateLimitStore creates an empty rate limit store.
+func NewInMemoryRateLimitStore() *InMemoryRateLimitStore {
+	return &InMemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// Take refills the bucket for key and takes a token if one is available.
+func (s *InMemoryRateLimitStore) Take(_ context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPrune) >= rateLimitPruneInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	burst := float64(limit.Burst)
+	perToken := limit.Period.Seconds() / burst
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed/perToken)
+		b.updated = now
+	}
+
+	res := domain.RateLimitResult{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) * perToken)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((burst - b.tokens) * perToken)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
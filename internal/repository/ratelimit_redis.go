@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// takeTokenScript refills and takes from one bucket atomically. The bucket is a hash of
+// tokens and the time, in microseconds, they were counted at; it expires once it would be
+// full again, so idle principals leave nothing behind. It returns whether a token was taken
+// and the tokens left, as a string because Redis truncates Lua numbers to integers.
+var takeTokenScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local per_token = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+	tokens = burst
+	updated = now
+end
+if now > updated then
+	tokens = math.min(burst, tokens + (now - updated) / per_token)
+	updated = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(updated))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * per_token / 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore is a RateLimitStore in Redis. Every replica shares its buckets, so a
+// budget holds across all of them. Keys are prefixed so the store can share a database.
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisRateLimitStore creates a store that keeps buckets in client under prefix.
+func NewRedisRateLimitStore(client redis.Scripter, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: prefix}
+}
+
+// Take refills the bucket for key and takes a token if one is available.
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitResult, error) {
+	burst := float64(limit.Burst)
+	perToken := limit.Period.Seconds() / burst
+	vals, err := takeTokenScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Burst, strconv.FormatFloat(perToken*1e6, 'f', -1, 64), now.UnixMicro()).Slice()
+	if err != nil {
+		return domain.RateLimitResult{}, fmt.Errorf("take token: %w", err)
+	}
+	if len(vals) != 2 {
+		return domain.RateLimitResult{}, fmt.Errorf("take token: unexpected reply %v", vals)
+	}
+	allowed, _ := vals[0].(int64)
+	left, _ := vals[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return domain.RateLimitResult{}, fmt.Errorf("take token: parse tokens: %w", err)
+	}
+
+	res := domain.RateLimitResult{Limit: limit.Burst, Allowed: allowed == 1, Remaining: int(tokens)}
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - tokens) * perToken)
+	}
+	res.Reset = seconds((burst - tokens) * perToken)
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return srv, client
+}
+
+func TestRedisRateLimitStore_MatchesInMemoryStore(t *testing.T) {
+	_, client := newTestRedis(t)
+	shared := NewRedisRateLimitStore(client, "rl:")
+	local := NewInMemoryRateLimitStore()
+	limit := domain.RateLimit{Name: "test", Burst: 3, Period: 3 * time.Second}
+	start := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for _, at := range []time.Duration{0, 0, 0, 0, 500 * time.Millisecond, time.Second, 10 * time.Second} {
+		want, err := local.Take(ctx, "k", limit, start.Add(at))
+		require.NoError(t, err)
+		got, err := shared.Take(ctx, "k", limit, start.Add(at))
+		require.NoError(t, err)
+		assert.Equal(t, want.Allowed, got.Allowed, "at %s", at)
+		assert.Equal(t, want.Remaining, got.Remaining, "at %s", at)
+		assert.InDelta(t, want.RetryAfter, got.RetryAfter, float64(time.Millisecond), "at %s", at)
+		assert.InDelta(t, want.Reset, got.Reset, float64(time.Millisecond), "at %s", at)
+	}
+}
+
+func TestRedisRateLimitStore_SharesBudgetAcrossReplicas(t *testing.T) {
+	srv, client := newTestRedis(t)
+	other := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = other.Close() })
+	replicaA := NewRedisRateLimitStore(client, "rl:")
+	replicaB := NewRedisRateLimitStore(other, "rl:")
+	limit := domain.RateLimit{Name: "test", Burst: 2, Period: time.Minute}
+	now := time.Now()
+	ctx := context.Background()
+
+	res, err := replicaA.Take(ctx, "k", limit, now)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	res, err = replicaB.Take(ctx, "k", limit, now)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	res, err = replicaA.Take(ctx, "k", limit, now)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "the budget is spent across both replicas")
+	assert.Equal(t, 30*time.Second, res.RetryAfter)
+
+	ttl := srv.TTL("rl:k")
+	assert.Greater(t, ttl, time.Duration(0), "buckets expire once they would be full")
+	assert.LessOrEqual(t, ttl, time.Minute+time.Millisecond)
+}
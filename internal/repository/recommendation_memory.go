@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryDoseRecommendationRepository is a process-local DoseRecommendationRepository.
+type InMemoryDoseRecommendationRepository struct {
+	mu    sync.RWMutex
+	items map[string]domain.DoseRecommendation
+}
+
+// NewInMemoryDoseRecommendationRepository creates an empty recommendation store.
+func NewInMemoryDoseRecommendationRepository() *InMemoryDoseRecommendationRepository {
+	return &InMemoryDoseRecommendationRepository{items: make(map[string]domain.DoseRecommendation)}
+}
+
+// Create stores a recommendation.
+func (r *InMemoryDoseRecommendationRepository) Create(_ context.Context, rec *domain.DoseRecommendation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[rec.ID] = cloneRecommendation(*rec)
+	return nil
+}
+
+// Get returns the recommendation with the given id or domain.ErrNotFound.
+func (r *InMemoryDoseRecommendationRepository) Get(_ context.Context, id string) (*domain.DoseRecommendation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	rec = cloneRecommendation(rec)
+	return &rec, nil
+}
+
+func cloneRecommendation(r domain.DoseRecommendation) domain.DoseRecommendation {
+	r.Inputs.Readings = maps.Clone(r.Inputs.Readings)
+	r.Inputs.Targets = maps.Clone(r.Inputs.Targets)
+	r.Outputs = append([]domain.RecommendedDose(nil), r.Outputs...)
+	r.Signature = append([]byte(nil), r.Signature...)
+	return r
+}
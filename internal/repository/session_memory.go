@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryRefreshTokenRepository is a process-local RefreshTokenRepository.
+type InMemoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	byID   map[string]*domain.RefreshToken
+	byHash map[string]string
+}
+
+// NewInMemoryRefreshTokenRepository creates an empty refresh token store.
+func NewInMemoryRefreshTokenRepository() *InMemoryRefreshTokenRepository {
+	return &InMemoryRefreshTokenRepository{
+		byID:   make(map[string]*domain.RefreshToken),
+		byHash: make(map[string]string),
+	}
+}
+
+// Create stores a token; a duplicate id or hash returns domain.ErrConflict.
+func (r *InMemoryRefreshTokenRepository) Create(_ context.Context, t *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byID[t.ID]; ok {
+		return domain.ErrConflict
+	}
+	if _, ok := r.byHash[t.TokenHash]; ok {
+		return domain.ErrConflict
+	}
+	cp := *t
+	r.byID[t.ID] = &cp
+	r.byHash[t.TokenHash] = t.ID
+	return nil
+}
+
+// GetByHash returns the token with the given hash or domain.ErrNotFound.
+func (r *InMemoryRefreshTokenRepository) GetByHash(_ context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *r.byID[id]
+	return &cp, nil
+}
+
+// MarkUsed sets UsedAt if the token has not been used yet.
+func (r *InMemoryRefreshTokenRepository) MarkUsed(_ context.Context, id string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.byID[id]
+	if !ok {
+		return false, domain.ErrNotFound
+	}
+	if t.UsedAt != nil {
+		return false, nil
+	}
+	t.UsedAt = &at
+	return true, nil
+}
+
+// RevokeFamily revokes every unrevoked token in a family.
+func (r *InMemoryRefreshTokenRepository) RevokeFamily(_ context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.byID {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+// RevokeUser revokes every unrevoked token of a user.
+func (r *InMemoryRefreshTokenRepository) RevokeUser(_ context.Context, userID string, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	families := make(map[string]struct{})
+	for _, t := range r.byID {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+			families[t.FamilyID] = struct{}{}
+		}
+	}
+	return len(families), nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemorySSOLoginStateRepository is a process-local SSOLoginStateRepository.
+type InMemorySSOLoginStateRepository struct {
+	mu    sync.Mutex
+	items map[string]domain.SSOLoginState
+}
+
+// NewInMemorySSOLoginStateRepository creates an empty login state store.
+func NewInMemorySSOLoginStateRepository() *InMemorySSOLoginStateRepository {
+	return &InMemorySSOLoginStateRepository{items: make(map[string]domain.SSOLoginState)}
+}
+
+// Create stores a login; a duplicate state hash returns domain.ErrConflict.
+func (r *InMemorySSOLoginStateRepository) Create(_ context.Context, s *domain.SSOLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[s.StateHash]; ok {
+		return domain.ErrConflict
+	}
+	r.items[s.StateHash] = *s
+	return nil
+}
+
+// Take returns and removes the login with the given state hash or domain.ErrNotFound.
+func (r *InMemorySSOLoginStateRepository) Take(_ context.Context, stateHash string) (*domain.SSOLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.items[stateHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(r.items, stateHash)
+	return &s, nil
+}
+
+// InMemorySSOHandoffRepository is a process-local SSOHandoffRepository.
+type InMemorySSOHandoffRepository struct {
+	mu    sync.Mutex
+	items map[string]domain.SSOHandoff
+}
+
+// NewInMemorySSOHandoffRepository creates an empty handoff store.
+func NewInMemorySSOHandoffRepository() *InMemorySSOHandoffRepository {
+	return &InMemorySSOHandoffRepository{items: make(map[string]domain.SSOHandoff)}
+}
+
+// Create stores a handoff; a duplicate code hash returns domain.ErrConflict.
+func (r *InMemorySSOHandoffRepository) Create(_ context.Context, h *domain.SSOHandoff) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[h.CodeHash]; ok {
+		return domain.ErrConflict
+	}
+	r.items[h.CodeHash] = *h
+	return nil
+}
+
+// Take returns and removes the handoff with the given code hash or domain.ErrNotFound.
+func (r *InMemorySSOHandoffRepository) Take(_ context.Context, codeHash string) (*domain.SSOHandoff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.items[codeHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(r.items, codeHash)
+	return &h, nil
+}
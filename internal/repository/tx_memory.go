@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryTxManager gives the in-memory repositories all-or-nothing writes. Transactions are
+// serialized, and every write made with a transactional ctx registers an undo step that runs
+// if the transaction function fails. Most repositories get atomicity only, not isolation from
+// readers; the outbox stages its writes until commit, so the relay never sees an event whose
+// transaction may still roll back.
+type InMemoryTxManager struct {
+	mu sync.Mutex
+}
+
+// NewInMemoryTxManager creates a transaction manager for the in-memory repositories.
+func NewInMemoryTxManager() *InMemoryTxManager {
+	return &InMemoryTxManager{}
+}
+
+type memTxKey struct{}
+
+type memTx struct {
+	undo   []func()
+	commit []func()
+}
+
+// WithinTx runs fn in a transaction, rolling back every registered write if fn returns an error
+// or panics. Nested calls join the outer transaction.
+func (m *InMemoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(memTxKey{}).(*memTx); ok {
+		return fn(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &memTx{}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.rollback()
+			return
+		}
+		for _, apply := range tx.commit {
+			apply()
+		}
+	}()
+	return fn(context.WithValue(ctx, memTxKey{}, tx))
+}
+
+func (tx *memTx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+}
+
+// onCommit runs apply when the transaction carried by ctx commits, or at once outside a
+// transaction. Repositories use it for writes that must stay invisible until commit.
+func onCommit(ctx context.Context, apply func()) {
+	if tx, ok := ctx.Value(memTxKey{}).(*memTx); ok {
+		tx.commit = append(tx.commit, apply)
+		return
+	}
+	apply()
+}
+
+// onRollback registers undo with the transaction carried by ctx, if any. Repositories call it
+// after each write; outside a transaction writes are simply final.
+func onRollback(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(memTxKey{}).(*memTx); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// FileUserRepository is a UserRepository backed by a JSON file, so the `users` CLI can
+// manage accounts while the server is stopped and the server sees the result. The file is
+// read on every call and replaced atomically on every write; it is meant for the handful of
+// accounts a single deployment has, not as a general database. Concurrent writers in
+// different processes are not coordinated.
+type FileUserRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileUserRepository uses the file at path, which need not exist yet.
+func NewFileUserRepository(path string) *FileUserRepository {
+	return &FileUserRepository{path: path}
+}
+
+// Create stores a new user; a duplicate id or username returns domain.ErrConflict.
+func (r *FileUserRepository) Create(_ context.Context, u *domain.User) error {
+	return r.modify(func(users map[string]domain.User) error { return createUser(users, u) })
+}
+
+// Get returns the user with the given id or domain.ErrNotFound.
+func (r *FileUserRepository) Get(_ context.Context, id string) (*domain.User, error) {
+	users, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return getUser(users, id)
+}
+
+// GetByUsername returns the user with the given username or domain.ErrNotFound.
+func (r *FileUserRepository) GetByUsername(_ context.Context, username string) (*domain.User, error) {
+	users, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return getUserByUsername(users, username)
+}
+
+// GetByExternalID returns the user provisioned for issuer and subject or domain.ErrNotFound.
+func (r *FileUserRepository) GetByExternalID(_ context.Context, issuer, subject string) (*domain.User, error) {
+	users, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return getUserByExternalID(users, issuer, subject)
+}
+
+// Update replaces an existing user or returns domain.ErrNotFound.
+func (r *FileUserRepository) Update(_ context.Context, u *domain.User) error {
+	return r.modify(func(users map[string]domain.User) error { return updateUser(users, u) })
+}
+
+// Modify applies fn to the stored user while holding the file lock and saves the result
+// unless fn returns an error.
+func (r *FileUserRepository) Modify(_ context.Context, id string, fn func(*domain.User) error) (*domain.User, error) {
+	var out *domain.User
+	err := r.modify(func(users map[string]domain.User) error {
+		u, err := modifyUser(users, id, fn)
+		out = u
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns every user ordered by username.
+func (r *FileUserRepository) List(_ context.Context) ([]domain.User, error) {
+	users, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return listUsers(users), nil
+}
+
+func (r *FileUserRepository) read() (map[string]domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+func (r *FileUserRepository) modify(fn func(map[string]domain.User) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users, err := r.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(users); err != nil {
+		return err
+	}
+	return r.save(users)
+}
+
+func (r *FileUserRepository) load() (map[string]domain.User, error) {
+	var list []domain.User
+	if err := readJSONFile(r.path, "user file", &list); err != nil {
+		return nil, err
+	}
+	users := make(map[string]domain.User, len(list))
+	for _, u := range list {
+		users[u.ID] = u
+	}
+	return users, nil
+}
+
+func (r *FileUserRepository) save(users map[string]domain.User) error {
+	return writeJSONFile(r.path, "user file", listUsers(users))
+}
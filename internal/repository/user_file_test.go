@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileUserRepository_PersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "users.json")
+	ctx := context.Background()
+	now := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+
+	a := NewFileUserRepository(path)
+	users, err := a.List(ctx)
+	require.NoError(t, err, "a missing file is an empty store")
+	assert.Empty(t, users)
+
+	u := &domain.User{ID: "u1", Username: "Alice", PasswordHash: "$argon2id$x", Roles: []string{"OWNER"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, a.Create(ctx, u))
+	assert.ErrorIs(t, a.Create(ctx, &domain.User{ID: "u2", Username: "alice"}), domain.ErrConflict, "usernames are case-insensitive")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	b := NewFileUserRepository(path)
+	got, err := b.GetByUsername(ctx, "ALICE")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", got.ID)
+	assert.Equal(t, "$argon2id$x", got.PasswordHash)
+
+	got.Disabled = true
+	require.NoError(t, b.Update(ctx, got))
+	again, err := a.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, again.Disabled, "writes through one instance are visible to the other")
+
+	assert.ErrorIs(t, b.Update(ctx, &domain.User{ID: "nope"}), domain.ErrNotFound)
+}
+
+func TestFileUserRepository_ModifyIsAtomic(t *testing.T) {
+	repo := NewFileUserRepository(filepath.Join(t.TempDir(), "users.json"))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &domain.User{ID: "u1", Username: "alice", Roles: []string{"OWNER"}}))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			_, err := repo.Modify(ctx, "u1", func(u *domain.User) error { u.FailedLogins++; return nil })
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+	got, err := repo.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 20, got.FailedLogins, "no increment is lost")
+	assert.Equal(t, []string{"OWNER"}, got.Roles, "fields fn does not touch are kept")
+
+	_, err = repo.Modify(ctx, "u1", func(u *domain.User) error { u.Disabled = true; return domain.ErrConflict })
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	got, _ = repo.Get(ctx, "u1")
+	assert.False(t, got.Disabled, "nothing is saved when fn fails")
+	_, err = repo.Modify(ctx, "nope", func(*domain.User) error { return nil })
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
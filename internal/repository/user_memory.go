@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryUserRepository is a process-local UserRepository.
+type InMemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]domain.User
+}
+
+// NewInMemoryUserRepository creates an empty user store.
+func NewInMemoryUserRepository() *InMemoryUserRepository {
+	return &InMemoryUserRepository{users: make(map[string]domain.User)}
+}
+
+// Create stores a new user; a duplicate id or username returns domain.ErrConflict.
+func (r *InMemoryUserRepository) Create(_ context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return createUser(r.users, u)
+}
+
+// Get returns the user with the given id or domain.ErrNotFound.
+func (r *InMemoryUserRepository) Get(_ context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return getUser(r.users, id)
+}
+
+// GetByUsername returns the user with the given username or domain.ErrNotFound.
+func (r *InMemoryUserRepository) GetByUsername(_ context.Context, username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return getUserByUsername(r.users, username)
+}
+
+// GetByExternalID returns the user provisioned for issuer and subject or domain.ErrNotFound.
+func (r *InMemoryUserRepository) GetByExternalID(_ context.Context, issuer, subject string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return getUserByExternalID(r.users, issuer, subject)
+}
+
+// Update replaces an existing user or returns domain.ErrNotFound.
+func (r *InMemoryUserRepository) Update(_ context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return updateUser(r.users, u)
+}
+
+// Modify applies fn to the stored user under the store's lock and saves the result unless
+// fn returns an error.
+func (r *InMemoryUserRepository) Modify(_ context.Context, id string, fn func(*domain.User) error) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return modifyUser(r.users, id, fn)
+}
+
+// List returns every user ordered by username.
+func (r *InMemoryUserRepository) List(_ context.Context) ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return listUsers(r.users), nil
+}
+
+// The helpers below are shared with FileUserRepository, which applies them to the map it
+// loads from disk.
+
+func createUser(users map[string]domain.User, u *domain.User) error {
+	if _, ok := users[u.ID]; ok {
+		return domain.ErrConflict
+	}
+	if _, err := getUserByUsername(users, u.Username); err == nil {
+		return domain.ErrConflict
+	}
+	if u.External() {
+		if _, err := getUserByExternalID(users, u.ExternalIssuer, u.ExternalSubject); err == nil {
+			return domain.ErrConflict
+		}
+	}
+	users[u.ID] = cloneUser(*u)
+	return nil
+}
+
+func getUser(users map[string]domain.User, id string) (*domain.User, error) {
+	u, ok := users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	u = cloneUser(u)
+	return &u, nil
+}
+
+func getUserByUsername(users map[string]domain.User, username string) (*domain.User, error) {
+	for _, u := range users {
+		if strings.EqualFold(u.Username, username) {
+			u = cloneUser(u)
+			return &u, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func getUserByExternalID(users map[string]domain.User, issuer, subject string) (*domain.User, error) {
+	for _, u := range users {
+		if u.ExternalIssuer == issuer && u.ExternalSubject == subject {
+			u = cloneUser(u)
+			return &u, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func updateUser(users map[string]domain.User, u *domain.User) error {
+	if _, ok := users[u.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	users[u.ID] = cloneUser(*u)
+	return nil
+}
+
+func modifyUser(users map[string]domain.User, id string, fn func(*domain.User) error) (*domain.User, error) {
+	u, err := getUser(users, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(u); err != nil {
+		return nil, err
+	}
+	if err := updateUser(users, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func listUsers(users map[string]domain.User) []domain.User {
+	out := make([]domain.User, 0, len(users))
+	for _, u := range users {
+		out = append(out, cloneUser(u))
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Username) < strings.ToLower(out[j].Username) })
+	return out
+}
+
+func cloneUser(u domain.User) domain.User {
+	u.Roles = append([]string(nil), u.Roles...)
+	u.RecoveryCodeHashes = append([]string(nil), u.RecoveryCodeHashes...)
+	if u.LockedUntil != nil {
+		t := *u.LockedUntil
+		u.LockedUntil = &t
+	}
+	return u
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// InMemoryWebhookSubscriptionRepository is a process-local WebhookSubscriptionRepository.
+// Suitable for a single replica and for tests; data is lost on restart.
+type InMemoryWebhookSubscriptionRepository struct {
+	mu    sync.RWMutex
+	items map[string]domain.WebhookSubscription
+}
+
+// NewInMemoryWebhookSubscriptionRepository creates an empty subscription store.
+func NewInMemoryWebhookSubscriptionRepository() *InMemoryWebhookSubscriptionRepository {
+	return &InMemoryWebhookSubscriptionRepository{items: make(map[string]domain.WebhookSubscription)}
+}
+
+// Create stores a new subscription.
+func (r *InMemoryWebhookSubscriptionRepository) Create(_ context.Context, sub *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := *sub
+	s.EventTypes = append([]string(nil), sub.EventTypes...)
+	r.items[s.ID] = s
+	return nil
+}
+
+// Get returns the subscription with the given id or domain.ErrNotFound.
+func (r *InMemoryWebhookSubscriptionRepository) Get(_ context.Context, id string) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	s.EventTypes = append([]string(nil), s.EventTypes...)
+	return &s, nil
+}
+
+// List returns every subscription, newest first.
+func (r *InMemoryWebhookSubscriptionRepository) List(_ context.Context) ([]domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.WebhookSubscription, 0, len(r.items))
+	for _, s := range r.items {
+		s.EventTypes = append([]string(nil), s.EventTypes...)
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Modify applies fn to the subscription under the store lock and stores the result.
+func (r *InMemoryWebhookSubscriptionRepository) Modify(_ context.Context, id string, fn func(*domain.WebhookSubscription) error) (*domain.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	s.EventTypes = append([]string(nil), s.EventTypes...)
+	if err := fn(&s); err != nil {
+		return nil, err
+	}
+	r.items[id] = s
+	out := s
+	out.EventTypes = append([]string(nil), s.EventTypes...)
+	return &out, nil
+}
+
+// Delete removes the subscription with the given id or returns domain.ErrNotFound.
+func (r *InMemoryWebhookSubscriptionRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// ListForEvent returns active subscriptions that listen for eventType.
+func (r *InMemoryWebhookSubscriptionRepository) ListForEvent(_ context.Context, eventType string) ([]domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.WebhookSubscription, 0)
+	for _, s := range r.items {
+		if s.Matches(eventType) {
+			s.EventTypes = append([]string(nil), s.EventTypes...)
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// InMemoryWebhookDeliveryRepository is a process-local WebhookDeliveryRepository.
+type InMemoryWebhookDeliveryRepository struct {
+	mu    sync.RWMutex
+	items map[string]domain.WebhookDelivery
+}
+
+// NewInMemoryWebhookDeliveryRepository creates an empty delivery store.
+func NewInMemoryWebhookDeliveryRepository() *InMemoryWebhookDeliveryRepository {
+	return &InMemoryWebhookDeliveryRepository{items: make(map[string]domain.WebhookDelivery)}
+}
+
+// Create stores a new delivery.
+func (r *InMemoryWebhookDeliveryRepository) Create(_ context.Context, d *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items[d.ID] = cloneDelivery(*d)
+	return nil
+}
+
+// Get returns the delivery with the given id or domain.ErrNotFound.
+func (r *InMemoryWebhookDeliveryRepository) Get(_ context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	d = cloneDelivery(d)
+	return &d, nil
+}
+
+// Update replaces an existing delivery.
+func (r *InMemoryWebhookDeliveryRepository) Update(_ context.Context, d *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[d.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	r.items[d.ID] = cloneDelivery(*d)
+	return nil
+}
+
+// List returns a page of the deliveries matching the filter, newest first by default.
+func (r *InMemoryWebhookDeliveryRepository) List(_ context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.WebhookDelivery, 0)
+	for _, d := range r.items {
+		if filter.SubscriptionID != "" && d.SubscriptionID != filter.SubscriptionID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		out = append(out, cloneDelivery(d))
+	}
+	return domain.Paginate(out, filter.OrDefault(domain.WebhookDeliverySortCreatedAt, true)), nil
+}
+
+// ListDue returns retryable deliveries whose next attempt is at or before now, oldest first.
+func (r *InMemoryWebhookDeliveryRepository) ListDue(_ context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.WebhookDelivery, 0)
+	for _, d := range r.items {
+		if d.Status.Retryable() && !d.NextAttemptAt.After(now) {
+			out = append(out, cloneDelivery(d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NextAttemptAt.Before(out[j].NextAttemptAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// cloneDelivery deep-copies slice fields so callers cannot mutate stored state.
+func cloneDelivery(d domain.WebhookDelivery) domain.WebhookDelivery {
+	d.Payload = append([]byte(nil), d.Payload...)
+	attempts := make([]domain.WebhookDeliveryAttempt, len(d.Attempts))
+	copy(attempts, d.Attempts)
+	d.Attempts = attempts
+	return d
+}
@@ -0,0 +1,103 @@
+// Package requestctx carries per-request correlation values in a context.Context so layers
+// below the HTTP handlers (use cases, repositories, background relays) can read them
+// without depending on gin.
+package requestctx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	traceIDKey
+	userIDKey
+	rolesKey
+	loggerKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request id stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTraceID returns a copy of ctx carrying the trace id.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey, id)
+}
+
+// TraceID returns the trace id stored in ctx, or "".
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey).(string)
+	return id
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user's id and roles.
+func WithUser(ctx context.Context, userID string, roles []string) context.Context {
+	return context.WithValue(context.WithValue(ctx, userIDKey, userID), rolesKey, roles)
+}
+
+// UserID returns the authenticated user id stored in ctx, or "".
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey).(string)
+	return id
+}
+
+// Roles returns the authenticated user's roles stored in ctx.
+func Roles(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey).([]string)
+	return roles
+}
+
+// WithLogger returns a copy of ctx carrying the base logger for Logger to enrich.
+func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// Logger returns the logger stored in ctx, or a no-op logger, enriched like Enrich. Code
+// that holds no logger of its own, such as a repository, logs through it.
+func Logger(ctx context.Context) *zap.Logger {
+	l, _ := ctx.Value(loggerKey).(*zap.Logger)
+	if l == nil {
+		l = zap.NewNop()
+	}
+	return Enrich(ctx, l)
+}
+
+// Enrich returns l with the request_id, trace_id, span_id and user_id found in ctx, so a
+// line logged anywhere below the handler joins the access log line of its request. Empty
+// values are left out; without any, l is returned as is.
+func Enrich(ctx context.Context, l *zap.Logger) *zap.Logger {
+	fields := make([]zap.Field, 0, 4)
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	traceID := TraceID(ctx)
+	if traceID == "" && sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+	if traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	if sc.HasSpanID() {
+		fields = append(fields, zap.String("span_id", sc.SpanID().String()))
+	}
+	if id := UserID(ctx); id != "" {
+		fields = append(fields, zap.String("user_id", id))
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
@@ -0,0 +1,61 @@
+package requestctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+	assert.Empty(t, TraceID(ctx))
+
+	ctx = WithTraceID(WithRequestID(ctx, "req-1"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceID(ctx))
+}
+
+func TestUserRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, UserID(ctx))
+	assert.Nil(t, Roles(ctx))
+
+	ctx = WithUser(ctx, "u1", []string{"OWNER"})
+	assert.Equal(t, "u1", UserID(ctx))
+	assert.Equal(t, []string{"OWNER"}, Roles(ctx))
+}
+
+func TestEnrich(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(core)
+
+	Enrich(context.Background(), base).Info("bare")
+	assert.Empty(t, logs.TakeAll()[0].Context, "no ids, no fields")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = WithUser(WithRequestID(ctx, "req-1"), "u1", nil)
+	Enrich(ctx, base).Info("deep")
+	assert.Equal(t, map[string]any{
+		"request_id": "req-1",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"user_id":    "u1",
+	}, logs.TakeAll()[0].ContextMap())
+}
+
+func TestLogger(t *testing.T) {
+	assert.NotNil(t, Logger(context.Background()), "falls back to a no-op logger")
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := WithRequestID(WithLogger(context.Background(), zap.New(core)), "req-1")
+	Logger(ctx).Info("from a repository")
+	assert.Equal(t, map[string]any{"request_id": "req-1"}, logs.TakeAll()[0].ContextMap())
+}
@@ -0,0 +1,80 @@
+// Package signing wraps Ed25519 keys loaded from configuration. Keys are identified by a
+// short fingerprint so a verifier can tell which key produced a signature.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrBadSignature is returned when a signature does not verify.
+var ErrBadSignature = errors.New("invalid signature")
+
+// Signer signs messages with an Ed25519 private key.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner builds a Signer from a 32-byte Ed25519 seed.
+func NewSigner(seed []byte) (*Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("ed25519 seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}, nil
+}
+
+// PublicKey returns the key verifiers need.
+func (s *Signer) PublicKey() ed25519.PublicKey { return s.key.Public().(ed25519.PublicKey) }
+
+// KeyID returns the fingerprint of the signer's public key.
+func (s *Signer) KeyID() string { return s.keyID }
+
+// Sign returns the Ed25519 signature of msg.
+func (s *Signer) Sign(msg []byte) []byte { return ed25519.Sign(s.key, msg) }
+
+// Verify checks that sig over msg was made by pub, and that keyID names pub.
+func Verify(pub ed25519.PublicKey, keyID string, msg, sig []byte) error {
+	if keyID != KeyID(pub) {
+		return fmt.Errorf("%w: signed by key %s, verifying with %s", ErrBadSignature, keyID, KeyID(pub))
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// KeySet holds the public keys a verifier accepts, by key id, so that documents signed
+// before a key rotation still verify.
+type KeySet map[string]ed25519.PublicKey
+
+// NewKeySet returns a KeySet holding keys.
+func NewKeySet(keys ...ed25519.PublicKey) KeySet {
+	ks := make(KeySet, len(keys))
+	for _, k := range keys {
+		ks[KeyID(k)] = k
+	}
+	return ks
+}
+
+// Verify checks that sig over msg was made by the key named keyID.
+func (ks KeySet) Verify(keyID string, msg, sig []byte) error {
+	pub, ok := ks[keyID]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %s", ErrBadSignature, keyID)
+	}
+	return Verify(pub, keyID, msg, sig)
+}
+
+// KeyID is a short fingerprint of a public key: the first 8 bytes of its SHA-256, in hex.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// PrivateKey returns the private key for libraries that sign on their own, such as JWT.
+func (s *Signer) PrivateKey() ed25519.PrivateKey { return s.key }
@@ -0,0 +1,41 @@
+package signing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	s, err := NewSigner(bytes.Repeat([]byte{5}, 32))
+	require.NoError(t, err)
+	other, err := NewSigner(bytes.Repeat([]byte{6}, 32))
+	require.NoError(t, err)
+	msg := []byte("hello")
+	sig := s.Sign(msg)
+
+	assert.NoError(t, Verify(s.PublicKey(), s.KeyID(), msg, sig))
+	assert.ErrorIs(t, Verify(s.PublicKey(), s.KeyID(), []byte("hellO"), sig), ErrBadSignature)
+	assert.ErrorIs(t, Verify(other.PublicKey(), s.KeyID(), msg, sig), ErrBadSignature, "key id mismatch")
+	assert.ErrorIs(t, Verify(other.PublicKey(), other.KeyID(), msg, sig), ErrBadSignature)
+	assert.Len(t, s.KeyID(), 16)
+
+	_, err = NewSigner([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestKeySet(t *testing.T) {
+	current, err := NewSigner(bytes.Repeat([]byte{5}, 32))
+	require.NoError(t, err)
+	retired, err := NewSigner(bytes.Repeat([]byte{6}, 32))
+	require.NoError(t, err)
+	ks := NewKeySet(current.PublicKey(), retired.PublicKey())
+	msg := []byte("hello")
+
+	assert.NoError(t, ks.Verify(current.KeyID(), msg, current.Sign(msg)))
+	assert.NoError(t, ks.Verify(retired.KeyID(), msg, retired.Sign(msg)))
+	assert.ErrorIs(t, ks.Verify(current.KeyID(), msg, retired.Sign(msg)), ErrBadSignature)
+	assert.ErrorIs(t, ks.Verify("0000000000000000", msg, current.Sign(msg)), ErrBadSignature)
+}
@@ -0,0 +1,139 @@
+// Package tracing sets up OpenTelemetry tracing (E-OBS-002): the tracer provider and its
+// exporter, the propagators that read and write trace context on HTTP headers, and an
+// http.RoundTripper that carries the current trace to outbound calls.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters selectable with Config.Exporter.
+const (
+	// ExporterNone creates spans, so trace ids are logged and propagated, but exports nothing.
+	ExporterNone = "none"
+	// ExporterOTLP sends spans over OTLP/HTTP; the endpoint and headers come from the
+	// standard OTEL_EXPORTER_OTLP_* environment variables.
+	ExporterOTLP = "otlp"
+	// ExporterConsole writes spans as JSON to Config.Output, for local use.
+	ExporterConsole = "console"
+)
+
+// Config configures the tracer provider.
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Exporter is one of ExporterNone, ExporterOTLP or ExporterConsole.
+	Exporter string
+	// Output receives console spans; it is unused by the other exporters.
+	Output io.Writer
+}
+
+// NewProvider creates a tracer provider that batches spans to the configured exporter.
+// Sampling follows the standard OTEL_TRACES_SAMPLER variables and defaults to sampling
+// every new trace and honouring the caller's decision for continued ones. Shut the
+// provider down on exit to flush buffered spans.
+func NewProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+		attribute.String("service.version", cfg.ServiceVersion),
+		attribute.String("deployment.environment.name", cfg.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterConsole:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(cfg.Output))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want %s, %s or %s)", cfg.Exporter, ExporterNone, ExporterOTLP, ExporterConsole)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s trace exporter: %w", cfg.Exporter, err)
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// Propagator reads W3C trace context, B3 in its multi-header (X-B3-TraceId, X-B3-SpanId,
+// X-B3-Sampled) and single-header (b3) forms, and W3C baggage. When a request carries both,
+// traceparent wins. Outbound requests get traceparent, tracestate, b3 and baggage, so
+// receivers that only speak B3 join the trace too.
+func Propagator() propagation.TextMapPropagator {
+	// Extraction runs in order and a later propagator overrides an earlier one.
+	return propagation.NewCompositeTextMapPropagator(
+		b3TraceIDOnly{},
+		b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)),
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+}
+
+// b3TraceIDOnly continues the trace of a caller that sends X-B3-TraceId without
+// X-B3-SpanId, which the b3 propagator rejects. The caller's span is unknown, so the parent
+// gets a random span id; the trace id, which is what logs are joined on, is kept. It
+// injects nothing.
+type b3TraceIDOnly struct{}
+
+const (
+	b3TraceIDHeader = "X-B3-TraceId"
+	b3SampledHeader = "X-B3-Sampled"
+)
+
+func (b3TraceIDOnly) Inject(context.Context, propagation.TextMapCarrier) {}
+
+func (b3TraceIDOnly) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	id := carrier.Get(b3TraceIDHeader)
+	if len(id) == 16 {
+		id = strings.Repeat("0", 16) + id
+	}
+	traceID, err := trace.TraceIDFromHex(id)
+	if err != nil {
+		return ctx
+	}
+	var spanID trace.SpanID
+	if _, err := rand.Read(spanID[:]); err != nil {
+		return ctx
+	}
+	var flags trace.TraceFlags
+	if carrier.Get(b3SampledHeader) != "0" {
+		flags = trace.FlagsSampled
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: flags, Remote: true,
+	}))
+}
+
+func (b3TraceIDOnly) Fields() []string { return []string{b3TraceIDHeader} }
+
+// Transport wraps base so every outbound request gets a client span and carries the
+// current trace context in its headers. base may be nil for http.DefaultTransport.
+func Transport(tp trace.TracerProvider, prop propagation.TextMapPropagator, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base, otelhttp.WithTracerProvider(tp), otelhttp.WithPropagators(prop))
+}
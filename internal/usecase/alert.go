@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// AlertService raises alerts when a recorded reading is out of range. It learns about
+// doses from DoseRecorded events on the bus rather than being called by DoseService, and
+// reads the post-dose reading from the dose store.
+type AlertService struct {
+	logger    *zap.Logger
+	tx        domain.TxManager
+	doses     domain.DoseEventRepository
+	alerts    domain.AlertRepository
+	publisher *EventPublisher
+	audit     *AuditService
+	ranges    map[string]domain.ChemistryRange
+	metrics   *metrics.Metrics
+
+	now func() time.Time
+}
+
+// NewAlertService wires an AlertService using domain.DefaultChemistryRanges.
+func NewAlertService(logger *zap.Logger, tx domain.TxManager, doses domain.DoseEventRepository, alerts domain.AlertRepository, publisher *EventPublisher, audit *AuditService, m *metrics.Metrics) *AlertService {
+	return &AlertService{
+		logger:    logger,
+		tx:        tx,
+		doses:     doses,
+		alerts:    alerts,
+		publisher: publisher,
+		audit:     audit,
+		ranges:    domain.DefaultChemistryRanges,
+		metrics:   m,
+		now:       time.Now,
+	}
+}
+
+// Subscribe registers the service's handlers on bus.
+func (s *AlertService) Subscribe(bus *events.Bus) error {
+	return events.Subscribe(bus, "alerts", s.onDoseRecorded, events.SubscribeOptions{Overflow: events.Block})
+}
+
+// List returns a page of the alerts matching f, newest first by default.
+func (s *AlertService) List(ctx context.Context, f domain.AlertFilter) ([]domain.Alert, error) {
+	return s.alerts.List(ctx, f)
+}
+
+// Acknowledge marks an alert as seen by the caller and returns it. Acknowledging an
+// acknowledged alert is a no-op that returns it unchanged.
+func (s *AlertService) Acknowledge(ctx context.Context, id string) (*domain.Alert, error) {
+	actorID, actorRole := actorFromContext(ctx)
+	var alert *domain.Alert
+	acknowledged := false
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		alert, err = s.alerts.Modify(ctx, id, func(a *domain.Alert) error {
+			if a.Acknowledged {
+				return nil
+			}
+			now := s.now().UTC()
+			a.Acknowledged = true
+			a.AcknowledgedAt = &now
+			a.AcknowledgedBy = actorID
+			acknowledged = true
+			return nil
+		})
+		if err != nil || !acknowledged {
+			return err
+		}
+		_, err = s.audit.Record(ctx, AuditEntry{
+			ActorID: actorID, ActorRole: actorRole, ActionType: "ALERT_ACKNOWLEDGED", EntityType: "alert", EntityID: alert.ID,
+			Metadata: map[string]string{"alert_type": alert.AlertType, "severity": alert.Severity},
+		})
+		if err != nil {
+			return fmt.Errorf("audit alert: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if acknowledged {
+		s.metrics.AlertAcknowledged(alert.Severity)
+		requestctx.Enrich(ctx, s.logger).Info("alert acknowledged",
+			zap.String("alert_id", alert.ID),
+			zap.String("severity", alert.Severity),
+		)
+	}
+	return alert, nil
+}
+
+// onDoseRecorded checks the post-dose reading and raises at most one alert per dose. The
+// alert id is derived from the dose id so a redelivered event does not raise a duplicate.
+func (s *AlertService) onDoseRecorded(ctx context.Context, msg events.Message[domain.DoseRecordedV1]) error {
+	dose, err := s.doses.Get(ctx, msg.Payload.DoseEventID)
+	if err != nil {
+		return fmt.Errorf("load dose %s: %w", msg.Payload.DoseEventID, err)
+	}
+	if dose.AfterValue == nil {
+		return nil
+	}
+	rng, ok := s.ranges[dose.Parameter]
+	if !ok {
+		return nil
+	}
+	alertType, severity, ok := rng.Classify(dose.Parameter, *dose.AfterValue)
+	if !ok {
+		return nil
+	}
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("alert:"+dose.ID)).String()
+	if _, err := s.alerts.Get(ctx, id); err == nil {
+		return nil
+	}
+
+	alert := &domain.Alert{
+		ID:           id,
+		JobID:        dose.JobID,
+		JobReadingID: dose.ID,
+		Parameter:    dose.Parameter,
+		Value:        *dose.AfterValue,
+		AlertType:    alertType,
+		Severity:     severity,
+		CreatedAt:    s.now().UTC(),
+	}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.alerts.Create(ctx, alert); err != nil {
+			return fmt.Errorf("create alert: %w", err)
+		}
+		_, err := s.publisher.Publish(ctx, domain.EventAlertRaised, domain.AlertRaisedV1{
+			AlertID:      alert.ID,
+			JobReadingID: alert.JobReadingID,
+			AlertType:    alert.AlertType,
+			Severity:     alert.Severity,
+			RaisedAt:     alert.CreatedAt,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.metrics.AlertRaised(alert.Severity)
+	requestctx.Enrich(ctx, s.logger).Info("alert raised",
+		zap.String("alert_id", alert.ID),
+		zap.String("alert_type", alert.AlertType),
+		zap.String("severity", alert.Severity),
+		zap.String("job_id", alert.JobID),
+	)
+	return nil
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []domain.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+// storeDose records a dose with the given post-dose reading and returns its DoseRecorded
+// message.
+func storeDose(t *testing.T, doses domain.DoseEventRepository, id, parameter string, after *float64) events.Message[domain.DoseRecordedV1] {
+	t.Helper()
+	require.NoError(t, doses.Create(context.Background(), &domain.DoseEvent{ID: id, JobID: "j1", Parameter: parameter, AfterValue: after}))
+	return events.Message[domain.DoseRecordedV1]{ID: "e-" + id, Payload: domain.DoseRecordedV1{DoseEventID: id, JobID: "j1", Parameter: parameter}}
+}
+
+func TestAlertService_RaisesOnceForOutOfRangeReading(t *testing.T) {
+	tx := repository.NewInMemoryTxManager()
+	outbox := repository.NewInMemoryOutboxRepository()
+	alerts := repository.NewInMemoryAlertRepository()
+	doses := repository.NewInMemoryDoseEventRepository()
+	m := metrics.New(nil)
+	svc := NewAlertService(zap.NewNop(), tx, doses, alerts, NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox), nil, m)
+	ctx := context.Background()
+
+	msg := storeDose(t, doses, "d1", "FC", floatPtr(0.2))
+	require.NoError(t, svc.onDoseRecorded(ctx, msg))
+	require.NoError(t, svc.onDoseRecorded(ctx, msg), "redelivery")
+
+	list, _ := svc.List(ctx, domain.AlertFilter{})
+	require.Len(t, list, 1)
+	assert.Equal(t, "FC_LOW", list[0].AlertType)
+	assert.Equal(t, domain.SeverityCritical, list[0].Severity)
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	require.Len(t, pending, 1)
+	assert.Equal(t, domain.EventAlertRaised, pending[0].Event.Type)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.Alerts.WithLabelValues(domain.SeverityCritical, "false")), "counted once")
+}
+
+func TestAlertService_IgnoresInRangeOrMissingReading(t *testing.T) {
+	alerts := repository.NewInMemoryAlertRepository()
+	doses := repository.NewInMemoryDoseEventRepository()
+	svc := NewAlertService(zap.NewNop(), repository.NewInMemoryTxManager(), doses, alerts,
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), nil, metrics.New(nil))
+	ctx := context.Background()
+
+	require.NoError(t, svc.onDoseRecorded(ctx, storeDose(t, doses, "d1", "FC", nil)))
+	require.NoError(t, svc.onDoseRecorded(ctx, storeDose(t, doses, "d2", "FC", floatPtr(3))))
+	list, _ := svc.List(ctx, domain.AlertFilter{})
+	assert.Empty(t, list)
+
+	err := svc.onDoseRecorded(ctx, events.Message[domain.DoseRecordedV1]{Payload: domain.DoseRecordedV1{DoseEventID: "unknown"}})
+	assert.ErrorIs(t, err, domain.ErrNotFound, "a dose that can not be read is retried")
+}
+
+func TestAlertService_AcknowledgeRecordsWhoAndCountsOnce(t *testing.T) {
+	alerts := repository.NewInMemoryAlertRepository()
+	auditRepo := repository.NewInMemoryAuditRepository()
+	m := metrics.New(nil)
+	svc := NewAlertService(zap.NewNop(), repository.NewInMemoryTxManager(), repository.NewInMemoryDoseEventRepository(), alerts,
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), NewAuditService(zap.NewNop(), auditRepo), m)
+	now := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := requestctx.WithUser(context.Background(), "dispatcher-7", []string{domain.RoleDispatcher})
+	require.NoError(t, alerts.Create(ctx, &domain.Alert{ID: "a1", Severity: domain.SeverityCritical}))
+
+	got, err := svc.Acknowledge(ctx, "a1")
+	require.NoError(t, err)
+	assert.True(t, got.Acknowledged)
+	assert.Equal(t, "dispatcher-7", got.AcknowledgedBy)
+	require.NotNil(t, got.AcknowledgedAt)
+	assert.Equal(t, now, *got.AcknowledgedAt)
+
+	now = now.Add(time.Hour)
+	again, err := svc.Acknowledge(requestctx.WithUser(context.Background(), "owner-1", []string{domain.RoleOwner}), "a1")
+	require.NoError(t, err)
+	assert.Equal(t, got, again, "a second acknowledgement changes nothing")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.Alerts.WithLabelValues(domain.SeverityCritical, "true")))
+	trail, _ := auditRepo.ListChain(ctx)
+	require.Len(t, trail, 1)
+	assert.Equal(t, "ALERT_ACKNOWLEDGED", trail[0].ActionType)
+	assert.Equal(t, "dispatcher-7", trail[0].ActorID)
+
+	_, err = svc.Acknowledge(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// TestEventFlow_DoseToAlertToNotification drives the whole pipeline: a dose is committed with
+// its event, the relay hands it to the bus, subscribers react, and the alert they raise goes
+// round the same loop to reach notifications.
+func TestEventFlow_DoseToAlertToNotification(t *testing.T) {
+	log := zap.NewNop()
+	tx := repository.NewInMemoryTxManager()
+	outbox := repository.NewInMemoryOutboxRepository()
+	publisher := NewEventPublisher(log, events.MustNewRegistry(), outbox)
+	inventory := repository.NewInMemoryInventoryRepository()
+	notifier := &recordingNotifier{}
+	doseRepo := repository.NewInMemoryDoseEventRepository()
+	alertSvc := NewAlertService(log, tx, doseRepo, repository.NewInMemoryAlertRepository(), publisher, nil, metrics.New(nil))
+
+	bus := events.NewBus(log, nil)
+	require.NoError(t, alertSvc.Subscribe(bus))
+	require.NoError(t, NewInventoryService(log, inventory).Subscribe(bus))
+	require.NoError(t, NewAuditService(log, repository.NewInMemoryAuditRepository()).Subscribe(bus))
+	require.NoError(t, NewNotificationService(log, notifier).Subscribe(bus))
+	var sinks []EventSink
+	for _, s := range bus.Sinks() {
+		sinks = append(sinks, s)
+	}
+	relay := NewOutboxRelay(log, outbox, DefaultOutboxRelayConfig(), sinks...)
+
+	doses := NewDoseService(log, tx, doseRepo, repository.NewInMemoryJobAssignmentRepository(), publisher, metrics.New(nil))
+	in := validDoseInput()
+	in.AfterValue = floatPtr(9)
+	_, err := doses.Record(context.Background(), in)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = relay.RelayPending(ctx) // DoseRecorded -> alerts, inventory, audit
+	require.NoError(t, err)
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	require.Len(t, pending, 1, "DoseRecorded is acknowledged once the subscribers are done")
+	assert.Equal(t, domain.EventAlertRaised, pending[0].Event.Type)
+	_, err = relay.RelayPending(ctx) // AlertRaised -> notifications, audit
+	require.NoError(t, err)
+	bus.Close()
+
+	item, err := inventory.Get(ctx, in.ProductID)
+	require.NoError(t, err)
+	assert.Equal(t, -in.ActualAmount, item.OnHand)
+	alerts, _ := alertSvc.List(ctx, domain.AlertFilter{})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "FC_HIGH", alerts[0].AlertType)
+	require.Len(t, notifier.sent, 1)
+	assert.Contains(t, notifier.sent[0].Subject, "FC_HIGH")
+}
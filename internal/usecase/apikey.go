@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// API key lifetimes. Every key expires; partners rotate by creating a new key before the
+// old one runs out.
+const (
+	DefaultAPIKeyTTL = 90 * 24 * time.Hour
+	MaxAPIKeyTTL     = 365 * 24 * time.Hour
+)
+
+// lastUsedResolution limits how often a busy key's LastUsedAt is written.
+const lastUsedResolution = time.Minute
+
+// CreateAPIKeyInput describes a new API key. A zero TTL means DefaultAPIKeyTTL.
+type CreateAPIKeyInput struct {
+	Name   string
+	Scopes []string
+	TTL    time.Duration
+}
+
+// APIKeyService issues, lists, revokes and authenticates scoped API keys.
+type APIKeyService struct {
+	logger *zap.Logger
+	repo   domain.APIKeyRepository
+	audit  *AuditService
+
+	now func() time.Time
+}
+
+// NewAPIKeyService wires an APIKeyService.
+func NewAPIKeyService(logger *zap.Logger, repo domain.APIKeyRepository, audit *AuditService) *APIKeyService {
+	return &APIKeyService{logger: logger, repo: repo, audit: audit, now: time.Now}
+}
+
+// Create issues a key and returns it with its plain value, which is not stored and can not
+// be shown again.
+func (s *APIKeyService) Create(ctx context.Context, in CreateAPIKeyInput) (*domain.APIKey, string, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return nil, "", domain.Validation("", domain.FieldError{Field: "name", Message: "is required"})
+	}
+	if err := validateScopes(in.Scopes); err != nil {
+		return nil, "", err
+	}
+	if in.TTL == 0 {
+		in.TTL = DefaultAPIKeyTTL
+	}
+	if in.TTL < 0 || in.TTL > MaxAPIKeyTTL {
+		return nil, "", domain.Validation("", domain.FieldError{Field: "expires_in_days", Message: fmt.Sprintf("must be within %d days", int(MaxAPIKeyTTL.Hours()/24))})
+	}
+	plain, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate api key: %w", err)
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	now := s.now().UTC()
+	k := &domain.APIKey{
+		ID:        uuid.NewString(),
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Name:      in.Name,
+		Scopes:    slices.Clone(in.Scopes),
+		CreatedBy: actorID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(in.TTL),
+	}
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, "", fmt.Errorf("store api key: %w", err)
+	}
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "API_KEY_CREATED", EntityType: "api_key", EntityID: k.ID,
+		Metadata: map[string]any{"name": k.Name, "prefix": k.Prefix, "scopes": k.Scopes, "expires_at": k.ExpiresAt},
+	}); err != nil {
+		return nil, "", fmt.Errorf("audit api key: %w", err)
+	}
+	return k, plain, nil
+}
+
+// List returns every key, including expired and revoked ones.
+func (s *APIKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Revoke disables a key immediately. Revoking a revoked key is a no-op.
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	revoked := false
+	k, err := s.repo.Modify(ctx, id, func(k *domain.APIKey) error {
+		if k.RevokedAt == nil {
+			now := s.now().UTC()
+			k.RevokedAt = &now
+			revoked = true
+		}
+		return nil
+	})
+	if errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if !revoked {
+		return nil
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "API_KEY_REVOKED", EntityType: "api_key", EntityID: k.ID,
+		Metadata: map[string]string{"prefix": k.Prefix},
+	}); err != nil {
+		return fmt.Errorf("audit api key: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey returns the active key matching raw, or domain.ErrInvalidCredentials.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, raw string) (*domain.APIKey, error) {
+	k, err := s.repo.GetByHash(ctx, auth.HashOpaqueToken(raw))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key", domain.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load api key: %w", err)
+	}
+	now := s.now()
+	if !k.Active(now) {
+		return nil, fmt.Errorf("%w: api key %s expired or revoked", domain.ErrInvalidCredentials, k.Prefix)
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedResolution {
+		// Only LastUsedAt is written, on the stored key: a revoke that lands after the load
+		// above must not be undone.
+		used := now.UTC()
+		if _, err := s.repo.Modify(ctx, k.ID, func(stored *domain.APIKey) error {
+			stored.LastUsedAt = &used
+			return nil
+		}); err != nil {
+			requestctx.Enrich(ctx, s.logger).Warn("record api key use failed", zap.String("api_key_prefix", k.Prefix), zap.Error(err))
+		}
+		k.LastUsedAt = &used
+	}
+	return k, nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return domain.Validation("", domain.FieldError{Field: "scopes", Message: "at least one scope is required"})
+	}
+	for _, sc := range scopes {
+		if !slices.Contains(domain.APIKeyScopes, sc) {
+			return domain.Validation("", domain.FieldError{Field: "scopes", Message: fmt.Sprintf("unknown scope %q; must be one of %s", sc, strings.Join(domain.APIKeyScopes, ", "))})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestAPIKeyService() (*APIKeyService, *repository.InMemoryAPIKeyRepository, *AuditService) {
+	repo := repository.NewInMemoryAPIKeyRepository()
+	audit := NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	return NewAPIKeyService(zap.NewNop(), repo, audit), repo, audit
+}
+
+func TestAPIKeyService_CreateValidates(t *testing.T) {
+	svc, _, _ := newTestAPIKeyService()
+	ctx := context.Background()
+	for name, in := range map[string]CreateAPIKeyInput{
+		"no name":       {Scopes: []string{domain.ScopeJobsRead}},
+		"no scopes":     {Name: "acme"},
+		"unknown scope": {Name: "acme", Scopes: []string{"jobs:delete"}},
+		"too long":      {Name: "acme", Scopes: []string{domain.ScopeJobsRead}, TTL: MaxAPIKeyTTL + time.Hour},
+	} {
+		_, _, err := svc.Create(ctx, in)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+	}
+}
+
+func TestAPIKeyService_CreateStoresOnlyHash(t *testing.T) {
+	svc, repo, audit := newTestAPIKeyService()
+	ctx := requestctx.WithUser(context.Background(), "owner-1", []string{domain.RoleOwner})
+
+	k, plain, err := svc.Create(ctx, CreateAPIKeyInput{Name: "acme", Scopes: []string{domain.ScopeJobsRead, domain.ScopeExportsRead}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plain, k.Prefix+"_"), "the key starts with its public prefix")
+	assert.Equal(t, "owner-1", k.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(DefaultAPIKeyTTL), k.ExpiresAt, time.Minute)
+
+	stored, err := repo.Get(ctx, k.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.KeyHash, plain)
+	assert.NotEqual(t, plain, stored.KeyHash)
+
+	evs, err := audit.Query(ctx, domain.AuditFilter{EntityType: "api_key"})
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "API_KEY_CREATED", evs[0].ActionType)
+	assert.NotContains(t, string(evs[0].Metadata), plain)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	svc, _, _ := newTestAPIKeyService()
+	ctx := context.Background()
+	k, plain, err := svc.Create(ctx, CreateAPIKeyInput{Name: "acme", Scopes: []string{domain.ScopeJobsRead}, TTL: 24 * time.Hour})
+	require.NoError(t, err)
+
+	got, err := svc.AuthenticateAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, k.ID, got.ID)
+	require.NotNil(t, got.LastUsedAt)
+
+	_, err = svc.AuthenticateAPIKey(ctx, plain+"x")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	svc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	_, err = svc.AuthenticateAPIKey(ctx, plain)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "expired")
+
+	svc.now = time.Now
+	require.NoError(t, svc.Revoke(ctx, k.ID))
+	require.NoError(t, svc.Revoke(ctx, k.ID), "revoking twice is a no-op")
+	_, err = svc.AuthenticateAPIKey(ctx, plain)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "revoked")
+
+	assert.ErrorIs(t, svc.Revoke(ctx, "missing"), domain.ErrNotFound)
+}
+
+// TestAPIKeyService_RevokeWinsOverConcurrentUse runs authentications, each of which records
+// LastUsedAt, alongside a revoke; the key must stay revoked however they interleave.
+func TestAPIKeyService_RevokeWinsOverConcurrentUse(t *testing.T) {
+	for _, repo := range map[string]domain.APIKeyRepository{
+		"memory": repository.NewInMemoryAPIKeyRepository(),
+		"file":   repository.NewFileAPIKeyRepository(filepath.Join(t.TempDir(), "api_keys.json")),
+	} {
+		svc := NewAPIKeyService(zap.NewNop(), repo, NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()))
+		var tick atomic.Int64
+		svc.now = func() time.Time { return time.Now().Add(time.Duration(tick.Add(1)) * lastUsedResolution) }
+		ctx := context.Background()
+		k, plain, err := svc.Create(ctx, CreateAPIKeyInput{Name: "acme", Scopes: []string{domain.ScopeJobsRead}})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					_, _ = svc.AuthenticateAPIKey(ctx, plain)
+				}
+			}()
+		}
+		require.NoError(t, svc.Revoke(ctx, k.ID))
+		wg.Wait()
+
+		stored, err := repo.Get(ctx, k.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.RevokedAt, "a concurrent use must not clear RevokedAt")
+		_, err = svc.AuthenticateAPIKey(ctx, plain)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// systemActor is the actor recorded for changes the service makes on its own behalf.
+const systemActor = "system"
+
+// AuditEntry is the caller-supplied part of an AuditEvent. Metadata is marshaled to JSON.
+type AuditEntry struct {
+	ActorID    string
+	ActorRole  string
+	ActionType string
+	EntityType string
+	EntityID   string
+	Metadata   any
+	OccurredAt time.Time
+}
+
+// AuditService writes the append-only audit trail. Besides explicit Record calls it
+// subscribes to every domain event on the bus, so state changes are audited without the
+// producing use case knowing about it.
+type AuditService struct {
+	logger *zap.Logger
+	repo   domain.AuditRepository
+
+	now func() time.Time
+}
+
+// NewAuditService wires an AuditService.
+func NewAuditService(logger *zap.Logger, repo domain.AuditRepository) *AuditService {
+	return &AuditService{logger: logger, repo: repo, now: time.Now}
+}
+
+// Record appends an audit event. request_id and trace_id are taken from ctx.
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) (*domain.AuditEvent, error) {
+	return s.record(ctx, uuid.NewString(), entry)
+}
+
+func (s *AuditService) record(ctx context.Context, id string, entry AuditEntry) (*domain.AuditEvent, error) {
+	if strings.TrimSpace(entry.ActorID) == "" || strings.TrimSpace(entry.ActionType) == "" || strings.TrimSpace(entry.EntityType) == "" {
+		return nil, fmt.Errorf("%w: actor_id, action_type and entity_type are required", domain.ErrInvalidInput)
+	}
+	var metadata json.RawMessage
+	if entry.Metadata != nil {
+		raw, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("marshal audit metadata: %w", err)
+		}
+		metadata = raw
+	}
+	occurredAt := entry.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = s.now()
+	}
+	ev := &domain.AuditEvent{
+		ID:         id,
+		ActorID:    entry.ActorID,
+		ActorRole:  entry.ActorRole,
+		ActionType: entry.ActionType,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Metadata:   metadata,
+		OccurredAt: occurredAt.UTC(),
+		RequestID:  requestctx.RequestID(ctx),
+		TraceID:    requestctx.TraceID(ctx),
+	}
+	if err := s.repo.Append(ctx, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// Query returns audit events matching f, newest first.
+func (s *AuditService) Query(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error) {
+	return s.repo.Query(ctx, f)
+}
+
+// Subscribe registers the service's handlers on bus. Audit must not miss events, so its
+// subscriptions block publishers instead of dropping when the queue is full.
+func (s *AuditService) Subscribe(bus *events.Bus) error {
+	opts := events.SubscribeOptions{Overflow: events.Block}
+	if err := events.Subscribe(bus, "audit.job_completed", auditHandler(s, func(p domain.JobCompletedV1) AuditEntry {
+		return AuditEntry{ActorID: p.TechnicianID, ActionType: "JOB_COMPLETED", EntityType: "job", EntityID: p.JobID}
+	}), opts); err != nil {
+		return err
+	}
+	if err := events.Subscribe(bus, "audit.dose_recorded", auditHandler(s, func(p domain.DoseRecordedV1) AuditEntry {
+		return AuditEntry{ActorID: p.RecordedBy, ActionType: "DOSE_RECORDED", EntityType: "dose_event", EntityID: p.DoseEventID}
+	}), opts); err != nil {
+		return err
+	}
+	if err := events.Subscribe(bus, "audit.invoice_paid", auditHandler(s, func(p domain.InvoicePaidV1) AuditEntry {
+		return AuditEntry{ActorID: systemActor, ActorRole: "SYSTEM", ActionType: "INVOICE_PAID", EntityType: "invoice", EntityID: p.InvoiceID}
+	}), opts); err != nil {
+		return err
+	}
+	return events.Subscribe(bus, "audit.alert_raised", auditHandler(s, func(p domain.AlertRaisedV1) AuditEntry {
+		return AuditEntry{ActorID: systemActor, ActorRole: "SYSTEM", ActionType: "ALERT_RAISED", EntityType: "alert", EntityID: p.AlertID}
+	}), opts)
+}
+
+// actorFromContext returns the authenticated user and roles stored in ctx, falling back to
+// the system actor for work not triggered by a request.
+func actorFromContext(ctx context.Context) (id, role string) {
+	id = requestctx.UserID(ctx)
+	if id == "" {
+		return systemActor, "SYSTEM"
+	}
+	return id, strings.Join(requestctx.Roles(ctx), ",")
+}
+
+// auditHandler turns a typed event into an audit entry whose id is derived from the event
+// id, so a redelivered event is recorded once.
+func auditHandler[T any](s *AuditService, entry func(T) AuditEntry) events.Handler[T] {
+	return func(ctx context.Context, msg events.Message[T]) error {
+		e := entry(msg.Payload)
+		e.Metadata = msg.Payload
+		e.OccurredAt = msg.OccurredAt
+		id := uuid.NewSHA1(uuid.NameSpaceURL, []byte("audit:"+msg.ID)).String()
+		if _, err := s.record(ctx, id, e); err != nil && !errors.Is(err, domain.ErrConflict) {
+			return fmt.Errorf("audit %s %s: %w", msg.Type, msg.ID, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// DefaultCheckpointInterval is how often chain heads are signed when not configured.
+const DefaultCheckpointInterval = time.Hour
+
+// AuditChainService signs periodic checkpoints over the audit and dose hash chains and
+// exports both chains for offline verification with `verify-audit`. It reads the chains inside
+// a transaction: the in-memory repositories append a link at once and remove it on rollback,
+// so a read outside one could sign or export a link that is later rolled back.
+type AuditChainService struct {
+	logger      *zap.Logger
+	tx          domain.TxManager
+	signer      *hashchain.Signer
+	checkpoints domain.CheckpointRepository
+	audit       domain.AuditRepository
+	doses       domain.DoseEventRepository
+	interval    time.Duration
+
+	now func() time.Time
+}
+
+// NewAuditChainService wires an AuditChainService.
+func NewAuditChainService(logger *zap.Logger, tx domain.TxManager, signer *hashchain.Signer, checkpoints domain.CheckpointRepository, audit domain.AuditRepository, doses domain.DoseEventRepository, interval time.Duration) *AuditChainService {
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+	return &AuditChainService{
+		logger:      logger,
+		tx:          tx,
+		signer:      signer,
+		checkpoints: checkpoints,
+		audit:       audit,
+		doses:       doses,
+		interval:    interval,
+		now:         time.Now,
+	}
+}
+
+// Run checkpoints both chains every interval until ctx is canceled.
+func (s *AuditChainService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Checkpoint(ctx); err != nil {
+				s.logger.Error("chain checkpoint failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Checkpoint signs the head of every chain that has grown since its last checkpoint and
+// returns the new checkpoints.
+func (s *AuditChainService) Checkpoint(ctx context.Context) ([]domain.ChainCheckpoint, error) {
+	heads := []struct {
+		chain string
+		head  domain.ChainHead
+	}{
+		{chain: domain.ChainAudit},
+		{chain: domain.ChainDose},
+	}
+	// Heads read while no other transaction is open are committed, and stay so.
+	if err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if heads[0].head, err = s.audit.Head(ctx); err != nil {
+			return fmt.Errorf("%s chain head: %w", domain.ChainAudit, err)
+		}
+		if heads[1].head, err = s.doses.Head(ctx); err != nil {
+			return fmt.Errorf("%s chain head: %w", domain.ChainDose, err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	created := make([]domain.ChainCheckpoint, 0, len(heads))
+	for _, h := range heads {
+		head := h.head
+		existing, err := s.checkpoints.List(ctx, h.chain)
+		if err != nil {
+			return created, fmt.Errorf("list %s checkpoints: %w", h.chain, err)
+		}
+		if head.Sequence == 0 || (len(existing) > 0 && existing[len(existing)-1].Sequence == head.Sequence) {
+			continue
+		}
+		cp := domain.ChainCheckpoint{
+			ID:        uuid.NewString(),
+			Chain:     h.chain,
+			Sequence:  head.Sequence,
+			Hash:      head.Hash,
+			CreatedAt: s.now().UTC(),
+		}
+		s.signer.SignCheckpoint(&cp)
+		if err := s.checkpoints.Append(ctx, &cp); err != nil {
+			return created, fmt.Errorf("store %s checkpoint: %w", h.chain, err)
+		}
+		requestctx.Enrich(ctx, s.logger).Info("chain checkpoint signed", zap.String("chain", cp.Chain), zap.Int64("sequence", cp.Sequence), zap.String("key_id", cp.KeyID))
+		created = append(created, cp)
+	}
+	return created, nil
+}
+
+// Export returns both chains and their checkpoints. Checkpoints are read first: chains only
+// grow, so every exported checkpoint is covered by the exported records.
+func (s *AuditChainService) Export(ctx context.Context) (*domain.ChainExport, error) {
+	exp := &domain.ChainExport{ExportedAt: s.now().UTC()}
+	for _, chain := range []string{domain.ChainAudit, domain.ChainDose} {
+		cps, err := s.checkpoints.List(ctx, chain)
+		if err != nil {
+			return nil, fmt.Errorf("list %s checkpoints: %w", chain, err)
+		}
+		exp.Checkpoints = append(exp.Checkpoints, cps...)
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if exp.AuditEvents, err = s.audit.ListChain(ctx); err != nil {
+			return fmt.Errorf("list audit chain: %w", err)
+		}
+		if exp.DoseEvents, err = s.doses.ListChain(ctx); err != nil {
+			return fmt.Errorf("list dose chain: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/hashchain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuditChainService_CheckpointsAndExportVerify(t *testing.T) {
+	signer, err := hashchain.NewSigner(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	auditRepo := repository.NewInMemoryAuditRepository()
+	doseRepo := repository.NewInMemoryDoseEventRepository()
+	checkpoints := repository.NewInMemoryCheckpointRepository()
+	chain := NewAuditChainService(zap.NewNop(), repository.NewInMemoryTxManager(), signer, checkpoints, auditRepo, doseRepo, 0)
+	ctx := context.Background()
+
+	created, err := chain.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created, "empty chains are not checkpointed")
+
+	audit := NewAuditService(zap.NewNop(), auditRepo)
+	doses := NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), doseRepo, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+	for i := 0; i < 3; i++ {
+		_, err := audit.Record(ctx, AuditEntry{ActorID: "u1", ActionType: "LOGIN_SUCCEEDED", EntityType: "user", EntityID: "u1"})
+		require.NoError(t, err)
+		_, err = doses.Record(ctx, validDoseInput())
+		require.NoError(t, err)
+	}
+
+	created, err = chain.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	created, err = chain.Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created, "unchanged heads are not checkpointed again")
+
+	exp, err := chain.Export(ctx)
+	require.NoError(t, err)
+	for _, res := range hashchain.VerifyExport(exp, signer.PublicKey()) {
+		assert.Nil(t, res.Break, res.Chain)
+		assert.Equal(t, 3, res.Records)
+		assert.Equal(t, 1, res.Checkpoints)
+	}
+
+	exp.DoseEvents[1].ActualAmount = 3
+	results := hashchain.VerifyExport(exp, signer.PublicKey())
+	require.NotNil(t, results[1].Break)
+	assert.Equal(t, domain.ChainDose, results[1].Break.Chain)
+	assert.Equal(t, int64(2), results[1].Break.Sequence)
+}
+
+// TestCheckpointSkipsRolledBackDose checkpoints while a dose transaction is open and then
+// rolls that dose back: the checkpoint must cover only committed doses, or every later
+// export would fail verification.
+func TestCheckpointSkipsRolledBackDose(t *testing.T) {
+	signer, err := hashchain.NewSigner(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	doseRepo := repository.NewInMemoryDoseEventRepository()
+	tx := repository.NewInMemoryTxManager()
+	chain := NewAuditChainService(zap.NewNop(), tx, signer, repository.NewInMemoryCheckpointRepository(), repository.NewInMemoryAuditRepository(), doseRepo, 0)
+	doses := NewDoseService(zap.NewNop(), tx, doseRepo, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+	ctx := context.Background()
+	_, err = doses.Record(ctx, validDoseInput())
+	require.NoError(t, err)
+
+	recorded, release := make(chan struct{}), make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := doses.Record(ctx, validDoseInput()); err != nil {
+				return err
+			}
+			close(recorded)
+			<-release
+			return errors.New("rolled back")
+		})
+	}()
+	<-recorded
+	checkpointed := make(chan []domain.ChainCheckpoint, 1)
+	go func() {
+		created, err := chain.Checkpoint(ctx)
+		assert.NoError(t, err)
+		checkpointed <- created
+	}()
+	time.Sleep(20 * time.Millisecond) // let the checkpoint reach the chain while the dose is uncommitted
+	close(release)
+	require.Error(t, <-txDone)
+
+	created := <-checkpointed
+	require.Len(t, created, 1)
+	assert.Equal(t, int64(1), created[0].Sequence, "the rolled back dose is not signed")
+
+	_, err = doses.Record(ctx, validDoseInput())
+	require.NoError(t, err)
+	exp, err := chain.Export(ctx)
+	require.NoError(t, err)
+	for _, res := range hashchain.VerifyExport(exp, signer.PublicKey()) {
+		assert.Nil(t, res.Break, res.Chain)
+	}
+}
+
+func TestDoseRollbackKeepsChainIntact(t *testing.T) {
+	doseRepo := repository.NewInMemoryDoseEventRepository()
+	tx := repository.NewInMemoryTxManager()
+	ok := NewDoseService(zap.NewNop(), tx, doseRepo, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+	failing := NewDoseService(zap.NewNop(), tx, doseRepo, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), failingOutbox{}), metrics.New(nil))
+	ctx := context.Background()
+
+	_, err := ok.Record(ctx, validDoseInput())
+	require.NoError(t, err)
+	_, err = failing.Record(ctx, validDoseInput())
+	require.Error(t, err)
+	_, err = ok.Record(ctx, validDoseInput())
+	require.NoError(t, err)
+
+	all, _ := doseRepo.ListChain(ctx)
+	require.Len(t, all, 2)
+	assert.Nil(t, hashchain.Verify(domain.ChainDose, all))
+}
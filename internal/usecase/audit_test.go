@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuditService_RecordCapturesCorrelationIDs(t *testing.T) {
+	svc := NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	ctx := requestctx.WithTraceID(requestctx.WithRequestID(context.Background(), "req-1"), "trace-1")
+
+	ev, err := svc.Record(ctx, AuditEntry{
+		ActorID: "u1", ActorRole: "OWNER", ActionType: "LOGIN_SUCCEEDED", EntityType: "user", EntityID: "u1",
+		Metadata: map[string]string{"ip": "10.0.0.1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", ev.RequestID)
+	assert.Equal(t, "trace-1", ev.TraceID)
+	assert.JSONEq(t, `{"ip":"10.0.0.1"}`, string(ev.Metadata))
+	assert.False(t, ev.OccurredAt.IsZero())
+
+	_, err = svc.Record(ctx, AuditEntry{ActionType: "X", EntityType: "user"})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestAuditService_QueryFilters(t *testing.T) {
+	svc := NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	ctx := context.Background()
+	base := time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+	for i, e := range []AuditEntry{
+		{ActorID: "u1", ActionType: "A", EntityType: "job", EntityID: "j1", OccurredAt: base},
+		{ActorID: "u2", ActionType: "A", EntityType: "job", EntityID: "j2", OccurredAt: base.Add(time.Hour)},
+		{ActorID: "u1", ActionType: "B", EntityType: "invoice", EntityID: "i1", OccurredAt: base.Add(2 * time.Hour)},
+	} {
+		_, err := svc.Record(ctx, e)
+		require.NoError(t, err, i)
+	}
+
+	got, _ := svc.Query(ctx, domain.AuditFilter{ActorID: "u1"})
+	require.Len(t, got, 2)
+	assert.Equal(t, "i1", got[0].EntityID, "newest first")
+
+	got, _ = svc.Query(ctx, domain.AuditFilter{EntityType: "job", EntityID: "j2"})
+	require.Len(t, got, 1)
+
+	got, _ = svc.Query(ctx, domain.AuditFilter{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)})
+	require.Len(t, got, 1)
+	assert.Equal(t, "j2", got[0].EntityID)
+
+	got, _ = svc.Query(ctx, domain.AuditFilter{PageRequest: domain.PageRequest{Limit: 1}})
+	assert.Len(t, got, 1)
+}
+
+func TestAuditService_RecordsBusEventsOnce(t *testing.T) {
+	repo := repository.NewInMemoryAuditRepository()
+	svc := NewAuditService(zap.NewNop(), repo)
+	bus := events.NewBus(zap.NewNop(), nil)
+	require.NoError(t, svc.Subscribe(bus))
+
+	ctx := requestctx.WithRequestID(context.Background(), "req-9")
+	data, err := json.Marshal(domain.DoseRecordedV1{DoseEventID: "d1", RecordedBy: "tech-1"})
+	require.NoError(t, err)
+	ev := domain.Event{ID: "e1", Type: domain.EventDoseRecorded, SchemaVersion: 1, Data: data}
+	for i := 0; i < 2; i++ {
+		for _, sink := range bus.Sinks() {
+			require.NoError(t, sink.HandleEvent(ctx, ev))
+		}
+	}
+	bus.Close()
+
+	got, _ := svc.Query(context.Background(), domain.AuditFilter{})
+	require.Len(t, got, 1)
+	assert.Equal(t, "DOSE_RECORDED", got[0].ActionType)
+	assert.Equal(t, "tech-1", got[0].ActorID)
+	assert.Equal(t, "dose_event", got[0].EntityType)
+	assert.Equal(t, "req-9", got[0].RequestID)
+}
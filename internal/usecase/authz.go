@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+)
+
+// principalFromContext returns the authenticated caller. ok is false for work that was not
+// started by an authenticated request (background workers, CLI), which is trusted.
+func principalFromContext(ctx context.Context) (p domain.Principal, ok bool) {
+	id := requestctx.UserID(ctx)
+	if id == "" {
+		return domain.Principal{}, false
+	}
+	return domain.Principal{UserID: id, Roles: requestctx.Roles(ctx)}, true
+}
+
+// authorizeJob returns domain.ErrForbidden unless the caller may act on jobID. OWNER and
+// DISPATCHER may act on any job; a TECH only on jobs on their route. API keys act on any
+// job; the route already checked the key's scope.
+func authorizeJob(ctx context.Context, assignments domain.JobAssignmentRepository, jobID string) error {
+	p, ok := principalFromContext(ctx)
+	if !ok || p.HasRole(domain.RoleOwner, domain.RoleDispatcher, domain.RoleIntegration) {
+		return nil
+	}
+	if !p.HasRole(domain.RoleTech) {
+		return domain.ErrForbidden
+	}
+	tech, err := assignments.AssignedTechnician(ctx, jobID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrForbidden
+	}
+	if err != nil {
+		return fmt.Errorf("load job assignment: %w", err)
+	}
+	if tech != p.UserID {
+		return domain.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// RecordDoseInput is the caller-supplied part of a DoseEvent.
+type RecordDoseInput struct {
+	JobID             string
+	Parameter         string
+	ProductID         string
+	Unit              string
+	RecommendedAmount *float64
+	ActualAmount      float64
+	BeforeValue       *float64
+	AfterValue        *float64
+	// UserID is who applied the dose. It defaults to the authenticated caller; only OWNER,
+	// DISPATCHER and API keys may record a dose on someone else's behalf.
+	UserID string
+}
+
+// DoseService records applied chemical doses and emits DoseRecorded events. Doses are
+// job-scoped: a TECH may only record and read doses for jobs on their route.
+type DoseService struct {
+	logger      *zap.Logger
+	tx          domain.TxManager
+	doses       domain.DoseEventRepository
+	assignments domain.JobAssignmentRepository
+	events      *EventPublisher
+	metrics     *metrics.Metrics
+
+	now func() time.Time
+}
+
+// NewDoseService wires a DoseService.
+func NewDoseService(logger *zap.Logger, tx domain.TxManager, doses domain.DoseEventRepository, assignments domain.JobAssignmentRepository, events *EventPublisher, m *metrics.Metrics) *DoseService {
+	return &DoseService{logger: logger, tx: tx, doses: doses, assignments: assignments, events: events, metrics: m, now: time.Now}
+}
+
+// Record validates and persists a dose. The DoseEvent row and its DoseRecorded outbox message
+// are written in one transaction: either both exist or neither does.
+func (s *DoseService) Record(ctx context.Context, in RecordDoseInput) (*domain.DoseEvent, error) {
+	if p, ok := principalFromContext(ctx); ok {
+		if in.UserID == "" {
+			in.UserID = p.UserID
+		}
+		if in.UserID != p.UserID && !p.HasRole(domain.RoleOwner, domain.RoleDispatcher, domain.RoleIntegration) {
+			return nil, domain.Forbidden("cannot record a dose for another user")
+		}
+	}
+	if err := validateDose(in); err != nil {
+		return nil, err
+	}
+	if err := authorizeJob(ctx, s.assignments, in.JobID); err != nil {
+		return nil, err
+	}
+	ev := &domain.DoseEvent{
+		ID:                uuid.NewString(),
+		JobID:             in.JobID,
+		Parameter:         in.Parameter,
+		RecommendedAmount: in.RecommendedAmount,
+		ActualAmount:      in.ActualAmount,
+		ProductID:         in.ProductID,
+		Unit:              in.Unit,
+		BeforeValue:       in.BeforeValue,
+		AfterValue:        in.AfterValue,
+		UserID:            in.UserID,
+		CreatedAt:         s.now().UTC(),
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.doses.Create(ctx, ev); err != nil {
+			return fmt.Errorf("create dose event: %w", err)
+		}
+		_, err := s.events.Publish(ctx, domain.EventDoseRecorded, domain.DoseRecordedV1{
+			DoseEventID:       ev.ID,
+			JobID:             ev.JobID,
+			Parameter:         ev.Parameter,
+			ProductID:         ev.ProductID,
+			Unit:              ev.Unit,
+			RecommendedAmount: ev.RecommendedAmount,
+			ActualAmount:      ev.ActualAmount,
+			RecordedBy:        ev.UserID,
+			RecordedAt:        ev.CreatedAt,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.DoseRecorded(ev.Parameter)
+	requestctx.Enrich(ctx, s.logger).Info("dose recorded", zap.String("dose_event_id", ev.ID), zap.String("job_id", ev.JobID), zap.String("parameter", ev.Parameter))
+	return ev, nil
+}
+
+// ListByJob returns a page of the doses recorded for a job, oldest first by default.
+func (s *DoseService) ListByJob(ctx context.Context, jobID string, page domain.PageRequest) ([]domain.DoseEvent, error) {
+	if err := authorizeJob(ctx, s.assignments, jobID); err != nil {
+		return nil, err
+	}
+	return s.doses.ListByJob(ctx, jobID, page)
+}
+
+func validateDose(in RecordDoseInput) error {
+	var fields []domain.FieldError
+	invalid := func(field, msg string) {
+		fields = append(fields, domain.FieldError{Field: field, Message: msg})
+	}
+	if strings.TrimSpace(in.JobID) == "" {
+		invalid("job_id", "is required")
+	}
+	if !domain.IsDoseParameter(in.Parameter) {
+		invalid("parameter", "must be one of "+strings.Join(domain.DoseParameters, ", "))
+	}
+	if strings.TrimSpace(in.ProductID) == "" {
+		invalid("product_id", "is required")
+	}
+	if strings.TrimSpace(in.Unit) == "" {
+		invalid("unit", "is required")
+	}
+	if in.ActualAmount < 0 {
+		invalid("actual_amount", "must not be negative")
+	}
+	if in.RecommendedAmount != nil && *in.RecommendedAmount < 0 {
+		invalid("recommended_amount", "must not be negative")
+	}
+	if strings.TrimSpace(in.UserID) == "" {
+		invalid("user_id", "is required")
+	}
+	if len(fields) > 0 {
+		return domain.Validation("", fields...)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// failingOutbox accepts nothing, simulating a crash/constraint failure after the dose insert.
+type failingOutbox struct{ domain.OutboxRepository }
+
+func (failingOutbox) Add(context.Context, *domain.OutboxMessage) error {
+	return errors.New("outbox unavailable")
+}
+
+func validDoseInput() RecordDoseInput {
+	return RecordDoseInput{JobID: "job-1", Parameter: "FC", ProductID: "liquid-chlorine", Unit: "oz", ActualAmount: 30, UserID: "tech-1"}
+}
+
+func TestDoseService_RecordWritesDoseAndOutboxTogether(t *testing.T) {
+	doses := repository.NewInMemoryDoseEventRepository()
+	outbox := repository.NewInMemoryOutboxRepository()
+	m := metrics.New(nil)
+	svc := NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), doses, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox), m)
+	ctx := context.Background()
+
+	ev, err := svc.Record(ctx, validDoseInput())
+	require.NoError(t, err)
+
+	stored, _ := doses.ListByJob(ctx, "job-1", domain.PageRequest{})
+	require.Len(t, stored, 1)
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	require.Len(t, pending, 1)
+	assert.Equal(t, domain.EventDoseRecorded, pending[0].Event.Type)
+
+	var data domain.DoseRecordedV1
+	require.NoError(t, json.Unmarshal(pending[0].Event.Data, &data))
+	assert.Equal(t, ev.ID, data.DoseEventID)
+	assert.Equal(t, "tech-1", data.RecordedBy)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.DoseEvents.WithLabelValues("FC")))
+}
+
+func TestDoseService_RollsBackDoseWhenOutboxWriteFails(t *testing.T) {
+	doses := repository.NewInMemoryDoseEventRepository()
+	svc := NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), doses, repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), failingOutbox{}), metrics.New(nil))
+	ctx := context.Background()
+
+	_, err := svc.Record(ctx, validDoseInput())
+	require.Error(t, err)
+	stored, _ := doses.ListByJob(ctx, "job-1", domain.PageRequest{})
+	assert.Empty(t, stored, "dose must not survive without its event")
+}
+
+func TestDoseService_Validation(t *testing.T) {
+	svc := NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), repository.NewInMemoryDoseEventRepository(), repository.NewInMemoryJobAssignmentRepository(),
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+
+	in := validDoseInput()
+	in.Parameter = "Iron"
+	_, err := svc.Record(context.Background(), in)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	in = validDoseInput()
+	in.ActualAmount = -1
+	_, err = svc.Record(context.Background(), in)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestDoseService_JobOwnership(t *testing.T) {
+	assignments := repository.NewInMemoryJobAssignmentRepository()
+	require.NoError(t, assignments.Assign(context.Background(), "job-1", "tech-1"))
+	svc := NewDoseService(zap.NewNop(), repository.NewInMemoryTxManager(), repository.NewInMemoryDoseEventRepository(), assignments,
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+	as := func(userID string, roles ...string) context.Context {
+		return requestctx.WithUser(context.Background(), userID, roles)
+	}
+	in := func(jobID, userID string) RecordDoseInput {
+		v := validDoseInput()
+		v.JobID, v.UserID = jobID, userID
+		return v
+	}
+
+	ev, err := svc.Record(as("tech-1", domain.RoleTech), in("job-1", ""))
+	require.NoError(t, err, "a TECH records on their own job")
+	assert.Equal(t, "tech-1", ev.UserID, "user id defaults to the caller")
+	_, err = svc.ListByJob(as("tech-1", domain.RoleTech), "job-1", domain.PageRequest{})
+	assert.NoError(t, err)
+
+	_, err = svc.Record(as("tech-2", domain.RoleTech), in("job-1", ""))
+	assert.ErrorIs(t, err, domain.ErrForbidden, "job is on another technician's route")
+	_, err = svc.ListByJob(as("tech-2", domain.RoleTech), "job-1", domain.PageRequest{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = svc.ListByJob(as("tech-1", domain.RoleTech), "job-unassigned", domain.PageRequest{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = svc.Record(as("tech-1", domain.RoleTech), in("job-1", "tech-2"))
+	assert.ErrorIs(t, err, domain.ErrForbidden, "a TECH cannot record for someone else")
+	_, err = svc.ListByJob(as("cust-1", domain.RoleCustomer), "job-1", domain.PageRequest{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = svc.Record(as("disp-1", domain.RoleDispatcher), in("job-unassigned", "tech-2"))
+	assert.NoError(t, err, "a DISPATCHER records on any job on a technician's behalf")
+	_, err = svc.ListByJob(as("owner-1", domain.RoleOwner), "job-1", domain.PageRequest{})
+	assert.NoError(t, err)
+}
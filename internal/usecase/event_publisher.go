@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// EventPublisher is the single entry point for outbound domain events. It wraps data in a
+// domain.Event envelope at the latest schema version, refuses anything that does not validate,
+// and writes the event to the transactional outbox. Call Publish with the ctx of the
+// transaction that makes the state change so both commit or roll back together; OutboxRelay
+// delivers the event afterwards.
+type EventPublisher struct {
+	logger   *zap.Logger
+	registry *events.Registry
+	outbox   domain.OutboxRepository
+
+	now func() time.Time
+}
+
+// NewEventPublisher wires an EventPublisher.
+func NewEventPublisher(logger *zap.Logger, registry *events.Registry, outbox domain.OutboxRepository) *EventPublisher {
+	return &EventPublisher{logger: logger, registry: registry, outbox: outbox, now: time.Now}
+}
+
+// Publish validates data against the latest schema for eventType and appends it to the outbox.
+// A *events.ValidationError is returned when the payload does not match the schema.
+func (p *EventPublisher) Publish(ctx context.Context, eventType string, data any) (*domain.Event, error) {
+	schema, err := p.registry.Latest(eventType)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", eventType, err)
+	}
+	if err := p.registry.Validate(eventType, schema.Version, raw); err != nil {
+		requestctx.Enrich(ctx, p.logger).Error("refusing to publish invalid event", zap.String("event_type", eventType), zap.Error(err))
+		return nil, err
+	}
+
+	now := p.now().UTC()
+	msg := &domain.OutboxMessage{
+		ID: uuid.NewString(),
+		Event: domain.Event{
+			ID:            uuid.NewString(),
+			Type:          eventType,
+			SchemaVersion: schema.Version,
+			OccurredAt:    now,
+			Data:          raw,
+		},
+		CreatedAt:   now,
+		DeliveredTo: []string{},
+		RequestID:   requestctx.RequestID(ctx),
+		TraceID:     requestctx.TraceID(ctx),
+	}
+	if err := p.outbox.Add(ctx, msg); err != nil {
+		return nil, fmt.Errorf("append to outbox: %w", err)
+	}
+	return &msg.Event, nil
+}
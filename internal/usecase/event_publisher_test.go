@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventPublisher_AppendsValidEnvelopeToOutbox(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	p := NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox)
+	ctx := context.Background()
+
+	ev, err := p.Publish(ctx, domain.EventJobCompleted, domain.JobCompletedV1{
+		JobID: "j1", PoolID: "p1", TechnicianID: "u1", CompletedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, ev.SchemaVersion)
+
+	pending, err := outbox.ListUnpublished(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, ev.ID, pending[0].Event.ID)
+	assert.Equal(t, domain.EventJobCompleted, pending[0].Event.Type)
+	assert.JSONEq(t, string(ev.Data), string(pending[0].Event.Data))
+}
+
+func TestEventPublisher_RejectsInvalidPayload(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	p := NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox)
+	ctx := context.Background()
+
+	_, err := p.Publish(ctx, domain.EventJobCompleted, map[string]string{"job_id": "j1"})
+	var vErr *events.ValidationError
+	assert.True(t, errors.As(err, &vErr), "got %v", err)
+
+	_, err = p.Publish(ctx, "Unregistered", struct{}{})
+	assert.ErrorIs(t, err, events.ErrUnknownSchema)
+
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	assert.Empty(t, pending, "nothing may reach the outbox when validation fails")
+}
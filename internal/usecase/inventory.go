@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// InventoryService keeps chemical stock in step with the doses technicians apply.
+type InventoryService struct {
+	logger    *zap.Logger
+	inventory domain.InventoryRepository
+}
+
+// NewInventoryService wires an InventoryService.
+func NewInventoryService(logger *zap.Logger, inventory domain.InventoryRepository) *InventoryService {
+	return &InventoryService{logger: logger, inventory: inventory}
+}
+
+// Subscribe registers the service's handlers on bus.
+func (s *InventoryService) Subscribe(bus *events.Bus) error {
+	return events.Subscribe(bus, "inventory", s.onDoseRecorded, events.SubscribeOptions{Overflow: events.Block})
+}
+
+// Get returns the stock of a product.
+func (s *InventoryService) Get(ctx context.Context, productID string) (*domain.InventoryItem, error) {
+	return s.inventory.Get(ctx, productID)
+}
+
+// onDoseRecorded deducts the applied amount. The event id is the movement id, so a
+// redelivered event is not deducted twice.
+func (s *InventoryService) onDoseRecorded(ctx context.Context, msg events.Message[domain.DoseRecordedV1]) error {
+	dose := msg.Payload
+	applied, err := s.inventory.ApplyMovement(ctx, domain.InventoryMovement{
+		ID:         msg.ID,
+		ProductID:  dose.ProductID,
+		Unit:       dose.Unit,
+		Quantity:   -dose.ActualAmount,
+		Reason:     "dose " + dose.DoseEventID,
+		OccurredAt: dose.RecordedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if !applied {
+		requestctx.Enrich(ctx, s.logger).Debug("inventory movement already applied", zap.String("event_id", msg.ID))
+	}
+	return nil
+}
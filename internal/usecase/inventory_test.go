@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInventoryService_DeductsEachDoseOnce(t *testing.T) {
+	svc := NewInventoryService(zap.NewNop(), repository.NewInMemoryInventoryRepository())
+	ctx := context.Background()
+
+	first := events.Message[domain.DoseRecordedV1]{ID: "e1", Payload: domain.DoseRecordedV1{ProductID: "acid", Unit: "oz", ActualAmount: 12}}
+	second := events.Message[domain.DoseRecordedV1]{ID: "e2", Payload: domain.DoseRecordedV1{ProductID: "acid", Unit: "oz", ActualAmount: 3}}
+	require.NoError(t, svc.onDoseRecorded(ctx, first))
+	require.NoError(t, svc.onDoseRecorded(ctx, first), "redelivery")
+	require.NoError(t, svc.onDoseRecorded(ctx, second))
+
+	item, err := svc.Get(ctx, "acid")
+	require.NoError(t, err)
+	assert.Equal(t, -15.0, item.OnHand)
+	assert.Equal(t, "oz", item.Unit)
+}
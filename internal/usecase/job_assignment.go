@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// JobAssignmentService routes jobs to technicians. The assignment is what lets a TECH record
+// and read the doses of a job, so only enabled TECH accounts can be assigned.
+type JobAssignmentService struct {
+	logger      *zap.Logger
+	tx          domain.TxManager
+	assignments domain.JobAssignmentRepository
+	users       domain.UserRepository
+	audit       *AuditService
+}
+
+// NewJobAssignmentService creates a JobAssignmentService.
+func NewJobAssignmentService(logger *zap.Logger, tx domain.TxManager, assignments domain.JobAssignmentRepository, users domain.UserRepository, audit *AuditService) *JobAssignmentService {
+	return &JobAssignmentService{logger: logger, tx: tx, assignments: assignments, users: users, audit: audit}
+}
+
+// Assign routes jobID to technicianID, replacing any previous assignment.
+func (s *JobAssignmentService) Assign(ctx context.Context, jobID, technicianID string) (*domain.JobAssignment, error) {
+	technicianID = strings.TrimSpace(technicianID)
+	if technicianID == "" {
+		return nil, domain.Validation("", domain.FieldError{Field: "technician_id", Message: "is required"})
+	}
+	tech, err := s.users.Get(ctx, technicianID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.Validation("", domain.FieldError{Field: "technician_id", Message: "is not a known user"})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load technician: %w", err)
+	}
+	if tech.Disabled || !tech.Principal().HasRole(domain.RoleTech) {
+		return nil, domain.Validation("", domain.FieldError{Field: "technician_id", Message: "must be an enabled " + domain.RoleTech + " user"})
+	}
+
+	actorID, actorRole := actorFromContext(ctx)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.assignments.Assign(ctx, jobID, technicianID); err != nil {
+			return fmt.Errorf("assign job: %w", err)
+		}
+		_, err := s.audit.Record(ctx, AuditEntry{
+			ActorID: actorID, ActorRole: actorRole, ActionType: "JOB_ASSIGNED", EntityType: "job", EntityID: jobID,
+			Metadata: map[string]string{"technician_id": technicianID},
+		})
+		if err != nil {
+			return fmt.Errorf("audit job assignment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	requestctx.Enrich(ctx, s.logger).Info("job assigned",
+		zap.String("job_id", jobID),
+		zap.String("technician_id", technicianID),
+	)
+	return &domain.JobAssignment{JobID: jobID, TechnicianID: technicianID}, nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestJobAssignmentService_AssignedTechCanRecordDoses(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewInMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "tech-1", Username: "tech1", Roles: []string{domain.RoleTech}}))
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "tech-2", Username: "tech2", Roles: []string{domain.RoleTech}}))
+	assignments := repository.NewInMemoryJobAssignmentRepository()
+	auditRepo := repository.NewInMemoryAuditRepository()
+	tx := repository.NewInMemoryTxManager()
+	svc := NewJobAssignmentService(zap.NewNop(), tx, assignments, users, NewAuditService(zap.NewNop(), auditRepo))
+	doses := NewDoseService(zap.NewNop(), tx, repository.NewInMemoryDoseEventRepository(), assignments,
+		NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), repository.NewInMemoryOutboxRepository()), metrics.New(nil))
+	dispatcher := requestctx.WithUser(ctx, "dispatcher-7", []string{domain.RoleDispatcher})
+	tech1 := requestctx.WithUser(ctx, "tech-1", []string{domain.RoleTech})
+
+	_, err := doses.ListByJob(tech1, "job-1", domain.PageRequest{})
+	require.ErrorIs(t, err, domain.ErrForbidden, "job is not on the route yet")
+
+	got, err := svc.Assign(dispatcher, "job-1", "tech-1")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.JobAssignment{JobID: "job-1", TechnicianID: "tech-1"}, got)
+	_, err = doses.Record(tech1, validDoseInput())
+	require.NoError(t, err)
+
+	_, err = svc.Assign(dispatcher, "job-1", "tech-2")
+	require.NoError(t, err)
+	_, err = doses.ListByJob(tech1, "job-1", domain.PageRequest{})
+	assert.ErrorIs(t, err, domain.ErrForbidden, "reassignment takes the job off the old route")
+
+	trail, _ := auditRepo.ListChain(ctx)
+	require.Len(t, trail, 2)
+	assert.Equal(t, "JOB_ASSIGNED", trail[1].ActionType)
+	assert.Equal(t, "dispatcher-7", trail[1].ActorID)
+	assert.JSONEq(t, `{"technician_id":"tech-2"}`, string(trail[1].Metadata))
+}
+
+func TestJobAssignmentService_RejectsNonTechnicians(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewInMemoryUserRepository()
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "disp-1", Username: "disp", Roles: []string{domain.RoleDispatcher}}))
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "tech-9", Username: "gone", Roles: []string{domain.RoleTech}, Disabled: true}))
+	assignments := repository.NewInMemoryJobAssignmentRepository()
+	svc := NewJobAssignmentService(zap.NewNop(), repository.NewInMemoryTxManager(), assignments, users,
+		NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()))
+
+	for _, techID := range []string{"", "nobody", "disp-1", "tech-9"} {
+		_, err := svc.Assign(ctx, "job-1", techID)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, "technician %q", techID)
+	}
+	_, err := assignments.AssignedTechnician(ctx, "job-1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
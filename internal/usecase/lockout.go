@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+)
+
+// errLocked and errUnchanged stop a UserRepository.Modify without saving.
+var (
+	errLocked    = errors.New("account locked")
+	errUnchanged = errors.New("unchanged")
+)
+
+// loginAttempts applies a LockoutPolicy to sign-in attempts. An attempt is counted before the
+// slow password or code check and settled after it, each step an atomic Modify of the stored
+// user, so parallel guesses share one budget: at most MaxFailures checks run before the
+// account locks, however many requests arrive at once.
+type loginAttempts struct {
+	users  domain.UserRepository
+	policy LockoutPolicy
+	// onLock is called after an attempt locks the account.
+	onLock func(ctx context.Context, u *domain.User, until time.Time)
+}
+
+// begin counts an attempt on the user, or returns ErrInvalidCredentials when the account is
+// locked. When MaxFailures attempts are already counted and unsettled it locks the account.
+func (a loginAttempts) begin(ctx context.Context, userID string, now time.Time) error {
+	var until time.Time
+	u, err := a.users.Modify(ctx, userID, func(u *domain.User) error {
+		if u.Locked(now) {
+			return errLocked
+		}
+		if a.policy.MaxFailures > 0 && u.FailedLogins >= a.policy.MaxFailures {
+			until = a.lock(u, now)
+			return nil
+		}
+		u.FailedLogins++
+		u.UpdatedAt = now.UTC()
+		return nil
+	})
+	switch {
+	case errors.Is(err, errLocked):
+		return fmt.Errorf("%w: account locked", domain.ErrInvalidCredentials)
+	case err != nil:
+		return fmt.Errorf("update user: %w", err)
+	case !until.IsZero():
+		a.onLock(ctx, u, until)
+		return fmt.Errorf("%w: account locked", domain.ErrInvalidCredentials)
+	}
+	return nil
+}
+
+// fail settles a counted attempt that failed for reason. The attempt stays counted; if it
+// was the last the policy allows, the account is locked. It returns the
+// ErrInvalidCredentials to give the caller.
+func (a loginAttempts) fail(ctx context.Context, userID string, now time.Time, reason string) error {
+	var until time.Time
+	u, err := a.users.Modify(ctx, userID, func(u *domain.User) error {
+		if u.Locked(now) || a.policy.MaxFailures <= 0 || u.FailedLogins < a.policy.MaxFailures {
+			return errUnchanged
+		}
+		until = a.lock(u, now)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errUnchanged) {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if !until.IsZero() {
+		a.onLock(ctx, u, until)
+		reason += "; account locked"
+	}
+	return fmt.Errorf("%w: %s", domain.ErrInvalidCredentials, reason)
+}
+
+// succeed settles a counted attempt that completed the sign-in: the failure count is
+// cleared and fn, if set, applies further changes in the same write. An account locked
+// meanwhile by parallel attempts stays locked and ErrInvalidCredentials is returned.
+func (a loginAttempts) succeed(ctx context.Context, userID string, now time.Time, fn func(*domain.User) error) (*domain.User, error) {
+	return a.pass(ctx, userID, now, true, fn)
+}
+
+// passFirstFactor settles a counted attempt that passed while another factor is still to
+// be checked. Only this attempt is uncounted; earlier failures stay, so a password that is
+// known does not reset the budget for guessing the second factor.
+func (a loginAttempts) passFirstFactor(ctx context.Context, userID string, now time.Time, fn func(*domain.User) error) (*domain.User, error) {
+	return a.pass(ctx, userID, now, false, fn)
+}
+
+func (a loginAttempts) pass(ctx context.Context, userID string, now time.Time, reset bool, fn func(*domain.User) error) (*domain.User, error) {
+	u, err := a.users.Modify(ctx, userID, func(u *domain.User) error {
+		if u.Locked(now) {
+			return errLocked
+		}
+		if reset {
+			u.FailedLogins, u.LockedUntil = 0, nil
+		} else if u.FailedLogins > 0 {
+			u.FailedLogins--
+		}
+		if fn != nil {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+		u.UpdatedAt = now.UTC()
+		return nil
+	})
+	if errors.Is(err, errLocked) {
+		return nil, fmt.Errorf("%w: account locked", domain.ErrInvalidCredentials)
+	}
+	return u, err
+}
+
+func (a loginAttempts) lock(u *domain.User, now time.Time) time.Time {
+	until := now.Add(a.policy.Duration).UTC()
+	u.FailedLogins, u.LockedUntil = 0, &until
+	u.UpdatedAt = now.UTC()
+	return until
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+)
+
+const (
+	// mfaChallengeTTL is how long a client has to present the second factor after the password.
+	mfaChallengeTTL = 5 * time.Minute
+	// maxMFAAttempts codes are checked per challenge; after that many wrong ones the client
+	// must log in again. Wrong codes also count toward the account lockout.
+	maxMFAAttempts = 5
+	// recoveryCodeCount recovery codes are issued when enrollment completes.
+	recoveryCodeCount = 10
+	totpPeriod        = 30
+)
+
+var totpOpts = totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+// MFAPolicy lists the roles that must use a second factor. Users outside those roles who
+// have enrolled anyway are challenged too.
+type MFAPolicy struct {
+	RequiredRoles []string
+}
+
+// DefaultMFAPolicy requires MFA for OWNER and DISPATCHER, who can change financials and dose
+// records.
+var DefaultMFAPolicy = MFAPolicy{RequiredRoles: []string{domain.RoleOwner, domain.RoleDispatcher}}
+
+// MFAChallengeResult tells the client to complete login with a second factor.
+// EnrollmentRequired is set when policy requires MFA but the user has no authenticator yet.
+type MFAChallengeResult struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+// TOTPEnrollment is what an authenticator app needs: the secret, as an otpauth:// URI and
+// as a QR code PNG of that URI.
+type TOTPEnrollment struct {
+	Secret    string
+	URI       string
+	QRCodePNG []byte
+}
+
+// MFAResult is a completed second-factor check. RecoveryCodes is set only when the check
+// completed enrollment; they are shown once and stored hashed.
+type MFAResult struct {
+	Principal     *domain.Principal
+	RecoveryCodes []string
+}
+
+// MFAService implements TOTP second-factor authentication (RFC 6238) with single-use
+// recovery codes. It works on challenges created after a successful password check, so
+// none of its endpoints need an access token.
+type MFAService struct {
+	logger     *zap.Logger
+	users      domain.UserRepository
+	challenges domain.MFAChallengeRepository
+	audit      *AuditService
+	policy     MFAPolicy
+	attempts   loginAttempts
+	issuer     string
+
+	now func() time.Time
+}
+
+// NewMFAService wires an MFAService. Wrong codes count toward lockout, the same policy the
+// UserService applies to passwords. issuer is the account label shown in authenticator apps.
+func NewMFAService(logger *zap.Logger, users domain.UserRepository, challenges domain.MFAChallengeRepository, audit *AuditService, policy MFAPolicy, lockout LockoutPolicy, issuer string) *MFAService {
+	s := &MFAService{logger: logger, users: users, challenges: challenges, audit: audit, policy: policy, issuer: issuer, now: time.Now}
+	s.attempts = loginAttempts{users: users, policy: lockout, onLock: s.lockedOut}
+	return s
+}
+
+// Challenge starts a second-factor step for p. It returns nil when p needs none.
+func (s *MFAService) Challenge(ctx context.Context, p *domain.Principal) (*MFAChallengeResult, error) {
+	u, err := s.users.Get(ctx, p.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	required := p.HasRole(s.policy.RequiredRoles...)
+	if !u.MFAEnrolled() && !required {
+		return nil, nil
+	}
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate mfa token: %w", err)
+	}
+	ch := &domain.MFAChallenge{
+		ID:        uuid.NewString(),
+		TokenHash: hash,
+		UserID:    u.ID,
+		Username:  u.Username,
+		ExpiresAt: s.now().Add(mfaChallengeTTL).UTC(),
+	}
+	if err := s.challenges.Create(ctx, ch); err != nil {
+		return nil, fmt.Errorf("store mfa challenge: %w", err)
+	}
+	return &MFAChallengeResult{Token: token, ExpiresAt: ch.ExpiresAt, EnrollmentRequired: !u.MFAEnrolled()}, nil
+}
+
+// Enroll generates a new TOTP secret for the challenged user. The secret becomes active when
+// Verify accepts its first code. Users who already have an authenticator get ErrConflict;
+// an admin must reset MFA first.
+func (s *MFAService) Enroll(ctx context.Context, token string) (*TOTPEnrollment, error) {
+	ch, err := s.challenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.Get(ctx, ch.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if u.MFAEnrolled() {
+		return nil, domain.Conflict("an authenticator is already enrolled")
+	}
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: u.Username,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generate totp secret: %w", err)
+	}
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return nil, fmt.Errorf("render qr code: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode qr code: %w", err)
+	}
+	if _, err := s.users.Modify(ctx, u.ID, func(u *domain.User) error {
+		if u.MFAEnrolled() {
+			return domain.Conflict("an authenticator is already enrolled")
+		}
+		u.TOTPPendingSecret = key.Secret()
+		u.UpdatedAt = s.now().UTC()
+		return nil
+	}); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+	return &TOTPEnrollment{Secret: key.Secret(), URI: key.URL(), QRCodePNG: buf.Bytes()}, nil
+}
+
+// Verify completes a challenge with a TOTP code or, for enrolled users, a recovery code.
+// Each code is counted against the challenge before it is checked, so parallel requests
+// can not try more than maxMFAAttempts; after that many wrong ones it is discarded. Wrong
+// codes also count toward the account lockout, so logging in again does not buy more
+// guesses. The code is matched and consumed in one atomic update of the user, so a TOTP
+// step or recovery code is accepted once even when sent twice at the same time.
+func (s *MFAService) Verify(ctx context.Context, token, code string) (*MFAResult, error) {
+	ch, err := s.challenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.Get(ctx, ch.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if u.Disabled {
+		return nil, fmt.Errorf("%w: account disabled", domain.ErrInvalidCredentials)
+	}
+	if !u.MFAEnrolled() && u.TOTPPendingSecret == "" {
+		return nil, domain.Validation("enroll an authenticator first")
+	}
+	now := s.now()
+	if ch, err = s.countAttempt(ctx, ch.ID); err != nil {
+		return nil, err
+	}
+	if err := s.attempts.begin(ctx, u.ID, now); err != nil {
+		return nil, err
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	res := &MFAResult{}
+	var action string
+	u, err = s.attempts.succeed(ctx, u.ID, now, func(u *domain.User) error {
+		switch {
+		case u.MFAEnrolled():
+			if step, ok := matchTOTP(u.TOTPSecret, code, now, u.TOTPLastStep); ok {
+				u.TOTPLastStep = step
+			} else if i := slices.Index(u.RecoveryCodeHashes, hashRecoveryCode(code)); i >= 0 {
+				u.RecoveryCodeHashes = slices.Delete(u.RecoveryCodeHashes, i, i+1)
+				action = "MFA_RECOVERY_CODE_USED"
+			} else {
+				return errWrongCode
+			}
+		case u.TOTPPendingSecret != "":
+			step, ok := matchTOTP(u.TOTPPendingSecret, code, now, 0)
+			if !ok {
+				return errWrongCode
+			}
+			codes, hashes, err := newRecoveryCodes()
+			if err != nil {
+				return err
+			}
+			u.TOTPSecret, u.TOTPPendingSecret, u.TOTPLastStep = u.TOTPPendingSecret, "", step
+			u.RecoveryCodeHashes, res.RecoveryCodes = hashes, codes
+			action = "MFA_ENROLLED"
+		default:
+			return errWrongCode
+		}
+		return nil
+	})
+	if errors.Is(err, errWrongCode) {
+		return nil, s.failAttempt(ctx, ch, now)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+	if err := s.challenges.Delete(ctx, ch.ID); err != nil {
+		return nil, fmt.Errorf("delete mfa challenge: %w", err)
+	}
+	if action != "" {
+		s.record(ctx, u, action, map[string]int{"recovery_codes_left": len(u.RecoveryCodeHashes)})
+	}
+	res.Principal = u.Principal()
+	return res, nil
+}
+
+func (s *MFAService) challenge(ctx context.Context, token string) (*domain.MFAChallenge, error) {
+	if token == "" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	ch, err := s.challenges.GetByHash(ctx, auth.HashOpaqueToken(token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load mfa challenge: %w", err)
+	}
+	if !s.now().Before(ch.ExpiresAt) {
+		_ = s.challenges.Delete(ctx, ch.ID)
+		return nil, fmt.Errorf("%w: mfa challenge expired", domain.ErrInvalidCredentials)
+	}
+	return ch, nil
+}
+
+// errWrongCode stops the user update in Verify when the code does not match.
+var errWrongCode = errors.New("wrong code")
+
+// countAttempt counts a code against the challenge before it is checked.
+func (s *MFAService) countAttempt(ctx context.Context, id string) (*domain.MFAChallenge, error) {
+	ch, err := s.challenges.Modify(ctx, id, func(ch *domain.MFAChallenge) error {
+		if ch.Attempts >= maxMFAAttempts {
+			return domain.ErrInvalidCredentials
+		}
+		ch.Attempts++
+		return nil
+	})
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrInvalidCredentials):
+		return nil, fmt.Errorf("%w: mfa challenge used up", domain.ErrInvalidCredentials)
+	case err != nil:
+		return nil, fmt.Errorf("update mfa challenge: %w", err)
+	}
+	return ch, nil
+}
+
+// failAttempt settles a wrong code: it counts toward the account lockout, and the challenge
+// is discarded once it has used all its attempts.
+func (s *MFAService) failAttempt(ctx context.Context, ch *domain.MFAChallenge, now time.Time) error {
+	failed := s.attempts.fail(ctx, ch.UserID, now, "invalid code")
+	if ch.Attempts >= maxMFAAttempts {
+		if err := s.challenges.Delete(ctx, ch.ID); err != nil {
+			return fmt.Errorf("delete mfa challenge: %w", err)
+		}
+	}
+	s.record(ctx, &domain.User{ID: ch.UserID}, "MFA_FAILED", map[string]int{"attempts": ch.Attempts})
+	return failed
+}
+
+// lockedOut logs and audits an account locked by wrong codes.
+func (s *MFAService) lockedOut(ctx context.Context, u *domain.User, until time.Time) {
+	requestctx.Enrich(ctx, s.logger).Warn("account locked after repeated failed logins",
+		zap.String("user_id", u.ID), zap.Time("locked_until", until))
+	s.record(ctx, u, "USER_LOCKED", map[string]any{"locked_until": until})
+}
+
+func (s *MFAService) record(ctx context.Context, u *domain.User, action string, metadata any) {
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: u.ID, ActionType: action, EntityType: "user", EntityID: u.ID, Metadata: metadata,
+	}); err != nil {
+		requestctx.Enrich(ctx, s.logger).Error("audit write failed", zap.String("action_type", action), zap.Error(err))
+	}
+}
+
+// matchTOTP checks code against the current time step and one step either side for clock
+// drift. Steps at or before lastStep are refused so an observed code can not be replayed.
+func matchTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	if len(code) != int(otp.DigitsSix) {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for _, step := range []int64{current - 1, current, current + 1} {
+		if step <= lastStep {
+			continue
+		}
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totpOpts)
+		if err == nil && subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes returns recoveryCodeCount codes formatted xxxx-xxxx and their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		c := strings.ToLower(enc.EncodeToString(b))
+		codes = append(codes, c[:4]+"-"+c[4:])
+		hashes = append(hashes, hashRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code ignoring case and dashes.
+func hashRecoveryCode(code string) string {
+	norm := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	sum := sha256.Sum256([]byte(norm))
+	return hex.EncodeToString(sum[:])
+}
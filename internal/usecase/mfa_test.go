@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mfaFixture struct {
+	users    *UserService
+	mfa      *MFAService
+	sessions *SessionService
+	audit    *AuditService
+	clock    time.Time
+}
+
+func newMFAFixture(t *testing.T) *mfaFixture {
+	t.Helper()
+	f := &mfaFixture{clock: time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)}
+	repo := repository.NewInMemoryUserRepository()
+	f.audit = NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	f.users = NewUserService(zap.NewNop(), repo, f.audit, DefaultLockoutPolicy)
+	f.users.hashParams = fastHashParams
+	f.users.now = func() time.Time { return f.clock }
+	f.mfa = NewMFAService(zap.NewNop(), repo, repository.NewInMemoryMFAChallengeRepository(), f.audit, DefaultMFAPolicy, DefaultLockoutPolicy, "Pool Maintenance")
+	f.mfa.now = func() time.Time { return f.clock }
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+	f.sessions = NewSessionService(zap.NewNop(), f.users, f.mfa, auth.NewIssuer(signer, testAuthConfig),
+		repository.NewInMemoryRefreshTokenRepository(), f.audit, 0)
+
+	ctx := context.Background()
+	for name, role := range map[string]string{"olivia": domain.RoleOwner, "tina": domain.RoleTech} {
+		_, err := f.users.Create(ctx, CreateUserInput{Username: name, Password: "correct horse battery", Roles: []string{role}})
+		require.NoError(t, err)
+	}
+	return f
+}
+
+func (f *mfaFixture) login(t *testing.T, username string) *LoginResult {
+	t.Helper()
+	res, err := f.sessions.Login(context.Background(), username, "correct horse battery")
+	require.NoError(t, err)
+	return res
+}
+
+func (f *mfaFixture) code(t *testing.T, secret string) string {
+	t.Helper()
+	c, err := totp.GenerateCode(secret, f.clock)
+	require.NoError(t, err)
+	return c
+}
+
+func TestMFA_PolicyDecidesWhoIsChallenged(t *testing.T) {
+	f := newMFAFixture(t)
+	tech := f.login(t, "tina")
+	assert.NotNil(t, tech.Tokens, "TECH is not required to use MFA")
+	assert.Nil(t, tech.MFA)
+
+	owner := f.login(t, "olivia")
+	assert.Nil(t, owner.Tokens, "no tokens before the second factor")
+	require.NotNil(t, owner.MFA)
+	assert.True(t, owner.MFA.EnrollmentRequired)
+}
+
+func TestMFA_EnrollVerifyAndRecoveryCodes(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	ch := f.login(t, "olivia").MFA
+
+	_, err := f.sessions.CompleteMFA(ctx, ch.Token, "123456")
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "must enroll before verifying")
+
+	enr, err := f.mfa.Enroll(ctx, ch.Token)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enr.URI, "otpauth://totp/Pool%20Maintenance:olivia?"), enr.URI)
+	assert.Contains(t, enr.URI, "secret="+enr.Secret)
+	assert.Equal(t, []byte("\x89PNG"), enr.QRCodePNG[:4])
+
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, "000000")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	code := f.code(t, enr.Secret)
+	tokens, err := f.sessions.CompleteMFA(ctx, ch.Token, code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	require.Len(t, tokens.RecoveryCodes, recoveryCodeCount)
+
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "a challenge is single use")
+
+	// Enrolled: the next login is challenged without enrollment and a replayed code fails.
+	ch = f.login(t, "olivia").MFA
+	require.NotNil(t, ch)
+	assert.False(t, ch.EnrollmentRequired)
+	_, err = f.mfa.Enroll(ctx, ch.Token)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "a TOTP code can not be replayed")
+	f.clock = f.clock.Add(30 * time.Second)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	require.NoError(t, err)
+
+	// Recovery codes work once, regardless of case and dash.
+	recovery := strings.ToUpper(strings.ReplaceAll(tokens.RecoveryCodes[0], "-", ""))
+	ch = f.login(t, "olivia").MFA
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, recovery)
+	require.NoError(t, err)
+	ch = f.login(t, "olivia").MFA
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, recovery)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	evs, err := f.audit.Query(ctx, domain.AuditFilter{EntityType: "user"})
+	require.NoError(t, err)
+	var actions []string
+	for _, ev := range evs {
+		actions = append(actions, ev.ActionType)
+	}
+	assert.Subset(t, actions, []string{"MFA_ENROLLED", "MFA_FAILED", "MFA_RECOVERY_CODE_USED"})
+}
+
+func TestMFA_ChallengeLimits(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	ch := f.login(t, "olivia").MFA
+	enr, err := f.mfa.Enroll(ctx, ch.Token)
+	require.NoError(t, err)
+
+	for range maxMFAAttempts {
+		_, err = f.sessions.CompleteMFA(ctx, ch.Token, "000000")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "challenge is discarded after too many wrong codes")
+	_, err = f.sessions.Login(ctx, "olivia", "correct horse battery")
+	assert.ErrorContains(t, err, "locked", "wrong codes count toward the account lockout")
+
+	f.clock = f.clock.Add(DefaultLockoutPolicy.Duration)
+	ch = f.login(t, "olivia").MFA
+	f.clock = f.clock.Add(mfaChallengeTTL)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "challenge expired")
+}
+
+func TestMFA_ResetRequiresReEnrollment(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	ch := f.login(t, "olivia").MFA
+	enr, err := f.mfa.Enroll(ctx, ch.Token)
+	require.NoError(t, err)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	require.NoError(t, err)
+
+	require.NoError(t, f.users.ResetMFA(ctx, "olivia"))
+	assert.True(t, f.login(t, "olivia").MFA.EnrollmentRequired)
+}
+
+func TestMFA_LoggingInAgainDoesNotResetTheGuessBudget(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	ch := f.login(t, "olivia").MFA
+	enr, err := f.mfa.Enroll(ctx, ch.Token)
+	require.NoError(t, err)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	require.NoError(t, err)
+	f.clock = f.clock.Add(30 * time.Second)
+
+	for range DefaultLockoutPolicy.MaxFailures {
+		ch := f.login(t, "olivia").MFA
+		_, err = f.sessions.CompleteMFA(ctx, ch.Token, "000000")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err = f.sessions.Login(ctx, "olivia", "correct horse battery")
+	assert.ErrorContains(t, err, "locked", "one wrong code per fresh challenge still locks the account")
+}
+
+func TestMFA_ParallelCodes(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	ch := f.login(t, "olivia").MFA
+	enr, err := f.mfa.Enroll(ctx, ch.Token)
+	require.NoError(t, err)
+	_, err = f.sessions.CompleteMFA(ctx, ch.Token, f.code(t, enr.Secret))
+	require.NoError(t, err)
+	f.clock = f.clock.Add(30 * time.Second)
+
+	// Two challenges race to use the same code: it is accepted once.
+	a, b := f.login(t, "olivia").MFA, f.login(t, "olivia").MFA
+	code := f.code(t, enr.Secret)
+	var ok atomic.Int32
+	var wg sync.WaitGroup
+	for _, token := range []string{a.Token, b.Token} {
+		wg.Go(func() {
+			if _, err := f.mfa.Verify(ctx, token, code); err == nil {
+				ok.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), ok.Load(), "a TOTP code is accepted once")
+
+	// Parallel guesses on one challenge share its attempt budget.
+	f.clock = f.clock.Add(DefaultLockoutPolicy.Duration)
+	ch = f.login(t, "olivia").MFA
+	var checked atomic.Int32
+	for range 20 {
+		wg.Go(func() {
+			if _, err := f.mfa.Verify(ctx, ch.Token, "000000"); err != nil && strings.Contains(err.Error(), "invalid code") {
+				checked.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, int(checked.Load()), maxMFAAttempts)
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"go.uber.org/zap"
+)
+
+// NotificationService tells staff about alerts and finished jobs.
+type NotificationService struct {
+	logger   *zap.Logger
+	notifier domain.Notifier
+}
+
+// NewNotificationService wires a NotificationService.
+func NewNotificationService(logger *zap.Logger, notifier domain.Notifier) *NotificationService {
+	return &NotificationService{logger: logger, notifier: notifier}
+}
+
+// Subscribe registers the service's handlers on bus. Notifications are best effort: the
+// relay hands them over without waiting, a full queue drops them, and a failed notification
+// is logged but not retried.
+func (s *NotificationService) Subscribe(bus *events.Bus) error {
+	opts := events.SubscribeOptions{Overflow: events.DropNewest}
+	if err := events.Subscribe(bus, "notifications.alerts", s.onAlertRaised, opts); err != nil {
+		return err
+	}
+	return events.Subscribe(bus, "notifications.jobs", s.onJobCompleted, opts)
+}
+
+func (s *NotificationService) onAlertRaised(ctx context.Context, msg events.Message[domain.AlertRaisedV1]) error {
+	a := msg.Payload
+	return s.notifier.Notify(ctx, domain.Notification{
+		Subject:  fmt.Sprintf("%s alert: %s", a.Severity, a.AlertType),
+		Body:     fmt.Sprintf("Alert %s raised at %s for reading %s.", a.AlertID, a.RaisedAt.Format("2006-01-02 15:04 MST"), a.JobReadingID),
+		Severity: a.Severity,
+	})
+}
+
+func (s *NotificationService) onJobCompleted(ctx context.Context, msg events.Message[domain.JobCompletedV1]) error {
+	j := msg.Payload
+	return s.notifier.Notify(ctx, domain.Notification{
+		Subject:  "Job completed",
+		Body:     fmt.Sprintf("Job %s at pool %s was completed by %s.", j.JobID, j.PoolID, j.TechnicianID),
+		Severity: domain.SeverityInfo,
+	})
+}
+
+// LogNotifier writes notifications to the log. It is the default until a real channel is configured.
+type LogNotifier struct {
+	Logger *zap.Logger
+}
+
+// Notify logs n.
+func (n LogNotifier) Notify(_ context.Context, notification domain.Notification) error {
+	n.Logger.Info("notification",
+		zap.String("subject", notification.Subject),
+		zap.String("severity", notification.Severity),
+		zap.String("body", notification.Body),
+	)
+	return nil
+}
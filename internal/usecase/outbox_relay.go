@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// EventSink receives relayed domain events. HandleEvent may be retried after a crash or a
+// failure in another sink, so implementations should tolerate seeing an event id twice.
+type EventSink interface {
+	// Name identifies the sink in OutboxMessage.DeliveredTo; it must be stable across releases.
+	Name() string
+	HandleEvent(ctx context.Context, ev domain.Event) error
+}
+
+// OutboxRelayConfig tunes the outbox polling loop.
+type OutboxRelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// SinkTimeout bounds a single HandleEvent call; zero means no bound.
+	SinkTimeout time.Duration
+	// MaxAttempts is how many times a message is relayed before it is dead-lettered; zero
+	// means it is retried forever.
+	MaxAttempts int
+	// RetryBackoff is the wait after the first failed attempt. It doubles with every further
+	// failure, up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// DefaultOutboxRelayConfig polls every second, 100 messages at a time, gives each sink 10
+// seconds per event, and dead-letters a message after 10 attempts spread over about 14 minutes.
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval:    time.Second,
+		BatchSize:       100,
+		SinkTimeout:     10 * time.Second,
+		MaxAttempts:     10,
+		RetryBackoff:    2 * time.Second,
+		MaxRetryBackoff: 5 * time.Minute,
+	}
+}
+
+// OutboxRelay moves committed outbox messages to every registered sink (webhooks,
+// notifications, internal subscribers). A message is marked published only once all sinks
+// have accepted it; sinks that already succeeded are skipped on retry. A message that a sink
+// refuses waits out an exponential backoff, during which newer messages are relayed, and is
+// dead-lettered after MaxAttempts, so one failing sink can not hold up the others.
+type OutboxRelay struct {
+	logger *zap.Logger
+	outbox domain.OutboxRepository
+	sinks  []EventSink
+	cfg    OutboxRelayConfig
+
+	now func() time.Time
+}
+
+// NewOutboxRelay wires an OutboxRelay.
+func NewOutboxRelay(logger *zap.Logger, outbox domain.OutboxRepository, cfg OutboxRelayConfig, sinks ...EventSink) *OutboxRelay {
+	return &OutboxRelay{logger: logger, outbox: outbox, sinks: sinks, cfg: cfg, now: time.Now}
+}
+
+// Run relays pending messages until ctx is canceled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RelayPending(ctx); err != nil {
+			r.logger.Error("outbox relay failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending hands every unpublished message to the sinks and returns how many were fully published.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	pending, err := r.outbox.ListDue(ctx, r.now(), r.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list outbox: %w", err)
+	}
+	published := 0
+	for i := range pending {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+		ok, err := r.relay(ctx, &pending[i])
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// relay delivers one message to the sinks that have not accepted it yet and persists progress.
+func (r *OutboxRelay) relay(ctx context.Context, msg *domain.OutboxMessage) (bool, error) {
+	msg.Attempts++
+	msg.LastError = ""
+	sinkCtx := requestctx.WithTraceID(requestctx.WithRequestID(ctx, msg.RequestID), msg.TraceID)
+	for _, sink := range r.sinks {
+		if slices.Contains(msg.DeliveredTo, sink.Name()) {
+			continue
+		}
+		if err := r.handle(sinkCtx, sink, msg.Event); err != nil {
+			msg.LastError = sink.Name() + ": " + err.Error()
+			requestctx.Enrich(sinkCtx, r.logger).Warn("outbox sink failed; will retry",
+				zap.String("sink", sink.Name()),
+				zap.String("event_id", msg.Event.ID),
+				zap.String("event_type", msg.Event.Type),
+				zap.Int("attempts", msg.Attempts),
+				zap.Error(err),
+			)
+			continue
+		}
+		msg.DeliveredTo = append(msg.DeliveredTo, sink.Name())
+	}
+	at := r.now()
+	done := msg.LastError == ""
+	switch {
+	case done:
+		msg.PublishedAt = &at
+		msg.NextAttemptAt = nil
+	case r.cfg.MaxAttempts > 0 && msg.Attempts >= r.cfg.MaxAttempts:
+		msg.DeadLetteredAt = &at
+		requestctx.Enrich(sinkCtx, r.logger).Error("outbox message dead-lettered",
+			zap.String("event_id", msg.Event.ID),
+			zap.String("event_type", msg.Event.Type),
+			zap.Int("attempts", msg.Attempts),
+			zap.String("last_error", msg.LastError),
+		)
+	default:
+		next := at.Add(r.backoff(msg.Attempts))
+		msg.NextAttemptAt = &next
+	}
+	if err := r.outbox.Update(ctx, msg); err != nil {
+		return false, fmt.Errorf("update outbox message %s: %w", msg.ID, err)
+	}
+	return done, nil
+}
+
+// handle calls sink, bounded by SinkTimeout.
+func (r *OutboxRelay) handle(ctx context.Context, sink EventSink, ev domain.Event) error {
+	if r.cfg.SinkTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.SinkTimeout)
+		defer cancel()
+	}
+	return sink.HandleEvent(ctx, ev)
+}
+
+// backoff is the wait before the next attempt after attempts failed ones.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	d := r.cfg.RetryBackoff
+	for i := 1; i < attempts && d < r.cfg.MaxRetryBackoff; i++ {
+		d *= 2
+	}
+	if r.cfg.MaxRetryBackoff > 0 && d > r.cfg.MaxRetryBackoff {
+		d = r.cfg.MaxRetryBackoff
+	}
+	return d
+}
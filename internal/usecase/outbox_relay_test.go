@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/events"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingSink collects events and can be told to fail.
+type recordingSink struct {
+	name string
+	mu   sync.Mutex
+	got  []domain.Event
+	fail error
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) HandleEvent(_ context.Context, ev domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.got = append(s.got, ev)
+	return nil
+}
+
+func publishTestEvent(t *testing.T, outbox domain.OutboxRepository) *domain.Event {
+	t.Helper()
+	p := NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox)
+	ev, err := p.Publish(context.Background(), domain.EventJobCompleted, domain.JobCompletedV1{
+		JobID: "j1", PoolID: "p1", TechnicianID: "u1", CompletedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	return ev
+}
+
+func TestOutboxRelay_PublishesToAllSinks(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	ev := publishTestEvent(t, outbox)
+	a, b := &recordingSink{name: "a"}, &recordingSink{name: "b"}
+	relay := NewOutboxRelay(zap.NewNop(), outbox, DefaultOutboxRelayConfig(), a, b)
+
+	n, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, a.got, 1)
+	require.Len(t, b.got, 1)
+	assert.Equal(t, ev.ID, a.got[0].ID)
+
+	pending, _ := outbox.ListUnpublished(context.Background(), 0)
+	assert.Empty(t, pending)
+
+	n, err = relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "published messages are not relayed again")
+}
+
+func TestOutboxRelay_NeverSeesUncommittedEvents(t *testing.T) {
+	tx := repository.NewInMemoryTxManager()
+	outbox := repository.NewInMemoryOutboxRepository()
+	p := NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox)
+	sink := &recordingSink{name: "sink"}
+	relay := NewOutboxRelay(zap.NewNop(), outbox, DefaultOutboxRelayConfig(), sink)
+	ctx := context.Background()
+	publish := func(ctx context.Context) error {
+		_, err := p.Publish(ctx, domain.EventJobCompleted, domain.JobCompletedV1{
+			JobID: "j1", PoolID: "p1", TechnicianID: "u1", CompletedAt: time.Now(),
+		})
+		return err
+	}
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, publish(ctx))
+		n, err := relay.RelayPending(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n, "the relay runs while the transaction is open")
+		return errors.New("rolled back")
+	})
+	require.Error(t, err)
+	n, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Empty(t, sink.got, "a rolled-back event is never published")
+
+	require.NoError(t, tx.WithinTx(ctx, publish))
+	n, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "committed events are published")
+}
+
+func TestOutboxRelay_RetriesOnlyFailedSinks(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	publishTestEvent(t, outbox)
+	ok := &recordingSink{name: "ok"}
+	flaky := &recordingSink{name: "flaky", fail: errors.New("down")}
+	relay := NewOutboxRelay(zap.NewNop(), outbox, DefaultOutboxRelayConfig(), ok, flaky)
+	ctx := context.Background()
+
+	n, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	require.Len(t, pending, 1)
+	assert.Equal(t, []string{"ok"}, pending[0].DeliveredTo)
+	assert.Contains(t, pending[0].LastError, "flaky: down")
+
+	require.NotNil(t, pending[0].NextAttemptAt)
+
+	flaky.fail = nil
+	n, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "the message waits out its backoff")
+
+	relay.now = func() time.Time { return time.Now().Add(time.Minute) }
+	n, err = relay.RelayPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, ok.got, 1, "sink that already accepted the event is not called again")
+	assert.Len(t, flaky.got, 1)
+}
+
+// TestOutboxRelay_FailingSinkDoesNotBlockOthers fills more than a batch with messages that
+// one sink always refuses: later messages must still reach the healthy sink, and the refused
+// ones are dead-lettered once they run out of attempts.
+func TestOutboxRelay_FailingSinkDoesNotBlockOthers(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	ok := &recordingSink{name: "ok"}
+	broken := &recordingSink{name: "broken", fail: errors.New("notifier outage")}
+	cfg := DefaultOutboxRelayConfig()
+	cfg.BatchSize = 2
+	cfg.MaxAttempts = 3
+	relay := NewOutboxRelay(zap.NewNop(), outbox, cfg, ok, broken)
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		publishTestEvent(t, outbox)
+	}
+	_, err := relay.RelayPending(ctx)
+	require.NoError(t, err)
+
+	later := publishTestEvent(t, outbox)
+	for i := 0; i < 20; i++ {
+		now = now.Add(cfg.MaxRetryBackoff)
+		_, err = relay.RelayPending(ctx)
+		require.NoError(t, err)
+	}
+
+	ids := make([]string, 0, len(ok.got))
+	for _, ev := range ok.got {
+		ids = append(ids, ev.ID)
+	}
+	assert.Len(t, ids, 3)
+	assert.Contains(t, ids, later.ID, "a message queued behind refused ones still reaches the healthy sink")
+
+	pending, _ := outbox.ListUnpublished(ctx, 0)
+	require.Len(t, pending, 3)
+	for _, m := range pending {
+		assert.NotNil(t, m.DeadLetteredAt, "given up on after MaxAttempts")
+		assert.Equal(t, cfg.MaxAttempts, m.Attempts)
+		assert.Contains(t, m.LastError, "broken: notifier outage")
+	}
+	due, _ := outbox.ListDue(ctx, now.Add(time.Hour), 0)
+	assert.Empty(t, due)
+}
+
+// hangingSink blocks until its context ends.
+type hangingSink struct{}
+
+func (hangingSink) Name() string { return "hanging" }
+
+func (hangingSink) HandleEvent(ctx context.Context, _ domain.Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOutboxRelay_BoundsEachSinkCall(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	publishTestEvent(t, outbox)
+	ok := &recordingSink{name: "ok"}
+	cfg := DefaultOutboxRelayConfig()
+	cfg.SinkTimeout = 20 * time.Millisecond
+	relay := NewOutboxRelay(zap.NewNop(), outbox, cfg, hangingSink{}, ok)
+
+	_, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, ok.got, 1, "the next sink runs once the hanging one times out")
+	pending, _ := outbox.ListUnpublished(context.Background(), 0)
+	require.Len(t, pending, 1)
+	assert.Contains(t, pending[0].LastError, "hanging: context deadline exceeded")
+}
+
+func TestOutboxRelay_FeedsWebhooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	webhooks, _ := newTestWebhookService(t, srv.URL, testWebhookConfig())
+	outbox := repository.NewInMemoryOutboxRepository()
+	ev := publishTestEvent(t, outbox)
+
+	relay := NewOutboxRelay(zap.NewNop(), outbox, DefaultOutboxRelayConfig(), webhooks)
+	_, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+
+	deliveries, err := webhooks.ListDeliveries(context.Background(), domain.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, ev.ID, deliveries[0].EventID)
+}
+
+type ctxCapturingSink struct{ requestID, traceID string }
+
+func (s *ctxCapturingSink) Name() string { return "ctx" }
+
+func (s *ctxCapturingSink) HandleEvent(ctx context.Context, _ domain.Event) error {
+	s.requestID, s.traceID = requestctx.RequestID(ctx), requestctx.TraceID(ctx)
+	return nil
+}
+
+func TestOutboxRelay_RestoresCorrelationIDs(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	p := NewEventPublisher(zap.NewNop(), events.MustNewRegistry(), outbox)
+	ctx := requestctx.WithTraceID(requestctx.WithRequestID(context.Background(), "req-1"), "trace-1")
+	_, err := p.Publish(ctx, domain.EventJobCompleted, domain.JobCompletedV1{
+		JobID: "j1", PoolID: "p1", TechnicianID: "u1", CompletedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	sink := &ctxCapturingSink{}
+	_, err = NewOutboxRelay(zap.NewNop(), outbox, DefaultOutboxRelayConfig(), sink).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", sink.requestID)
+	assert.Equal(t, "trace-1", sink.traceID)
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"go.uber.org/zap"
+)
+
+// ErrEngineUnavailable is returned by Recommend when no dosing engine is configured.
+var ErrEngineUnavailable = domain.Unavailable("dosing engine not configured")
+
+// StubDosingEngine stands in for the dosing engine until one ships. It recommends no doses;
+// its version is signed into every document, so a stub result can never pass for a real one.
+type StubDosingEngine struct{}
+
+// Version implements domain.DosingEngine.
+func (StubDosingEngine) Version() string { return "stub" }
+
+// Recommend implements domain.DosingEngine and always returns an empty list.
+func (StubDosingEngine) Recommend(context.Context, domain.DoseRecommendationInput) ([]domain.RecommendedDose, error) {
+	return []domain.RecommendedDose{}, nil
+}
+
+// DoseRecommendationService runs the dosing engine and signs every result, so a
+// recommendation presented in a later dispute can be proven to be exactly what the engine
+// produced from those inputs.
+type DoseRecommendationService struct {
+	logger  *zap.Logger
+	engine  domain.DosingEngine
+	signer  *signing.Signer
+	keys    signing.KeySet
+	repo    domain.DoseRecommendationRepository
+	metrics *metrics.Metrics
+
+	now func() time.Time
+}
+
+// NewDoseRecommendationService wires a DoseRecommendationService. engine may be nil to turn
+// recommendations off; Recommend then returns ErrEngineUnavailable while Get and Verify work.
+// Verify accepts documents signed by signer or by any of retired, the keys signer replaced.
+func NewDoseRecommendationService(logger *zap.Logger, engine domain.DosingEngine, signer *signing.Signer, retired []ed25519.PublicKey, repo domain.DoseRecommendationRepository, m *metrics.Metrics) *DoseRecommendationService {
+	keys := signing.NewKeySet(append([]ed25519.PublicKey{signer.PublicKey()}, retired...)...)
+	return &DoseRecommendationService{logger: logger, engine: engine, signer: signer, keys: keys, repo: repo, metrics: m, now: time.Now}
+}
+
+// Recommend asks the engine for doses, signs inputs, engine version and outputs together,
+// and stores the signed recommendation.
+func (s *DoseRecommendationService) Recommend(ctx context.Context, in domain.DoseRecommendationInput) (*domain.DoseRecommendation, error) {
+	if err := validateRecommendationInput(in); err != nil {
+		return nil, err
+	}
+	if s.engine == nil {
+		return nil, ErrEngineUnavailable
+	}
+	start := time.Now()
+	outputs, err := s.engine.Recommend(ctx, in)
+	s.metrics.ObserveDosingEngine(start, err)
+	if err != nil {
+		return nil, fmt.Errorf("dosing engine %s: %w", s.engine.Version(), err)
+	}
+	rec := &domain.DoseRecommendation{
+		ID:            uuid.NewString(),
+		Inputs:        in,
+		EngineVersion: s.engine.Version(),
+		Outputs:       outputs,
+		CreatedAt:     s.now().UTC(),
+	}
+	if err := s.sign(rec); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, rec); err != nil {
+		return nil, fmt.Errorf("store recommendation: %w", err)
+	}
+	requestctx.Enrich(ctx, s.logger).Info("dose recommendation signed",
+		zap.String("recommendation_id", rec.ID),
+		zap.String("job_id", in.JobID),
+		zap.String("engine_version", rec.EngineVersion),
+		zap.String("key_id", rec.KeyID),
+	)
+	return rec, nil
+}
+
+// Get returns a stored recommendation.
+func (s *DoseRecommendationService) Get(ctx context.Context, id string) (*domain.DoseRecommendation, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Verify checks a recommendation document against the key its KeyID names. It returns an
+// error wrapping signing.ErrBadSignature when the document was altered or signed by a key
+// this service does not know.
+func (s *DoseRecommendationService) Verify(rec domain.DoseRecommendation) error {
+	payload, err := rec.SigningPayload()
+	if err != nil {
+		return fmt.Errorf("encode recommendation: %w", err)
+	}
+	return s.keys.Verify(rec.KeyID, payload, rec.Signature)
+}
+
+func (s *DoseRecommendationService) sign(rec *domain.DoseRecommendation) error {
+	rec.SignatureVersion = domain.RecommendationSignatureV1
+	payload, err := rec.SigningPayload()
+	if err != nil {
+		return fmt.Errorf("encode recommendation: %w", err)
+	}
+	rec.KeyID = s.signer.KeyID()
+	rec.Signature = s.signer.Sign(payload)
+	return nil
+}
+
+func validateRecommendationInput(in domain.DoseRecommendationInput) error {
+	if in.PoolVolumeGallons <= 0 {
+		return domain.Validation("", domain.FieldError{Field: "pool_volume_gallons", Message: "must be positive"})
+	}
+	if len(in.Readings) == 0 {
+		return domain.Validation("", domain.FieldError{Field: "readings", Message: "at least one reading is required"})
+	}
+	for p := range in.Readings {
+		if !domain.IsDoseParameter(p) {
+			return domain.Validation("", domain.FieldError{Field: "readings." + p, Message: "unknown parameter"})
+		}
+	}
+	for p := range in.Targets {
+		if !domain.IsDoseParameter(p) {
+			return domain.Validation("", domain.FieldError{Field: "targets." + p, Message: "unknown parameter"})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/metrics"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fixedEngine returns one chlorine dose proportional to the FC deficit.
+type fixedEngine struct{}
+
+func (fixedEngine) Version() string { return "test-1" }
+
+func (fixedEngine) Recommend(_ context.Context, in domain.DoseRecommendationInput) ([]domain.RecommendedDose, error) {
+	deficit := in.Targets["FC"] - in.Readings["FC"]
+	return []domain.RecommendedDose{{Parameter: "FC", ProductID: "liquid-chlorine-12.5", Unit: "oz", Amount: deficit * in.PoolVolumeGallons / 1000}}, nil
+}
+
+func newTestRecommendationService(t *testing.T, engine domain.DosingEngine) *DoseRecommendationService {
+	t.Helper()
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	return NewDoseRecommendationService(zap.NewNop(), engine, signer, nil, repository.NewInMemoryDoseRecommendationRepository(), metrics.New(nil))
+}
+
+func testRecommendationInput() domain.DoseRecommendationInput {
+	return domain.DoseRecommendationInput{
+		JobID: "job-1", PoolVolumeGallons: 15000,
+		Readings: map[string]float64{"FC": 1.5, "pH": 7.6},
+		Targets:  map[string]float64{"FC": 4},
+	}
+}
+
+func TestDoseRecommendationService_SignsAndVerifies(t *testing.T) {
+	svc := newTestRecommendationService(t, fixedEngine{})
+	ctx := context.Background()
+
+	rec, err := svc.Recommend(ctx, testRecommendationInput())
+	require.NoError(t, err)
+	assert.Equal(t, "test-1", rec.EngineVersion)
+	assert.NotEmpty(t, rec.Signature)
+	require.NoError(t, svc.Verify(*rec))
+
+	stored, err := svc.Get(ctx, rec.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Verify(*stored))
+
+	// A document that went through JSON (as a client would hold it) still verifies.
+	raw, _ := json.Marshal(rec)
+	var roundTrip domain.DoseRecommendation
+	require.NoError(t, json.Unmarshal(raw, &roundTrip))
+	require.NoError(t, svc.Verify(roundTrip))
+
+	for name, tamper := range map[string]func(r *domain.DoseRecommendation){
+		"output amount":  func(r *domain.DoseRecommendation) { r.Outputs[0].Amount *= 2 },
+		"input reading":  func(r *domain.DoseRecommendation) { r.Inputs.Readings["FC"] = 0.5 },
+		"engine version": func(r *domain.DoseRecommendation) { r.EngineVersion = "test-2" },
+		"target added":   func(r *domain.DoseRecommendation) { r.Inputs.Targets["pH"] = 7.4 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			doc, err := svc.Get(ctx, rec.ID)
+			require.NoError(t, err)
+			tamper(doc)
+			assert.ErrorIs(t, svc.Verify(*doc), signing.ErrBadSignature)
+		})
+	}
+}
+
+func TestDoseRecommendationService_VerifiesAfterKeyRotation(t *testing.T) {
+	old := newTestRecommendationService(t, fixedEngine{})
+	rec, err := old.Recommend(context.Background(), testRecommendationInput())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RecommendationSignatureV1, rec.SignatureVersion)
+
+	newSigner, err := signing.NewSigner(bytes.Repeat([]byte{3}, 32))
+	require.NoError(t, err)
+	rotated := NewDoseRecommendationService(zap.NewNop(), fixedEngine{}, newSigner, []ed25519.PublicKey{old.signer.PublicKey()},
+		repository.NewInMemoryDoseRecommendationRepository(), metrics.New(nil))
+	assert.NoError(t, rotated.Verify(*rec), "documents signed by a retired key still verify")
+
+	forgotten := NewDoseRecommendationService(zap.NewNop(), fixedEngine{}, newSigner, nil, repository.NewInMemoryDoseRecommendationRepository(), metrics.New(nil))
+	assert.ErrorIs(t, forgotten.Verify(*rec), signing.ErrBadSignature)
+
+	rec.SignatureVersion = 2
+	assert.ErrorContains(t, old.Verify(*rec), "unknown signature version 2")
+}
+
+func TestDoseRecommendationService_StubEngineSignsEmptyRecommendation(t *testing.T) {
+	svc := newTestRecommendationService(t, StubDosingEngine{})
+	rec, err := svc.Recommend(context.Background(), testRecommendationInput())
+	require.NoError(t, err)
+	assert.Equal(t, "stub", rec.EngineVersion)
+	assert.NotNil(t, rec.Outputs)
+	assert.Empty(t, rec.Outputs)
+	require.NoError(t, svc.Verify(*rec))
+}
+
+func TestDoseRecommendationService_Errors(t *testing.T) {
+	_, err := newTestRecommendationService(t, nil).Recommend(context.Background(), testRecommendationInput())
+	assert.ErrorIs(t, err, ErrEngineUnavailable)
+
+	svc := newTestRecommendationService(t, fixedEngine{})
+	in := testRecommendationInput()
+	in.PoolVolumeGallons = 0
+	_, err = svc.Recommend(context.Background(), in)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	in = testRecommendationInput()
+	in.Readings["Iron"] = 1
+	_, err = svc.Recommend(context.Background(), in)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+type failingEngine struct{ fixedEngine }
+
+func (failingEngine) Recommend(context.Context, domain.DoseRecommendationInput) ([]domain.RecommendedDose, error) {
+	return nil, errors.New("solver diverged")
+}
+
+func TestDoseRecommendationService_ObservesEngineLatency(t *testing.T) {
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	m := metrics.New(nil)
+	for _, engine := range []domain.DosingEngine{fixedEngine{}, failingEngine{}} {
+		svc := NewDoseRecommendationService(zap.NewNop(), engine, signer, nil, repository.NewInMemoryDoseRecommendationRepository(), m)
+		_, _ = svc.Recommend(context.Background(), testRecommendationInput())
+	}
+	_, _ = newTestRecommendationService(t, nil).Recommend(context.Background(), testRecommendationInput())
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.DosingEngineLatency), "one series per outcome")
+	for _, outcome := range []string{metrics.OutcomeOK, metrics.OutcomeError} {
+		var out dto.Metric
+		require.NoError(t, m.DosingEngineLatency.WithLabelValues(outcome).(prometheus.Histogram).Write(&out))
+		assert.Equal(t, uint64(1), out.GetHistogram().GetSampleCount(), outcome)
+	}
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// ErrAuthenticatorUnavailable is returned by Login and Refresh when no user store is configured.
+var ErrAuthenticatorUnavailable = domain.Unavailable("authenticator not configured")
+
+// Tokens is an access token and the refresh token that can replace it.
+type Tokens struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	// RecoveryCodes is set when the login completed MFA enrollment.
+	RecoveryCodes []string
+}
+
+// LoginResult is the outcome of a password check: either tokens, or an MFA challenge the
+// client must complete with CompleteMFA.
+type LoginResult struct {
+	Tokens *Tokens
+	MFA    *MFAChallengeResult
+}
+
+// SessionService implements login, refresh-token rotation and logout (E-SEC-001). A login
+// starts a token family; every refresh marks the presented token used and issues a new one
+// in the same family. Presenting a used token means it was copied, so the whole family is
+// revoked and both the thief and the legitimate client must log in again.
+type SessionService struct {
+	logger     *zap.Logger
+	authn      domain.Authenticator
+	mfa        *MFAService
+	issuer     *auth.Issuer
+	tokens     domain.RefreshTokenRepository
+	audit      *AuditService
+	refreshTTL time.Duration
+
+	now func() time.Time
+}
+
+// NewSessionService wires a SessionService. refreshTTL defaults to, and is capped at,
+// auth.MaxRefreshTokenTTL. authn may be nil until a user store is available; Login and
+// Refresh then return ErrAuthenticatorUnavailable. mfa may be nil to skip second factors.
+func NewSessionService(logger *zap.Logger, authn domain.Authenticator, mfa *MFAService, issuer *auth.Issuer, tokens domain.RefreshTokenRepository, audit *AuditService, refreshTTL time.Duration) *SessionService {
+	if refreshTTL <= 0 || refreshTTL > auth.MaxRefreshTokenTTL {
+		refreshTTL = auth.MaxRefreshTokenTTL
+	}
+	return &SessionService{
+		logger:     logger,
+		authn:      authn,
+		mfa:        mfa,
+		issuer:     issuer,
+		tokens:     tokens,
+		audit:      audit,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// Login checks credentials and starts a new session, or returns an MFA challenge when the
+// user needs a second factor. Both outcomes of the password check are audited; the login
+// itself is audited as succeeded only once the session is issued.
+func (s *SessionService) Login(ctx context.Context, username, password string) (*LoginResult, error) {
+	if s.authn == nil {
+		return nil, ErrAuthenticatorUnavailable
+	}
+	p, err := s.authn.Authenticate(ctx, username, password)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			s.recordBestEffort(ctx, AuditEntry{
+				ActorID: username, ActionType: "LOGIN_FAILED", EntityType: "session",
+				Metadata: map[string]string{"username": username, "reason": err.Error()},
+			})
+		}
+		return nil, err
+	}
+	if s.mfa != nil {
+		ch, err := s.mfa.Challenge(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if ch != nil {
+			return &LoginResult{MFA: ch}, nil
+		}
+	}
+	tokens, err := s.start(ctx, p, map[string]any{"username": username, "mfa": false})
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteMFA finishes a login that returned an MFA challenge.
+func (s *SessionService) CompleteMFA(ctx context.Context, mfaToken, code string) (*Tokens, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	res, err := s.mfa.Verify(ctx, mfaToken, code)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.start(ctx, res.Principal, map[string]any{"mfa": true})
+	if err != nil {
+		return nil, err
+	}
+	tokens.RecoveryCodes = res.RecoveryCodes
+	return tokens, nil
+}
+
+// LoginExternal starts a session for a principal a single sign-on provider authenticated.
+// The provider is responsible for any second factor.
+func (s *SessionService) LoginExternal(ctx context.Context, p *domain.Principal, issuer string) (*Tokens, error) {
+	return s.start(ctx, p, map[string]any{"issuer": issuer, "mfa": false})
+}
+
+// start audits a successful login and issues the first tokens of a new session.
+func (s *SessionService) start(ctx context.Context, p *domain.Principal, metadata map[string]any) (*Tokens, error) {
+	familyID := uuid.NewString()
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: p.UserID, ActorRole: strings.Join(p.Roles, ","), ActionType: "LOGIN_SUCCEEDED",
+		EntityType: "session", EntityID: familyID, Metadata: metadata,
+	}); err != nil {
+		return nil, fmt.Errorf("audit login: %w", err)
+	}
+	return s.issue(ctx, p, familyID)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token. The presented token
+// can not be used again; presenting it a second time revokes the session.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	if s.authn == nil {
+		return nil, ErrAuthenticatorUnavailable
+	}
+	t, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if t.RevokedAt != nil || !now.Before(t.ExpiresAt) {
+		return nil, domain.ErrInvalidCredentials
+	}
+	fresh, err := s.tokens.MarkUsed(ctx, t.ID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("mark refresh token used: %w", err)
+	}
+	if !fresh {
+		return nil, s.revokeReused(ctx, t)
+	}
+	p, err := s.authn.Principal(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, p, t.FamilyID)
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are ignored so
+// logout is idempotent.
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	t, err := s.lookup(ctx, refreshToken)
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.RevokeFamily(ctx, t.FamilyID, s.now().UTC()); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	s.recordBestEffort(ctx, AuditEntry{ActorID: t.UserID, ActionType: "LOGOUT", EntityType: "session", EntityID: t.FamilyID})
+	return nil
+}
+
+// RevokeUserSessions revokes every session of userID and returns how many were revoked.
+// Access tokens already issued stay valid until they expire, at most auth.MaxAccessTokenTTL.
+func (s *SessionService) RevokeUserSessions(ctx context.Context, userID string) (int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, domain.Validation("user id is required")
+	}
+	n, err := s.tokens.RevokeUser(ctx, userID, s.now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "SESSIONS_REVOKED",
+		EntityType: "user", EntityID: userID, Metadata: map[string]int{"sessions": n},
+	}); err != nil {
+		return n, fmt.Errorf("audit session revocation: %w", err)
+	}
+	return n, nil
+}
+
+func (s *SessionService) issue(ctx context.Context, p *domain.Principal, familyID string) (*Tokens, error) {
+	access, accessExp, err := s.issuer.Issue(p.UserID, p.Roles)
+	if err != nil {
+		return nil, err
+	}
+	refresh, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+	now := s.now().UTC()
+	t := &domain.RefreshToken{
+		ID:        uuid.NewString(),
+		FamilyID:  familyID,
+		UserID:    p.UserID,
+		TokenHash: hash,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.tokens.Create(ctx, t); err != nil {
+		return nil, fmt.Errorf("store refresh token: %w", err)
+	}
+	return &Tokens{AccessToken: access, AccessExpiresAt: accessExp, RefreshToken: refresh, RefreshExpiresAt: t.ExpiresAt}, nil
+}
+
+func (s *SessionService) lookup(ctx context.Context, refreshToken string) (*domain.RefreshToken, error) {
+	if refreshToken == "" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	t, err := s.tokens.GetByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load refresh token: %w", err)
+	}
+	return t, nil
+}
+
+func (s *SessionService) revokeReused(ctx context.Context, t *domain.RefreshToken) error {
+	requestctx.Enrich(ctx, s.logger).Warn("refresh token reused; revoking session",
+		zap.String("user_id", t.UserID), zap.String("family_id", t.FamilyID))
+	if err := s.tokens.RevokeFamily(ctx, t.FamilyID, s.now().UTC()); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	s.recordBestEffort(ctx, AuditEntry{
+		ActorID: t.UserID, ActionType: "REFRESH_TOKEN_REUSED", EntityType: "session", EntityID: t.FamilyID,
+	})
+	return domain.ErrInvalidCredentials
+}
+
+// recordBestEffort audits an event whose outcome does not depend on the audit write; a failed
+// write is logged instead of returned.
+func (s *SessionService) recordBestEffort(ctx context.Context, entry AuditEntry) {
+	if _, err := s.audit.Record(ctx, entry); err != nil {
+		requestctx.Enrich(ctx, s.logger).Error("audit write failed", zap.String("action_type", entry.ActionType), zap.Error(err))
+	}
+}
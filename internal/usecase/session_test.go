@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// staticAuthenticator accepts a fixed set of username/password pairs.
+type staticAuthenticator map[string]struct {
+	password string
+	domain.Principal
+}
+
+func (a staticAuthenticator) Authenticate(_ context.Context, username, password string) (*domain.Principal, error) {
+	u, ok := a[username]
+	if !ok || u.password != password {
+		return nil, domain.ErrInvalidCredentials
+	}
+	p := u.Principal
+	return &p, nil
+}
+
+func (a staticAuthenticator) Principal(_ context.Context, userID string) (*domain.Principal, error) {
+	for _, u := range a {
+		if u.UserID == userID {
+			p := u.Principal
+			return &p, nil
+		}
+	}
+	return nil, domain.ErrInvalidCredentials
+}
+
+var testAuthConfig = auth.Config{Issuer: "test", Audience: "test"}
+
+func newTestSessionService(t *testing.T) (*SessionService, *auth.Verifier, *AuditService) {
+	t.Helper()
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{5}, 32))
+	require.NoError(t, err)
+	authn := staticAuthenticator{"alice": {password: "correct horse", Principal: domain.Principal{UserID: "u1", Roles: []string{"OWNER"}}}}
+	audit := NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	svc := NewSessionService(zap.NewNop(), authn, nil, auth.NewIssuer(signer, testAuthConfig),
+		repository.NewInMemoryRefreshTokenRepository(), audit, 0)
+	return svc, auth.NewVerifier(testAuthConfig, signer.PublicKey()), audit
+}
+
+func mustLogin(t *testing.T, svc *SessionService) *Tokens {
+	t.Helper()
+	res, err := svc.Login(context.Background(), "alice", "correct horse")
+	require.NoError(t, err)
+	require.NotNil(t, res.Tokens)
+	return res.Tokens
+}
+
+func auditActions(t *testing.T, audit *AuditService) []string {
+	t.Helper()
+	evs, err := audit.Query(context.Background(), domain.AuditFilter{EntityType: "session"})
+	require.NoError(t, err)
+	var out []string
+	for i := len(evs) - 1; i >= 0; i-- {
+		out = append(out, evs[i].ActionType)
+	}
+	return out
+}
+
+func TestSessionService_LoginAuditsOutcome(t *testing.T) {
+	svc, verifier, audit := newTestSessionService(t)
+	ctx := context.Background()
+
+	_, err := svc.Login(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	tokens := mustLogin(t, svc)
+	claims, err := verifier.Verify(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.Subject)
+	assert.WithinDuration(t, time.Now().Add(auth.MaxRefreshTokenTTL), tokens.RefreshExpiresAt, 5*time.Second)
+
+	assert.Equal(t, []string{"LOGIN_FAILED", "LOGIN_SUCCEEDED"}, auditActions(t, audit))
+}
+
+func TestSessionService_RefreshRotates(t *testing.T) {
+	svc, _, _ := newTestSessionService(t)
+	ctx := context.Background()
+	first := mustLogin(t, svc)
+
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	third, err := svc.Refresh(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, third.AccessToken)
+}
+
+func TestSessionService_ReuseRevokesFamily(t *testing.T) {
+	svc, _, audit := newTestSessionService(t)
+	ctx := context.Background()
+	first := mustLogin(t, svc)
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	other := mustLogin(t, svc)
+
+	_, err = svc.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "replayed token is refused")
+	_, err = svc.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "the legitimate successor is revoked too")
+
+	_, err = svc.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err, "other sessions are unaffected")
+	assert.Contains(t, auditActions(t, audit), "REFRESH_TOKEN_REUSED")
+}
+
+func TestSessionService_RefreshRejectsExpired(t *testing.T) {
+	svc, _, _ := newTestSessionService(t)
+	ctx := context.Background()
+	tokens := mustLogin(t, svc)
+
+	svc.now = func() time.Time { return time.Now().Add(auth.MaxRefreshTokenTTL + time.Minute) }
+	_, err := svc.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestSessionService_LogoutAndRevokeAll(t *testing.T) {
+	svc, _, audit := newTestSessionService(t)
+	ctx := context.Background()
+	a := mustLogin(t, svc)
+	b := mustLogin(t, svc)
+	c := mustLogin(t, svc)
+
+	require.NoError(t, svc.Logout(ctx, a.RefreshToken))
+	require.NoError(t, svc.Logout(ctx, "unknown"), "logout is idempotent")
+	_, err := svc.Refresh(ctx, a.RefreshToken)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	adminCtx := requestctx.WithUser(ctx, "admin-1", []string{"OWNER"})
+	n, err := svc.RevokeUserSessions(adminCtx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, tok := range []string{b.RefreshToken, c.RefreshToken} {
+		_, err = svc.Refresh(ctx, tok)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+
+	evs, err := audit.Query(ctx, domain.AuditFilter{EntityType: "user", EntityID: "u1"})
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "SESSIONS_REVOKED", evs[0].ActionType)
+	assert.Equal(t, "admin-1", evs[0].ActorID)
+}
+
+func TestSessionService_WithoutAuthenticator(t *testing.T) {
+	svc := NewSessionService(zap.NewNop(), nil, nil, nil, repository.NewInMemoryRefreshTokenRepository(),
+		NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository()), 0)
+	_, err := svc.Login(context.Background(), "alice", "pw")
+	assert.ErrorIs(t, err, ErrAuthenticatorUnavailable)
+}
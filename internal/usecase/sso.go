@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+const (
+	// ssoStateTTL bounds how long a user may take at the identity provider.
+	ssoStateTTL = 10 * time.Minute
+	// ssoHandoffTTL bounds how long the front end may take to redeem a completed login.
+	ssoHandoffTTL = time.Minute
+)
+
+// IdentityProvider is an OpenID Connect provider; *auth.OIDCProvider implements it.
+type IdentityProvider interface {
+	Issuer() string
+	AuthCodeURL(state, nonce, verifier string) string
+	Exchange(ctx context.Context, code, verifier string) (*auth.IDToken, error)
+}
+
+// RoleMapping turns ID token claims into roles. Values of Claim (a string or a list of
+// strings, e.g. group names) are looked up in Map; Default applies when nothing matches.
+type RoleMapping struct {
+	Claim   string
+	Map     map[string][]string
+	Default []string
+	// UsernameClaims are tried in order for the account's username; the subject is the
+	// fallback.
+	UsernameClaims []string
+}
+
+// DefaultRoleMapping reads groups from the "groups" claim and grants no role by default.
+var DefaultRoleMapping = RoleMapping{Claim: "groups", UsernameClaims: []string{"preferred_username", "email"}}
+
+// Roles returns the roles claims map to, in the order of domain.AllRoles.
+func (m RoleMapping) Roles(claims map[string]any) []string {
+	granted := map[string]bool{}
+	for _, v := range claimValues(claims[m.Claim]) {
+		for _, r := range m.Map[v] {
+			granted[r] = true
+		}
+	}
+	if len(granted) == 0 {
+		for _, r := range m.Default {
+			granted[r] = true
+		}
+	}
+	var roles []string
+	for _, r := range domain.AllRoles {
+		if granted[r] {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// Username returns the first non-empty UsernameClaims value.
+func (m RoleMapping) Username(claims map[string]any) string {
+	for _, c := range m.UsernameClaims {
+		if v, ok := claims[c].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func claimValues(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// SSOService logs users in through an OpenID Connect provider with the authorization code
+// flow and PKCE, then issues this service's own session tokens. Accounts are provisioned on
+// first login and their roles follow the provider's claims.
+type SSOService struct {
+	logger   *zap.Logger
+	provider IdentityProvider
+	states   domain.SSOLoginStateRepository
+	handoffs domain.SSOHandoffRepository
+	users    *UserService
+	sessions *SessionService
+	audit    *AuditService
+	mapping  RoleMapping
+
+	now func() time.Time
+}
+
+// NewSSOService wires an SSOService.
+func NewSSOService(logger *zap.Logger, provider IdentityProvider, states domain.SSOLoginStateRepository, handoffs domain.SSOHandoffRepository, users *UserService, sessions *SessionService, audit *AuditService, mapping RoleMapping) *SSOService {
+	return &SSOService{
+		logger:   logger,
+		provider: provider,
+		states:   states,
+		handoffs: handoffs,
+		users:    users,
+		sessions: sessions,
+		audit:    audit,
+		mapping:  mapping,
+		now:      time.Now,
+	}
+}
+
+// Begin starts a login and returns the provider URL to redirect the browser to, and the
+// state. The state, nonce and PKCE verifier are fresh random values; only the state travels
+// back to us. The caller must also keep the state in the browser, e.g. in a cookie, and hand
+// it to Complete, so a callback started in another browser is refused.
+func (s *SSOService) Begin(ctx context.Context) (authURL, state string, err error) {
+	state, stateHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("generate state: %w", err)
+	}
+	nonce, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("generate nonce: %w", err)
+	}
+	verifier, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("generate code verifier: %w", err)
+	}
+	if err := s.states.Create(ctx, &domain.SSOLoginState{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    s.now().Add(ssoStateTTL).UTC(),
+	}); err != nil {
+		return "", "", fmt.Errorf("store login state: %w", err)
+	}
+	return s.provider.AuthCodeURL(state, nonce, verifier), state, nil
+}
+
+// Complete handles the provider's callback: it checks that state is the one browserState
+// kept in the browser, redeems code, validates the ID token and its nonce and maps claims to
+// roles. It returns a one-time code for Redeem rather than tokens, so tokens never appear
+// in a URL or a page the browser renders. Every refusal is audited as LOGIN_FAILED and
+// returns domain.ErrInvalidCredentials, or domain.ErrForbidden when the user authenticated
+// but no role maps to them.
+func (s *SSOService) Complete(ctx context.Context, state, browserState, code string) (string, error) {
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return "", s.fail(ctx, "", fmt.Errorf("%w: login was not started in this browser", domain.ErrInvalidCredentials))
+	}
+	st, err := s.states.Take(ctx, auth.HashOpaqueToken(state))
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", s.fail(ctx, "", fmt.Errorf("%w: unknown or reused state", domain.ErrInvalidCredentials))
+	}
+	if err != nil {
+		return "", fmt.Errorf("load login state: %w", err)
+	}
+	if !s.now().Before(st.ExpiresAt) {
+		return "", s.fail(ctx, "", fmt.Errorf("%w: login expired", domain.ErrInvalidCredentials))
+	}
+	tok, err := s.provider.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		return "", s.fail(ctx, "", fmt.Errorf("%w: %v", domain.ErrInvalidCredentials, err))
+	}
+	if tok.Nonce != st.Nonce {
+		return "", s.fail(ctx, tok.Subject, fmt.Errorf("%w: nonce mismatch", domain.ErrInvalidCredentials))
+	}
+	roles := s.mapping.Roles(tok.Claims)
+	if len(roles) == 0 {
+		return "", s.fail(ctx, tok.Subject, domain.Forbidden("no role is mapped to this account"))
+	}
+	p, err := s.users.ProvisionExternal(ctx, ExternalIdentity{
+		Issuer:   tok.Issuer,
+		Subject:  tok.Subject,
+		Username: s.mapping.Username(tok.Claims),
+		Roles:    roles,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, domain.ErrConflict) {
+			return "", s.fail(ctx, tok.Subject, err)
+		}
+		return "", err
+	}
+	handoff, handoffHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("generate handoff code: %w", err)
+	}
+	if err := s.handoffs.Create(ctx, &domain.SSOHandoff{
+		CodeHash:  handoffHash,
+		UserID:    p.UserID,
+		Roles:     p.Roles,
+		Issuer:    tok.Issuer,
+		ExpiresAt: s.now().Add(ssoHandoffTTL).UTC(),
+	}); err != nil {
+		return "", fmt.Errorf("store handoff: %w", err)
+	}
+	return handoff, nil
+}
+
+// Redeem exchanges a one-time code from Complete for the session's tokens. Unknown, reused
+// and expired codes return domain.ErrInvalidCredentials.
+func (s *SSOService) Redeem(ctx context.Context, code string) (*Tokens, error) {
+	h, err := s.handoffs.Take(ctx, auth.HashOpaqueToken(code))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown or reused code", domain.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load handoff: %w", err)
+	}
+	if !s.now().Before(h.ExpiresAt) {
+		return nil, fmt.Errorf("%w: code expired", domain.ErrInvalidCredentials)
+	}
+	return s.sessions.LoginExternal(ctx, &domain.Principal{UserID: h.UserID, Roles: h.Roles}, h.Issuer)
+}
+
+// fail audits a refused login and returns err.
+func (s *SSOService) fail(ctx context.Context, subject string, err error) error {
+	if _, aerr := s.audit.Record(ctx, AuditEntry{
+		ActorID: subject, ActionType: "LOGIN_FAILED", EntityType: "session",
+		Metadata: map[string]string{"issuer": s.provider.Issuer(), "subject": subject, "reason": err.Error()},
+	}); aerr != nil {
+		requestctx.Enrich(ctx, s.logger).Error("audit write failed", zap.String("action_type", "LOGIN_FAILED"), zap.Error(aerr))
+	}
+	return err
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth/oidctest"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var testRoleMapping = RoleMapping{
+	Claim:          "groups",
+	Map:            map[string][]string{"hoa-admins": {domain.RoleOwner}, "field": {domain.RoleTech}},
+	UsernameClaims: DefaultRoleMapping.UsernameClaims,
+}
+
+type ssoFixture struct {
+	idp      *oidctest.Provider
+	sso      *SSOService
+	users    *UserService
+	verifier *auth.Verifier
+	audit    *AuditService
+}
+
+func newSSOFixture(t *testing.T) *ssoFixture {
+	t.Helper()
+	f := &ssoFixture{idp: oidctest.New(t)}
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:    f.idp.Issuer(),
+		ClientID:     f.idp.ClientID,
+		ClientSecret: f.idp.ClientSecret,
+		RedirectURL:  "http://app.test/api/v1/auth/oidc/callback",
+	})
+	require.NoError(t, err)
+	f.audit = NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	f.users = NewUserService(zap.NewNop(), repository.NewInMemoryUserRepository(), f.audit, DefaultLockoutPolicy)
+	f.users.hashParams = fastHashParams
+	signer, err := signing.NewSigner(bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	sessions := NewSessionService(zap.NewNop(), f.users, nil, auth.NewIssuer(signer, testAuthConfig),
+		repository.NewInMemoryRefreshTokenRepository(), f.audit, 0)
+	f.sso = NewSSOService(zap.NewNop(), provider, repository.NewInMemorySSOLoginStateRepository(), repository.NewInMemorySSOHandoffRepository(), f.users, sessions, f.audit, testRoleMapping)
+	f.verifier = auth.NewVerifier(testAuthConfig, signer.PublicKey())
+	return f
+}
+
+// login runs the browser side of the flow and returns the callback's state and code.
+func (f *ssoFixture) login(t *testing.T, claims map[string]any) (state, code string) {
+	t.Helper()
+	f.idp.SetClaims(claims)
+	authURL, _, err := f.sso.Begin(context.Background())
+	require.NoError(t, err)
+	cb, err := f.idp.Login(authURL)
+	require.NoError(t, err)
+	return cb.Query().Get("state"), cb.Query().Get("code")
+}
+
+// complete runs a whole login for a user with the given claims, from the same browser, and
+// redeems the handoff code.
+func (f *ssoFixture) complete(t *testing.T, claims map[string]any) (*Tokens, error) {
+	t.Helper()
+	state, code := f.login(t, claims)
+	handoff, err := f.sso.Complete(context.Background(), state, state, code)
+	if err != nil {
+		return nil, err
+	}
+	return f.sso.Redeem(context.Background(), handoff)
+}
+
+func TestSSO_ProvisionsUserAndSyncsRoles(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+
+	tokens, err := f.complete(t, map[string]any{
+		"sub": "idp-42", "preferred_username": "hannah@hoa.example", "groups": []string{"hoa-admins", "unrelated"},
+	})
+	require.NoError(t, err)
+	claims, err := f.verifier.Verify(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleOwner}, claims.Roles)
+
+	users, err := f.users.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "hannah@hoa.example", users[0].Username)
+	assert.Equal(t, claims.Subject, users[0].ID)
+	assert.Empty(t, users[0].PasswordHash)
+
+	// Group membership changed at the provider: the same account, new roles.
+	tokens, err = f.complete(t, map[string]any{"sub": "idp-42", "groups": "field"})
+	require.NoError(t, err)
+	claims, err = f.verifier.Verify(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, users[0].ID, claims.Subject)
+	assert.Equal(t, []string{domain.RoleTech}, claims.Roles)
+
+	_, err = f.users.Authenticate(ctx, "hannah@hoa.example", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "SSO accounts have no password")
+}
+
+func TestSSO_RefusesUnmappedUser(t *testing.T) {
+	f := newSSOFixture(t)
+	_, err := f.complete(t, map[string]any{"sub": "x", "groups": []string{"guests"}})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	users, _ := f.users.List(context.Background())
+	assert.Empty(t, users, "no account is created")
+	evs, err := f.audit.Query(context.Background(), domain.AuditFilter{EntityType: "session"})
+	require.NoError(t, err)
+	require.Len(t, evs, 1)
+	assert.Equal(t, "LOGIN_FAILED", evs[0].ActionType)
+}
+
+func TestSSO_StateIsSingleUseAndExpires(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+	claims := map[string]any{"sub": "idp-1", "groups": []string{"field"}}
+
+	state, code := f.login(t, claims)
+	_, err := f.sso.Complete(ctx, state, state, code)
+	require.NoError(t, err)
+	_, err = f.sso.Complete(ctx, state, state, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "replayed callback")
+
+	_, err = f.sso.Complete(ctx, "forged", "forged", code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	state, code = f.login(t, claims)
+	f.sso.now = func() time.Time { return time.Now().Add(ssoStateTTL + time.Second) }
+	_, err = f.sso.Complete(ctx, state, state, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestSSO_RefusesCallbackFromAnotherBrowser(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+	claims := map[string]any{"sub": "idp-1", "groups": []string{"field"}}
+
+	// An attacker's own callback URL, opened in a victim's browser that never started a
+	// login, or started a different one.
+	state, code := f.login(t, claims)
+	_, err := f.sso.Complete(ctx, state, "", code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	otherState, _ := f.login(t, claims)
+	_, err = f.sso.Complete(ctx, state, otherState, code)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	users, _ := f.users.List(ctx)
+	assert.Empty(t, users, "no account is created")
+}
+
+func TestSSO_HandoffIsSingleUseAndExpires(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+	claims := map[string]any{"sub": "idp-1", "groups": []string{"field"}}
+
+	state, code := f.login(t, claims)
+	handoff, err := f.sso.Complete(ctx, state, state, code)
+	require.NoError(t, err)
+	tokens, err := f.sso.Redeem(ctx, handoff)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	_, err = f.sso.Redeem(ctx, handoff)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "reused code")
+
+	state, code = f.login(t, claims)
+	handoff, err = f.sso.Complete(ctx, state, state, code)
+	require.NoError(t, err)
+	f.sso.now = func() time.Time { return time.Now().Add(ssoHandoffTTL + time.Second) }
+	_, err = f.sso.Redeem(ctx, handoff)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+// nonceProvider returns ID tokens whose nonce never matches, as a replayed token would.
+type nonceProvider struct{ IdentityProvider }
+
+func (p nonceProvider) Exchange(ctx context.Context, code, verifier string) (*auth.IDToken, error) {
+	tok, err := p.IdentityProvider.Exchange(ctx, code, verifier)
+	if err == nil {
+		tok.Nonce = "stale"
+	}
+	return tok, err
+}
+
+func TestSSO_RejectsNonceMismatch(t *testing.T) {
+	f := newSSOFixture(t)
+	f.sso.provider = nonceProvider{f.sso.provider}
+	_, err := f.complete(t, map[string]any{"sub": "idp-1", "groups": []string{"field"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+}
+
+func TestRoleMapping(t *testing.T) {
+	m := RoleMapping{
+		Claim:   "roles",
+		Map:     map[string][]string{"a": {domain.RoleTech}, "b": {domain.RoleOwner, domain.RoleDispatcher}},
+		Default: []string{domain.RoleCustomer},
+	}
+	assert.Equal(t, []string{domain.RoleOwner, domain.RoleDispatcher, domain.RoleTech},
+		m.Roles(map[string]any{"roles": []any{"a", "b", 7}}))
+	assert.Equal(t, []string{domain.RoleTech}, m.Roles(map[string]any{"roles": "a"}))
+	assert.Equal(t, []string{domain.RoleCustomer}, m.Roles(map[string]any{}))
+
+	assert.Equal(t, "e@x", DefaultRoleMapping.Username(map[string]any{"preferred_username": "", "email": "e@x"}))
+}
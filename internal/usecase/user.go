@@ -0,0 +1,373 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/password"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// LockoutPolicy locks an account for Duration after MaxFailures consecutive failed logins.
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+// DefaultLockoutPolicy locks an account for 15 minutes after 5 failed logins.
+var DefaultLockoutPolicy = LockoutPolicy{MaxFailures: 5, Duration: 15 * time.Minute}
+
+// CreateUserInput describes a new account.
+type CreateUserInput struct {
+	Username string
+	Password string
+	Roles    []string
+}
+
+// ExternalIdentity is a user authenticated by a single sign-on provider, with the roles
+// mapped from its claims.
+type ExternalIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Roles    []string
+}
+
+// UserService manages accounts and is the password Authenticator for sessions. Changes are
+// audited when an AuditService is configured; the offline CLI runs without one.
+type UserService struct {
+	logger   *zap.Logger
+	repo     domain.UserRepository
+	audit    *AuditService
+	lockout  LockoutPolicy
+	attempts loginAttempts
+
+	hashParams password.Params
+	policy     password.Policy
+	now        func() time.Time
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// NewUserService wires a UserService with the default hashing parameters, password policy
+// and lockout policy. audit may be nil.
+func NewUserService(logger *zap.Logger, repo domain.UserRepository, audit *AuditService, lockout LockoutPolicy) *UserService {
+	s := &UserService{
+		logger:     logger,
+		repo:       repo,
+		audit:      audit,
+		lockout:    lockout,
+		hashParams: password.DefaultParams,
+		policy:     password.DefaultPolicy,
+		now:        time.Now,
+	}
+	s.attempts = loginAttempts{users: repo, policy: lockout, onLock: s.lockedOut}
+	return s
+}
+
+// Authenticate checks a username and password. Unknown users, wrong passwords, disabled and
+// locked accounts all return domain.ErrInvalidCredentials; the wrapped message says which
+// for the audit log but is never shown to the caller.
+func (s *UserService) Authenticate(ctx context.Context, username, pw string) (*domain.Principal, error) {
+	u, err := s.repo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		// Spend the same time as a real check so response times do not reveal which
+		// usernames exist.
+		_, _ = password.Verify(pw, s.dummy())
+		return nil, fmt.Errorf("%w: unknown user", domain.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	now := s.now()
+	if u.Locked(now) {
+		return nil, fmt.Errorf("%w: account locked", domain.ErrInvalidCredentials)
+	}
+	if u.External() {
+		_, _ = password.Verify(pw, s.dummy())
+		return nil, fmt.Errorf("%w: single sign-on account", domain.ErrInvalidCredentials)
+	}
+	if err := s.attempts.begin(ctx, u.ID, now); err != nil {
+		return nil, err
+	}
+	ok, err := password.Verify(pw, u.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("verify password for %s: %w", u.ID, err)
+	}
+	if !ok {
+		return nil, s.attempts.fail(ctx, u.ID, now, "wrong password")
+	}
+	if u.Disabled {
+		return nil, fmt.Errorf("%w: account disabled", domain.ErrInvalidCredentials)
+	}
+	pass := s.attempts.succeed
+	if u.MFAEnrolled() {
+		pass = s.attempts.passFirstFactor
+	}
+	verified := u.PasswordHash
+	u, err = pass(ctx, u.ID, now, func(u *domain.User) error {
+		// Upgrade the hash unless the password was changed while this login was checked.
+		if u.PasswordHash == verified && password.NeedsRehash(verified, s.hashParams) {
+			if h, err := password.Hash(pw, s.hashParams); err == nil {
+				u.PasswordHash = h
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.Principal(), nil
+}
+
+// Principal returns the current roles of an enabled user.
+func (s *UserService) Principal(ctx context.Context, userID string) (*domain.Principal, error) {
+	u, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown user", domain.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if u.Disabled {
+		return nil, fmt.Errorf("%w: account disabled", domain.ErrInvalidCredentials)
+	}
+	return u.Principal(), nil
+}
+
+// ProvisionExternal returns the principal for a single sign-on login, creating the account
+// on first login. Roles come from the provider and replace the stored ones on every login,
+// so removing someone from a group at the provider takes effect at their next login.
+func (s *UserService) ProvisionExternal(ctx context.Context, id ExternalIdentity) (*domain.Principal, error) {
+	if err := validateRoles(id.Roles); err != nil {
+		return nil, err
+	}
+	u, err := s.repo.GetByExternalID(ctx, id.Issuer, id.Subject)
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.createExternal(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if u.Disabled {
+		return nil, fmt.Errorf("%w: account disabled", domain.ErrInvalidCredentials)
+	}
+	roles := slices.Clone(id.Roles)
+	slices.Sort(roles)
+	current := slices.Clone(u.Roles)
+	slices.Sort(current)
+	if !slices.Equal(roles, current) {
+		u.Roles = slices.Clone(id.Roles)
+		u.UpdatedAt = s.now().UTC()
+		if err := s.repo.Update(ctx, u); err != nil {
+			return nil, fmt.Errorf("update user: %w", err)
+		}
+		s.record(ctx, "ROLE_CHANGED", u, map[string]any{"roles": u.Roles, "issuer": id.Issuer})
+	}
+	return u.Principal(), nil
+}
+
+func (s *UserService) createExternal(ctx context.Context, id ExternalIdentity) (*domain.Principal, error) {
+	username := strings.TrimSpace(id.Username)
+	if username == "" {
+		username = id.Subject
+	}
+	now := s.now().UTC()
+	u := &domain.User{
+		ID:              uuid.NewString(),
+		Username:        username,
+		ExternalIssuer:  id.Issuer,
+		ExternalSubject: id.Subject,
+		Roles:           slices.Clone(id.Roles),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, fmt.Errorf("create user %q: %w", username, err)
+	}
+	s.record(ctx, "USER_CREATED", u, map[string]any{"username": u.Username, "roles": u.Roles, "issuer": id.Issuer})
+	return u.Principal(), nil
+}
+
+// Bootstrap creates the first OWNER. It returns domain.ErrConflict once any user exists.
+func (s *UserService) Bootstrap(ctx context.Context, username, pw string) (*domain.User, error) {
+	users, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	if len(users) > 0 {
+		return nil, fmt.Errorf("%w: users already exist; use `users add`", domain.ErrConflict)
+	}
+	return s.Create(ctx, CreateUserInput{Username: username, Password: pw, Roles: []string{domain.RoleOwner}})
+}
+
+// Create adds an account after checking the username, roles and password policy.
+func (s *UserService) Create(ctx context.Context, in CreateUserInput) (*domain.User, error) {
+	in.Username = strings.TrimSpace(in.Username)
+	if in.Username == "" {
+		return nil, fmt.Errorf("%w: username is required", domain.ErrInvalidInput)
+	}
+	if err := validateRoles(in.Roles); err != nil {
+		return nil, err
+	}
+	hash, err := s.hash(in.Username, in.Password)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	u := &domain.User{
+		ID:           uuid.NewString(),
+		Username:     in.Username,
+		PasswordHash: hash,
+		Roles:        slices.Clone(in.Roles),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, fmt.Errorf("create user %q: %w", in.Username, err)
+	}
+	s.record(ctx, "USER_CREATED", u, map[string]any{"username": u.Username, "roles": u.Roles})
+	return u, nil
+}
+
+// List returns every account.
+func (s *UserService) List(ctx context.Context) ([]domain.User, error) {
+	return s.repo.List(ctx)
+}
+
+// SetPassword replaces a user's password and clears any lockout. Single sign-on accounts
+// have no password.
+func (s *UserService) SetPassword(ctx context.Context, username, pw string) error {
+	return s.update(ctx, username, "PASSWORD_CHANGED", nil, func(u *domain.User) error {
+		if u.External() {
+			return fmt.Errorf("%w: %s signs in through single sign-on", domain.ErrInvalidInput, u.Username)
+		}
+		hash, err := s.hash(u.Username, pw)
+		if err != nil {
+			return err
+		}
+		u.PasswordHash, u.FailedLogins, u.LockedUntil = hash, 0, nil
+		return nil
+	})
+}
+
+// SetRoles replaces a user's roles (E-SEC-003: audited as ROLE_CHANGED).
+func (s *UserService) SetRoles(ctx context.Context, username string, roles []string) error {
+	if err := validateRoles(roles); err != nil {
+		return err
+	}
+	return s.update(ctx, username, "ROLE_CHANGED", map[string]any{"roles": roles}, func(u *domain.User) error {
+		u.Roles = slices.Clone(roles)
+		return nil
+	})
+}
+
+// SetDisabled disables or re-enables an account. A disabled account can not log in or
+// refresh.
+func (s *UserService) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	action := "USER_ENABLED"
+	if disabled {
+		action = "USER_DISABLED"
+	}
+	return s.update(ctx, username, action, nil, func(u *domain.User) error {
+		u.Disabled = disabled
+		return nil
+	})
+}
+
+// Unlock clears a lockout before it expires.
+func (s *UserService) Unlock(ctx context.Context, username string) error {
+	return s.update(ctx, username, "USER_UNLOCKED", nil, func(u *domain.User) error {
+		u.FailedLogins, u.LockedUntil = 0, nil
+		return nil
+	})
+}
+
+// ResetMFA removes a user's authenticator and recovery codes, e.g. after a lost phone. If
+// policy requires MFA for the user's roles, the next login enrolls a new authenticator.
+func (s *UserService) ResetMFA(ctx context.Context, username string) error {
+	return s.update(ctx, username, "MFA_RESET", nil, func(u *domain.User) error {
+		u.TOTPSecret, u.TOTPPendingSecret, u.TOTPLastStep, u.RecoveryCodeHashes = "", "", 0, nil
+		return nil
+	})
+}
+
+func (s *UserService) update(ctx context.Context, username, action string, metadata any, fn func(*domain.User) error) error {
+	found, err := s.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", username, err)
+	}
+	var fnErr error
+	u, err := s.repo.Modify(ctx, found.ID, func(u *domain.User) error {
+		if fnErr = fn(u); fnErr != nil {
+			return fnErr
+		}
+		u.UpdatedAt = s.now().UTC()
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("update user %q: %w", username, err)
+	}
+	s.record(ctx, action, u, metadata)
+	return nil
+}
+
+// lockedOut logs and audits an account locked by failed sign-in attempts.
+func (s *UserService) lockedOut(ctx context.Context, u *domain.User, until time.Time) {
+	requestctx.Enrich(ctx, s.logger).Warn("account locked after repeated failed logins",
+		zap.String("user_id", u.ID), zap.Time("locked_until", until))
+	s.record(ctx, "USER_LOCKED", u, map[string]any{"locked_until": until})
+}
+
+func (s *UserService) hash(username, pw string) (string, error) {
+	if err := s.policy.Check(username, pw); err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return password.Hash(pw, s.hashParams)
+}
+
+func (s *UserService) dummy() string {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, _ = password.Hash(uuid.NewString(), s.hashParams)
+	})
+	return s.dummyHash
+}
+
+// record audits a change to u. The actor is the authenticated caller, or the system actor
+// for the CLI and automatic lockouts.
+func (s *UserService) record(ctx context.Context, action string, u *domain.User, metadata any) {
+	if s.audit == nil {
+		return
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: action,
+		EntityType: "user", EntityID: u.ID, Metadata: metadata,
+	}); err != nil {
+		requestctx.Enrich(ctx, s.logger).Error("audit write failed", zap.String("action_type", action), zap.Error(err))
+	}
+}
+
+func validateRoles(roles []string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("%w: at least one role is required", domain.ErrInvalidInput)
+	}
+	for _, r := range roles {
+		if !slices.Contains(domain.AllRoles, r) {
+			return fmt.Errorf("%w: unknown role %q; must be one of %s", domain.ErrInvalidInput, r, strings.Join(domain.AllRoles, ", "))
+		}
+	}
+	return nil
+}
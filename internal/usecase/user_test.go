@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/password"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fastHashParams keep tests quick; production uses password.DefaultParams.
+var fastHashParams = password.Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newTestUserService(t *testing.T) (*UserService, *AuditService) {
+	t.Helper()
+	audit := NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	svc := NewUserService(zap.NewNop(), repository.NewInMemoryUserRepository(), audit, LockoutPolicy{MaxFailures: 3, Duration: 15 * time.Minute})
+	svc.hashParams = fastHashParams
+	return svc, audit
+}
+
+func TestUserService_BootstrapOnlyOnce(t *testing.T) {
+	svc, _ := newTestUserService(t)
+	ctx := context.Background()
+	u, err := svc.Bootstrap(ctx, "owner", "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleOwner}, u.Roles)
+	assert.Contains(t, u.PasswordHash, "$argon2id$")
+
+	_, err = svc.Bootstrap(ctx, "owner2", "correct horse battery")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestUserService_CreateValidates(t *testing.T) {
+	svc, _ := newTestUserService(t)
+	ctx := context.Background()
+	for name, in := range map[string]CreateUserInput{
+		"weak password": {Username: "bob", Password: "short", Roles: []string{domain.RoleTech}},
+		"no roles":      {Username: "bob", Password: "correct horse battery"},
+		"unknown role":  {Username: "bob", Password: "correct horse battery", Roles: []string{"ADMIN"}},
+		"no username":   {Password: "correct horse battery", Roles: []string{domain.RoleTech}},
+	} {
+		_, err := svc.Create(ctx, in)
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, name)
+	}
+	_, err := svc.Create(ctx, CreateUserInput{Username: "bob", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, CreateUserInput{Username: "BOB", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestUserService_AuthenticateAndLockout(t *testing.T) {
+	svc, audit := newTestUserService(t)
+	ctx := context.Background()
+	u, err := svc.Create(ctx, CreateUserInput{Username: "tina", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	require.NoError(t, err)
+
+	p, err := svc.Authenticate(ctx, "tina", "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, p.UserID)
+	assert.Equal(t, []string{domain.RoleTech}, p.Roles)
+
+	_, err = svc.Authenticate(ctx, "nobody", "correct horse battery")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	for range 3 {
+		_, err = svc.Authenticate(ctx, "tina", "wrong password!")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "locked even with the right password")
+	assert.ErrorContains(t, err, "locked")
+
+	locked, err := audit.Query(ctx, domain.AuditFilter{EntityID: u.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "USER_LOCKED", locked[0].ActionType)
+
+	svc.now = func() time.Time { return time.Now().Add(16 * time.Minute) }
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	assert.NoError(t, err, "lockout expires")
+}
+
+func TestUserService_ParallelGuessesShareTheLockoutBudget(t *testing.T) {
+	svc, audit := newTestUserService(t)
+	ctx := context.Background()
+	u, err := svc.Create(ctx, CreateUserInput{Username: "tina", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	require.NoError(t, err)
+
+	var checked atomic.Int32
+	var wg sync.WaitGroup
+	for range 30 {
+		wg.Go(func() {
+			_, err := svc.Authenticate(ctx, "tina", "wrong password!")
+			assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+			if strings.Contains(err.Error(), "wrong password") {
+				checked.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, int(checked.Load()), svc.lockout.MaxFailures, "no more guesses are checked than the policy allows")
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	assert.ErrorContains(t, err, "locked")
+	evs, err := audit.Query(ctx, domain.AuditFilter{EntityID: u.ID})
+	require.NoError(t, err)
+	locks := 0
+	for _, ev := range evs {
+		if ev.ActionType == "USER_LOCKED" {
+			locks++
+		}
+	}
+	assert.Equal(t, 1, locks)
+}
+
+func TestUserService_UnlockDisableAndRoles(t *testing.T) {
+	svc, audit := newTestUserService(t)
+	ctx := requestctx.WithUser(context.Background(), "owner-1", []string{domain.RoleOwner})
+	u, err := svc.Create(ctx, CreateUserInput{Username: "tina", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	require.NoError(t, err)
+	for range 3 {
+		_, _ = svc.Authenticate(ctx, "tina", "wrong password!")
+	}
+	require.NoError(t, svc.Unlock(ctx, "tina"))
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SetRoles(ctx, "tina", []string{domain.RoleDispatcher}))
+	p, err := svc.Principal(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleDispatcher}, p.Roles, "refresh picks up role changes")
+
+	require.NoError(t, svc.SetDisabled(ctx, "tina", true))
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, err = svc.Principal(ctx, u.ID)
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials, "disabled users can not refresh")
+
+	require.NoError(t, svc.SetPassword(ctx, "tina", "a brand new passphrase"))
+	assert.ErrorIs(t, svc.SetPassword(ctx, "tina", "short"), domain.ErrInvalidInput)
+	assert.ErrorIs(t, svc.Unlock(ctx, "ghost"), domain.ErrNotFound)
+
+	evs, err := audit.Query(ctx, domain.AuditFilter{ActorID: "owner-1", EntityType: "user"})
+	require.NoError(t, err)
+	var actions []string
+	for _, ev := range evs {
+		actions = append(actions, ev.ActionType)
+	}
+	assert.Subset(t, actions, []string{"USER_CREATED", "USER_UNLOCKED", "ROLE_CHANGED", "USER_DISABLED", "PASSWORD_CHANGED"})
+}
+
+func TestUserService_RehashesOutdatedHash(t *testing.T) {
+	svc, _ := newTestUserService(t)
+	ctx := context.Background()
+	u, err := svc.Create(ctx, CreateUserInput{Username: "tina", Password: "correct horse battery", Roles: []string{domain.RoleTech}})
+	require.NoError(t, err)
+
+	svc.hashParams.Iterations = 2
+	_, err = svc.Authenticate(ctx, "tina", "correct horse battery")
+	require.NoError(t, err)
+	stored, err := svc.repo.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Contains(t, stored.PasswordHash, "t=2")
+}
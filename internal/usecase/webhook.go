@@ -0,0 +1,460 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mgmacri/pool-maintenance-app/internal/auth"
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/requestctx"
+	"github.com/mgmacri/pool-maintenance-app/pkg/webhooksig"
+	"go.uber.org/zap"
+)
+
+// WebhookConfig tunes delivery retries and the background dispatcher.
+type WebhookConfig struct {
+	// MaxAttempts is the total number of HTTP attempts before a delivery is dead-lettered.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry; it doubles for every subsequent attempt.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the exponential delay.
+	MaxBackoff time.Duration
+	// PollInterval is how often the dispatcher looks for due deliveries.
+	PollInterval time.Duration
+	// BatchSize bounds how many due deliveries are processed per poll.
+	BatchSize int
+	// Timeout bounds a single HTTP attempt.
+	Timeout time.Duration
+	// SecretGracePeriod is how long a rotated-out secret keeps signing deliveries.
+	SecretGracePeriod time.Duration
+}
+
+// DefaultWebhookConfig returns conservative defaults: 8 attempts spread over roughly an hour.
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		MaxAttempts:       8,
+		BaseBackoff:       30 * time.Second,
+		MaxBackoff:        30 * time.Minute,
+		PollInterval:      5 * time.Second,
+		BatchSize:         50,
+		Timeout:           10 * time.Second,
+		SecretGracePeriod: 24 * time.Hour,
+	}
+}
+
+// WebhookService fans events out to subscriptions, delivers them with signed requests and
+// retries failures with exponential backoff until they succeed or are dead-lettered.
+type WebhookService struct {
+	logger     *zap.Logger
+	subs       domain.WebhookSubscriptionRepository
+	deliveries domain.WebhookDeliveryRepository
+	audit      *AuditService
+	client     *http.Client
+	cfg        WebhookConfig
+
+	now    func() time.Time
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewWebhookService wires a WebhookService. A nil client gets one with cfg.Timeout.
+func NewWebhookService(logger *zap.Logger, subs domain.WebhookSubscriptionRepository, deliveries domain.WebhookDeliveryRepository, audit *AuditService, client *http.Client, cfg WebhookConfig) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &WebhookService{
+		logger:     logger,
+		subs:       subs,
+		deliveries: deliveries,
+		audit:      audit,
+		client:     client,
+		cfg:        cfg,
+		now:        time.Now,
+		jitter:     equalJitter,
+	}
+}
+
+// CreateWebhookSubscriptionInput describes a new subscription. Empty EventTypes subscribes
+// to every event.
+type CreateWebhookSubscriptionInput struct {
+	TargetURL  string
+	EventTypes []string
+}
+
+// CreateSubscription registers an active subscription and returns it with its signing
+// secret, which the subscription JSON never includes and which is not shown again.
+func (s *WebhookService) CreateSubscription(ctx context.Context, in CreateWebhookSubscriptionInput) (*domain.WebhookSubscription, string, error) {
+	if err := validateTargetURL(in.TargetURL); err != nil {
+		return nil, "", err
+	}
+	eventTypes := make([]string, 0, len(in.EventTypes))
+	for _, t := range in.EventTypes {
+		if !slices.Contains(domain.EventTypes, t) {
+			return nil, "", domain.Validation("", domain.FieldError{Field: "event_types", Message: fmt.Sprintf("unknown event type %q", t)})
+		}
+		if !slices.Contains(eventTypes, t) {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	secret, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	sub := &domain.WebhookSubscription{
+		ID:         uuid.NewString(),
+		TargetURL:  in.TargetURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.subs.Create(ctx, sub); err != nil {
+		return nil, "", fmt.Errorf("store webhook subscription: %w", err)
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "WEBHOOK_SUBSCRIPTION_CREATED", EntityType: "webhook_subscription", EntityID: sub.ID,
+		Metadata: map[string]any{"target_url": sub.TargetURL, "event_types": sub.EventTypes},
+	}); err != nil {
+		return nil, "", fmt.Errorf("audit webhook subscription: %w", err)
+	}
+	return sub, secret, nil
+}
+
+// ListSubscriptions returns every subscription, newest first, without secrets.
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.subs.List(ctx)
+}
+
+// DeleteSubscription removes a subscription. Deliveries still queued for it are
+// dead-lettered when they come due.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	sub, err := s.subs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.subs.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "WEBHOOK_SUBSCRIPTION_DELETED", EntityType: "webhook_subscription", EntityID: sub.ID,
+		Metadata: map[string]string{"target_url": sub.TargetURL},
+	}); err != nil {
+		return fmt.Errorf("audit webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// RotateSecret gives the subscription a new signing secret and returns it; like the one from
+// CreateSubscription it is not shown again. The old secret keeps signing deliveries alongside
+// the new one for SecretGracePeriod, so the receiver can switch over without rejecting any.
+// Rotating again within the grace period retires the oldest secret at once.
+func (s *WebhookService) RotateSecret(ctx context.Context, id string) (*domain.WebhookSubscription, string, error) {
+	secret, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	expiresAt := s.now().UTC().Add(s.cfg.SecretGracePeriod)
+	sub, err := s.subs.Modify(ctx, id, func(sub *domain.WebhookSubscription) error {
+		sub.PreviousSecret = sub.Secret
+		sub.PreviousSecretExpiresAt = &expiresAt
+		sub.Secret = secret
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	actorID, actorRole := actorFromContext(ctx)
+	if _, err := s.audit.Record(ctx, AuditEntry{
+		ActorID: actorID, ActorRole: actorRole, ActionType: "WEBHOOK_SECRET_ROTATED", EntityType: "webhook_subscription", EntityID: sub.ID,
+		Metadata: map[string]string{"previous_secret_expires_at": expiresAt.Format(time.RFC3339)},
+	}); err != nil {
+		return nil, "", fmt.Errorf("audit webhook secret rotation: %w", err)
+	}
+	requestctx.Enrich(ctx, s.logger).Info("webhook secret rotated", zap.String("subscription_id", sub.ID), zap.Time("previous_secret_expires_at", expiresAt))
+	return sub, secret, nil
+}
+
+// ExpirePreviousSecrets clears the rotated-out secrets whose grace period is over and returns
+// how many it cleared. SigningSecrets already ignores them; this drops them from storage.
+func (s *WebhookService) ExpirePreviousSecrets(ctx context.Context) (int, error) {
+	subs, err := s.subs.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list subscriptions: %w", err)
+	}
+	now := s.now()
+	expired := func(sub *domain.WebhookSubscription) bool {
+		return sub.PreviousSecretExpiresAt != nil && !now.Before(*sub.PreviousSecretExpiresAt)
+	}
+	n := 0
+	for _, sub := range subs {
+		if !expired(&sub) {
+			continue
+		}
+		cleared := false
+		_, err := s.subs.Modify(ctx, sub.ID, func(sub *domain.WebhookSubscription) error {
+			// A rotation since the listing set a new grace period; leave that one alone.
+			if expired(sub) {
+				sub.PreviousSecret = ""
+				sub.PreviousSecretExpiresAt = nil
+				cleared = true
+			}
+			return nil
+		})
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return n, fmt.Errorf("clear previous secret of %s: %w", sub.ID, err)
+		}
+		if cleared {
+			n++
+			s.logger.Info("webhook previous secret expired", zap.String("subscription_id", sub.ID))
+		}
+	}
+	return n, nil
+}
+
+// validateTargetURL accepts absolute http and https URLs without credentials or fragment.
+func validateTargetURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return domain.Validation("", domain.FieldError{Field: "target_url", Message: "must be an absolute http or https URL without credentials or fragment"})
+	}
+	return nil
+}
+
+// Enqueue persists one PENDING delivery per subscription listening for eventType.
+func (s *WebhookService) Enqueue(ctx context.Context, eventID, eventType string, payload []byte) ([]domain.WebhookDelivery, error) {
+	subs, err := s.subs.ListForEvent(ctx, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("list subscriptions: %w", err)
+	}
+	out := make([]domain.WebhookDelivery, 0, len(subs))
+	for _, sub := range subs {
+		d := s.newDelivery(sub.ID, eventID, eventType, payload)
+		if err := s.deliveries.Create(ctx, &d); err != nil {
+			return out, fmt.Errorf("create delivery: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// Name implements EventSink.
+func (s *WebhookService) Name() string { return "webhooks" }
+
+// HandleEvent implements EventSink: the event envelope becomes the payload of one delivery per
+// matching subscription. Delivery is at-least-once; receivers dedupe on the envelope id.
+func (s *WebhookService) HandleEvent(ctx context.Context, ev domain.Event) error {
+	envelope, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	_, err = s.Enqueue(ctx, ev.ID, ev.Type, envelope)
+	return err
+}
+
+// ListDeliveries returns delivery history matching the filter, newest first.
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	return s.deliveries.List(ctx, filter)
+}
+
+// GetDelivery returns a single delivery with its attempt history.
+func (s *WebhookService) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return s.deliveries.Get(ctx, id)
+}
+
+// Redeliver queues a fresh copy of an existing delivery with a full retry budget.
+// The original is left untouched so its history stays intact.
+func (s *WebhookService) Redeliver(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	orig, err := s.deliveries.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d := s.newDelivery(orig.SubscriptionID, orig.EventID, orig.EventType, orig.Payload)
+	d.RedeliveryOf = orig.ID
+	if err := s.deliveries.Create(ctx, &d); err != nil {
+		return nil, fmt.Errorf("create delivery: %w", err)
+	}
+	requestctx.Enrich(ctx, s.logger).Info("webhook redelivery queued", zap.String("delivery_id", d.ID), zap.String("redelivery_of", orig.ID))
+	return &d, nil
+}
+
+// Run polls for due deliveries, and clears expired previous secrets, until ctx is canceled.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			s.logger.Error("webhook dispatch failed", zap.Error(err))
+		}
+		if _, err := s.ExpirePreviousSecrets(ctx); err != nil {
+			s.logger.Error("webhook secret expiry failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue attempts every delivery that is currently due and returns how many were attempted.
+func (s *WebhookService) ProcessDue(ctx context.Context) (int, error) {
+	due, err := s.deliveries.ListDue(ctx, s.now(), s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list due deliveries: %w", err)
+	}
+	for i := range due {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		if err := s.attempt(ctx, &due[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
+
+// attempt performs one HTTP attempt and persists the resulting state transition.
+func (s *WebhookService) attempt(ctx context.Context, d *domain.WebhookDelivery) error {
+	sub, err := s.subs.Get(ctx, d.SubscriptionID)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && !sub.Active) {
+		// Nothing left to deliver to; park it rather than retrying forever.
+		return s.deadLetter(ctx, d, "subscription not found or inactive")
+	}
+	if err != nil {
+		return fmt.Errorf("load subscription %s: %w", d.SubscriptionID, err)
+	}
+
+	start := s.now()
+	statusCode, sendErr := s.send(ctx, sub, d, start)
+	d.AttemptCount++
+	d.Attempts = append(d.Attempts, domain.WebhookDeliveryAttempt{
+		Number:      d.AttemptCount,
+		AttemptedAt: start,
+		StatusCode:  statusCode,
+		Error:       errString(sendErr),
+		DurationMS:  s.now().Sub(start).Milliseconds(),
+	})
+	d.UpdatedAt = s.now()
+	at := d.UpdatedAt
+
+	log := s.logger.With(
+		zap.String("delivery_id", d.ID),
+		zap.String("subscription_id", d.SubscriptionID),
+		zap.String("event_type", d.EventType),
+		zap.Int("attempt", d.AttemptCount),
+		zap.Int("status_code", statusCode),
+	)
+	switch {
+	case sendErr == nil:
+		d.Status = domain.WebhookDeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &at
+		log.Info("webhook delivered")
+	case d.AttemptCount >= s.cfg.MaxAttempts:
+		d.Status = domain.WebhookDeliveryDeadLettered
+		d.LastError = sendErr.Error()
+		d.DeadLetteredAt = &at
+		log.Warn("webhook dead-lettered", zap.Error(sendErr))
+	default:
+		d.Status = domain.WebhookDeliveryFailed
+		d.LastError = sendErr.Error()
+		d.NextAttemptAt = d.UpdatedAt.Add(s.backoff(d.AttemptCount))
+		log.Info("webhook attempt failed; retry scheduled", zap.Error(sendErr), zap.Time("next_attempt_at", d.NextAttemptAt))
+	}
+	return s.deliveries.Update(ctx, d)
+}
+
+// deadLetter moves a delivery straight to DEAD_LETTERED without attempting it.
+func (s *WebhookService) deadLetter(ctx context.Context, d *domain.WebhookDelivery, reason string) error {
+	at := s.now()
+	d.Status = domain.WebhookDeliveryDeadLettered
+	d.LastError = reason
+	d.UpdatedAt = at
+	d.DeadLetteredAt = &at
+	requestctx.Enrich(ctx, s.logger).Warn("webhook dead-lettered", zap.String("delivery_id", d.ID), zap.String("reason", reason))
+	return s.deliveries.Update(ctx, d)
+}
+
+// send posts the signed payload. A non-2xx response is reported as an error.
+func (s *WebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, d *domain.WebhookDelivery, ts time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.TargetURL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooksig.IDHeader, d.ID)
+	req.Header.Set(webhooksig.EventHeader, d.EventType)
+	req.Header.Set(webhooksig.TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set(webhooksig.SignatureHeader, webhooksig.SignAll(sub.SigningSecrets(ts), ts, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the jittered delay before the retry that follows attempt n (1-based).
+func (s *WebhookService) backoff(n int) time.Duration {
+	d := s.cfg.BaseBackoff
+	for i := 1; i < n && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+	return s.jitter(d)
+}
+
+func (s *WebhookService) newDelivery(subscriptionID, eventID, eventType string, payload []byte) domain.WebhookDelivery {
+	now := s.now()
+	return domain.WebhookDelivery{
+		ID:             uuid.NewString(),
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		EventType:      eventType,
+		Payload:        append([]byte(nil), payload...),
+		Status:         domain.WebhookDeliveryPending,
+		NextAttemptAt:  now,
+		Attempts:       []domain.WebhookDeliveryAttempt{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// equalJitter keeps half of d and randomizes the other half so retries from many
+// deliveries that failed together do not hit the receiver in lockstep.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
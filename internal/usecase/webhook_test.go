@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mgmacri/pool-maintenance-app/internal/domain"
+	"github.com/mgmacri/pool-maintenance-app/internal/repository"
+	"github.com/mgmacri/pool-maintenance-app/pkg/webhooksig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeClock is a manually advanced time source for deterministic retry tests.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestWebhookService returns a service pointed at targetURL with no jitter and a fake clock.
+func newTestWebhookService(t *testing.T, targetURL string, cfg WebhookConfig) (*WebhookService, *fakeClock) {
+	t.Helper()
+	subs := repository.NewInMemoryWebhookSubscriptionRepository()
+	require.NoError(t, subs.Create(context.Background(), &domain.WebhookSubscription{
+		ID:         "sub-1",
+		TargetURL:  targetURL,
+		Secret:     "s3cret",
+		EventTypes: []string{"JobCompleted"},
+		Active:     true,
+	}))
+	svc := NewWebhookService(zap.NewNop(), subs, repository.NewInMemoryWebhookDeliveryRepository(), nil, nil, cfg)
+	clock := &fakeClock{t: time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)}
+	svc.now = clock.Now
+	svc.jitter = func(d time.Duration) time.Duration { return d }
+	return svc, clock
+}
+
+func testWebhookConfig() WebhookConfig {
+	cfg := DefaultWebhookConfig()
+	cfg.MaxAttempts = 3
+	cfg.BaseBackoff = time.Second
+	cfg.MaxBackoff = 10 * time.Second
+	return cfg
+}
+
+func TestWebhookService_DeliversSignedRequest(t *testing.T) {
+	var gotSig, gotTS, gotEvent string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(webhooksig.SignatureHeader)
+		gotTS = r.Header.Get(webhooksig.TimestampHeader)
+		gotEvent = r.Header.Get(webhooksig.EventHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	svc, clock := newTestWebhookService(t, srv.URL, testWebhookConfig())
+	ctx := context.Background()
+	queued, err := svc.Enqueue(ctx, "evt-1", "JobCompleted", []byte(`{"job_id":"j1"}`))
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+
+	n, err := svc.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, "JobCompleted", gotEvent)
+	assert.Equal(t, `{"job_id":"j1"}`, string(gotBody))
+	assert.Equal(t, strconv.FormatInt(clock.Now().Unix(), 10), gotTS)
+	assert.Equal(t, webhooksig.Sign("s3cret", clock.Now(), gotBody), gotSig)
+	verifier := &webhooksig.Verifier{Secrets: []string{"s3cret"}, Now: clock.Now}
+	assert.NoError(t, verifier.Verify(gotTS, gotSig, gotBody), "reference verifier must accept what the sender produced")
+
+	d, err := svc.GetDelivery(ctx, queued[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+	assert.Equal(t, 1, d.AttemptCount)
+	require.NotNil(t, d.DeliveredAt)
+	require.Len(t, d.Attempts, 1)
+	assert.Equal(t, http.StatusNoContent, d.Attempts[0].StatusCode)
+}
+
+func TestWebhookService_RotateSecretSignsWithBothUntilGracePeriodEnds(t *testing.T) {
+	var gotSig, gotTS string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(webhooksig.SignatureHeader)
+		gotTS = r.Header.Get(webhooksig.TimestampHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := testWebhookConfig()
+	cfg.SecretGracePeriod = time.Hour
+	svc, clock := newTestWebhookService(t, srv.URL, cfg)
+	auditRepo := repository.NewInMemoryAuditRepository()
+	svc.audit = NewAuditService(zap.NewNop(), auditRepo)
+	ctx := context.Background()
+	deliver := func() {
+		t.Helper()
+		_, err := svc.Enqueue(ctx, "evt-1", "JobCompleted", []byte(`{"job_id":"j1"}`))
+		require.NoError(t, err)
+		n, err := svc.ProcessDue(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	}
+	verifies := func(secret string) bool {
+		v := &webhooksig.Verifier{Secrets: []string{secret}, Now: clock.Now}
+		return v.Verify(gotTS, gotSig, gotBody) == nil
+	}
+
+	sub, secret, err := svc.RotateSecret(ctx, "sub-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, "s3cret", secret)
+	require.NotNil(t, sub.PreviousSecretExpiresAt)
+	assert.Equal(t, clock.Now().Add(time.Hour), *sub.PreviousSecretExpiresAt)
+
+	deliver()
+	assert.True(t, verifies(secret))
+	assert.True(t, verifies("s3cret"), "the old secret still verifies during the grace period")
+
+	clock.Advance(time.Hour)
+	deliver()
+	assert.True(t, verifies(secret))
+	assert.False(t, verifies("s3cret"), "the old secret no longer signs once the grace period is over")
+
+	n, err := svc.ExpirePreviousSecrets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	stored, err := svc.subs.Get(ctx, "sub-1")
+	require.NoError(t, err)
+	assert.Empty(t, stored.PreviousSecret)
+	assert.Nil(t, stored.PreviousSecretExpiresAt)
+	assert.Equal(t, secret, stored.Secret)
+
+	trail, _ := auditRepo.ListChain(ctx)
+	require.Len(t, trail, 1)
+	assert.Equal(t, "WEBHOOK_SECRET_ROTATED", trail[0].ActionType)
+
+	_, _, err = svc.RotateSecret(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestWebhookService_ExpirePreviousSecretsKeepsThoseInGracePeriod(t *testing.T) {
+	cfg := testWebhookConfig()
+	cfg.SecretGracePeriod = time.Hour
+	svc, clock := newTestWebhookService(t, "http://127.0.0.1:0", cfg)
+	svc.audit = NewAuditService(zap.NewNop(), repository.NewInMemoryAuditRepository())
+	ctx := context.Background()
+	_, _, err := svc.RotateSecret(ctx, "sub-1")
+	require.NoError(t, err)
+
+	clock.Advance(59 * time.Minute)
+	n, err := svc.ExpirePreviousSecrets(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	stored, err := svc.subs.Get(ctx, "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", stored.PreviousSecret)
+}
+
+func TestWebhookService_RetriesWithBackoffThenDelivers(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc, clock := newTestWebhookService(t, srv.URL, testWebhookConfig())
+	ctx := context.Background()
+	queued, err := svc.Enqueue(ctx, "evt-1", "JobCompleted", []byte(`{}`))
+	require.NoError(t, err)
+
+	_, err = svc.ProcessDue(ctx)
+	require.NoError(t, err)
+	d, _ := svc.GetDelivery(ctx, queued[0].ID)
+	assert.Equal(t, domain.WebhookDeliveryFailed, d.Status)
+	assert.Equal(t, "unexpected status 502", d.LastError)
+	assert.Equal(t, clock.Now().Add(time.Second), d.NextAttemptAt)
+
+	// Not due yet: nothing is attempted.
+	n, err := svc.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	clock.Advance(time.Second)
+	n, err = svc.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	d, _ = svc.GetDelivery(ctx, queued[0].ID)
+	assert.Equal(t, domain.WebhookDeliveryDelivered, d.Status)
+	assert.Equal(t, 2, d.AttemptCount)
+	assert.Empty(t, d.LastError)
+}
+
+func TestWebhookService_DeadLettersAfterMaxAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	svc, clock := newTestWebhookService(t, srv.URL, testWebhookConfig())
+	ctx := context.Background()
+	queued, err := svc.Enqueue(ctx, "evt-1", "JobCompleted", []byte(`{}`))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := svc.ProcessDue(ctx)
+		require.NoError(t, err)
+		clock.Advance(time.Minute)
+	}
+
+	d, _ := svc.GetDelivery(ctx, queued[0].ID)
+	assert.Equal(t, domain.WebhookDeliveryDeadLettered, d.Status)
+	assert.Equal(t, 3, d.AttemptCount)
+	assert.Len(t, d.Attempts, 3)
+	assert.NotNil(t, d.DeadLetteredAt)
+}
+
+func TestWebhookService_RedeliverCreatesFreshDelivery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc, _ := newTestWebhookService(t, srv.URL, testWebhookConfig())
+	ctx := context.Background()
+	queued, err := svc.Enqueue(ctx, "evt-1", "JobCompleted", []byte(`{"a":1}`))
+	require.NoError(t, err)
+	_, err = svc.ProcessDue(ctx)
+	require.NoError(t, err)
+
+	re, err := svc.Redeliver(ctx, queued[0].ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, queued[0].ID, re.ID)
+	assert.Equal(t, queued[0].ID, re.RedeliveryOf)
+	assert.Equal(t, domain.WebhookDeliveryPending, re.Status)
+	assert.JSONEq(t, `{"a":1}`, string(re.Payload))
+
+	_, err = svc.Redeliver(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestWebhookService_Backoff(t *testing.T) {
+	svc, _ := newTestWebhookService(t, "http://unused", testWebhookConfig())
+	assert.Equal(t, time.Second, svc.backoff(1))
+	assert.Equal(t, 2*time.Second, svc.backoff(2))
+	assert.Equal(t, 8*time.Second, svc.backoff(4))
+	assert.Equal(t, 10*time.Second, svc.backoff(10), "capped at MaxBackoff")
+}
+
+func TestEqualJitter_StaysWithinBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := equalJitter(10 * time.Second)
+		assert.GreaterOrEqual(t, d, 5*time.Second)
+		assert.Less(t, d, 10*time.Second)
+	}
+}
@@ -0,0 +1,212 @@
+// Package webhooksig signs and verifies Pool Maintenance API webhook deliveries.
+//
+// The sender and this verifier share the same code, so a receiver that uses
+// Verifier is guaranteed to agree with what the server produced.
+//
+// Each delivery carries two headers:
+//
+//	X-Webhook-Timestamp: 1759665600
+//	X-Webhook-Signature: v1=<64 hex chars>
+//
+// The v1 signature is hex(HMAC-SHA256(secret, "<timestamp>.<raw body>")). The
+// signature header may carry several comma-separated entries (for example while a
+// secret is being rotated); a delivery is valid if any entry matches any secret.
+//
+// Typical receiver usage:
+//
+//	v := webhooksig.NewVerifier(os.Getenv("WEBHOOK_SECRET"))
+//	body, err := v.VerifyRequest(r)
+//	if err != nil {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//		return
+//	}
+package webhooksig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	IDHeader        = "X-Webhook-Id"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// SchemeV1 is the only signature scheme currently emitted.
+const SchemeV1 = "v1"
+
+// DefaultTolerance is the replay window required by E-SEC-006.
+const DefaultTolerance = 5 * time.Minute
+
+// MaxBodyBytes bounds how much of a request body VerifyRequest will read. Larger bodies fail
+// with ErrBodyTooLarge rather than being verified in part.
+const MaxBodyBytes = 1 << 20
+
+// Verification errors. Use errors.Is to tell them apart.
+var (
+	ErrNoSecrets         = errors.New("webhooksig: no secrets configured")
+	ErrMissingTimestamp  = errors.New("webhooksig: missing timestamp header")
+	ErrMissingSignature  = errors.New("webhooksig: missing signature header")
+	ErrInvalidTimestamp  = errors.New("webhooksig: timestamp is not unix seconds")
+	ErrTimestampExpired  = errors.New("webhooksig: timestamp outside tolerance")
+	ErrSignatureMismatch = errors.New("webhooksig: no signature matched")
+	ErrBodyTooLarge      = fmt.Errorf("webhooksig: body exceeds %d bytes", MaxBodyBytes)
+)
+
+// Sign returns the signature header value for body sent at ts: "v1=<hex>".
+func Sign(secret string, ts time.Time, body []byte) string {
+	return SchemeV1 + "=" + hex.EncodeToString(compute(secret, strconv.FormatInt(ts.Unix(), 10), body))
+}
+
+// SignAll returns a header value with one v1 entry per secret, in order. Senders use it
+// during rotation so receivers holding either the old or new secret can verify.
+func SignAll(secrets []string, ts time.Time, body []byte) string {
+	parts := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		parts = append(parts, Sign(s, ts, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verifier checks delivery signatures against one or more active secrets.
+type Verifier struct {
+	// Secrets are tried in order; keep the newest first during rotation.
+	Secrets []string
+	// Tolerance is the maximum allowed clock skew in either direction. Zero means DefaultTolerance.
+	Tolerance time.Duration
+	// Now returns the current time. Nil means time.Now.
+	Now func() time.Time
+}
+
+// NewVerifier returns a Verifier for the given secrets with the default 5-minute window.
+func NewVerifier(secrets ...string) *Verifier {
+	return &Verifier{Secrets: secrets}
+}
+
+// Verify checks the timestamp and signature header values against body.
+// Empty secrets are ignored so an unset environment variable never acts as a valid key.
+func (v *Verifier) Verify(timestamp, signature string, body []byte) error {
+	secrets := v.activeSecrets()
+	if len(secrets) == 0 {
+		return ErrNoSecrets
+	}
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return ErrMissingTimestamp
+	}
+	if strings.TrimSpace(signature) == "" {
+		return ErrMissingSignature
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	skew := v.now().Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.tolerance() {
+		return fmt.Errorf("%w: skew %s", ErrTimestampExpired, skew.Truncate(time.Second))
+	}
+
+	candidates := parseSignatures(signature)
+	for _, secret := range secrets {
+		expected := compute(secret, timestamp, body)
+		for _, got := range candidates {
+			if hmac.Equal(expected, got) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads and verifies r's body, returning it. The body is restored on r so
+// downstream handlers can read it again. A body over MaxBodyBytes fails with ErrBodyTooLarge,
+// which receivers should answer with 413 rather than 401.
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := ReadBody(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := v.Verify(r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// ReadBody reads a delivery body of at most MaxBodyBytes from rd. It returns ErrBodyTooLarge
+// instead of a truncated body, whose signature could never match.
+func ReadBody(rd io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(rd, MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhooksig: read body: %w", err)
+	}
+	if len(body) > MaxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+func (v *Verifier) activeSecrets() []string {
+	out := make([]string, 0, len(v.Secrets))
+	for _, s := range v.Secrets {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func (v *Verifier) tolerance() time.Duration {
+	if v.Tolerance > 0 {
+		return v.Tolerance
+	}
+	return DefaultTolerance
+}
+
+// parseSignatures extracts decoded v1 digests from a header like "v1=ab12,v1=cd34".
+// Unknown schemes and malformed entries are skipped.
+func parseSignatures(header string) [][]byte {
+	var out [][]byte
+	for _, part := range strings.Split(header, ",") {
+		scheme, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || scheme != SchemeV1 {
+			continue
+		}
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func compute(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,163 @@
+package webhooksig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedNow = time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return fixedNow }
+
+func TestVerify_AcceptsValidSignature(t *testing.T) {
+	body := []byte(`{"job_id":"j1"}`)
+	sig := Sign("s3cret", fixedNow, body)
+	v := &Verifier{Secrets: []string{"s3cret"}, Now: fixedClock}
+	assert.NoError(t, v.Verify(strconv.FormatInt(fixedNow.Unix(), 10), sig, body))
+}
+
+func TestVerify_KnownVector(t *testing.T) {
+	// Pin the wire format so an accidental change to the signed content is caught.
+	ts := time.Unix(1759665600, 0)
+	got := Sign("whsec_test", ts, []byte(`{"hello":"world"}`))
+	assert.Equal(t, "v1=0671b763c05cd3136f760ead4e1f07a4fbe5f5319a0cb4e6e992ef2fb5252747", got)
+}
+
+func TestVerify_Failures(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	ts := strconv.FormatInt(fixedNow.Unix(), 10)
+	good := Sign("s3cret", fixedNow, body)
+
+	cases := []struct {
+		name    string
+		secrets []string
+		ts, sig string
+		body    []byte
+		want    error
+	}{
+		{"no secrets", nil, ts, good, body, ErrNoSecrets},
+		{"only empty secrets", []string{"", ""}, ts, Sign("", fixedNow, body), body, ErrNoSecrets},
+		{"missing timestamp", []string{"s3cret"}, "", good, body, ErrMissingTimestamp},
+		{"missing signature", []string{"s3cret"}, ts, "", body, ErrMissingSignature},
+		{"non numeric timestamp", []string{"s3cret"}, "yesterday", good, body, ErrInvalidTimestamp},
+		{"wrong secret", []string{"other"}, ts, good, body, ErrSignatureMismatch},
+		{"tampered body", []string{"s3cret"}, ts, good, []byte(`{"a":2}`), ErrSignatureMismatch},
+		{"tampered timestamp", []string{"s3cret"}, strconv.FormatInt(fixedNow.Unix()-1, 10), good, body, ErrSignatureMismatch},
+		{"unknown scheme", []string{"s3cret"}, ts, "v0=" + good[3:], body, ErrSignatureMismatch},
+		{"garbage hex", []string{"s3cret"}, ts, "v1=zz", body, ErrSignatureMismatch},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &Verifier{Secrets: tc.secrets, Now: fixedClock}
+			err := v.Verify(tc.ts, tc.sig, tc.body)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestVerify_ReplayWindow(t *testing.T) {
+	body := []byte(`{}`)
+	v := &Verifier{Secrets: []string{"s3cret"}, Now: fixedClock}
+
+	for _, skew := range []time.Duration{-5 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		sent := fixedNow.Add(-skew)
+		err := v.Verify(strconv.FormatInt(sent.Unix(), 10), Sign("s3cret", sent, body), body)
+		assert.NoError(t, err, "skew %s should be accepted", skew)
+	}
+	for _, skew := range []time.Duration{-6 * time.Minute, 5*time.Minute + time.Second, time.Hour} {
+		sent := fixedNow.Add(-skew)
+		err := v.Verify(strconv.FormatInt(sent.Unix(), 10), Sign("s3cret", sent, body), body)
+		assert.ErrorIs(t, err, ErrTimestampExpired, "skew %s should be rejected", skew)
+	}
+}
+
+func TestVerify_RotationWithMultipleSecrets(t *testing.T) {
+	body := []byte(`{}`)
+	ts := strconv.FormatInt(fixedNow.Unix(), 10)
+
+	// Receiver holds old and new secrets while the sender still signs with the old one.
+	v := &Verifier{Secrets: []string{"new", "old"}, Now: fixedClock}
+	assert.NoError(t, v.Verify(ts, Sign("old", fixedNow, body), body))
+
+	// Sender dual-signs; a receiver that only knows either secret verifies.
+	both := SignAll([]string{"new", "old"}, fixedNow, body)
+	for _, secret := range []string{"new", "old"} {
+		v := &Verifier{Secrets: []string{secret}, Now: fixedClock}
+		assert.NoError(t, v.Verify(ts, both, body), "secret %q", secret)
+	}
+	v = &Verifier{Secrets: []string{"retired"}, Now: fixedClock}
+	assert.ErrorIs(t, v.Verify(ts, both, body), ErrSignatureMismatch)
+}
+
+func TestVerifyRequest_RestoresBody(t *testing.T) {
+	body := []byte(`{"job_id":"j1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(fixedNow.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign("s3cret", fixedNow, body))
+
+	v := &Verifier{Secrets: []string{"s3cret"}, Now: fixedClock}
+	got, err := v.VerifyRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	again, _ := io.ReadAll(req.Body)
+	assert.Equal(t, body, again)
+}
+
+func TestVerifyRequest_RejectsOversizedBody(t *testing.T) {
+	v := &Verifier{Secrets: []string{"s3cret"}, Now: fixedClock}
+	request := func(size int) *http.Request {
+		body := bytes.Repeat([]byte("a"), size)
+		req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+		req.Header.Set(TimestampHeader, strconv.FormatInt(fixedNow.Unix(), 10))
+		req.Header.Set(SignatureHeader, Sign("s3cret", fixedNow, body))
+		return req
+	}
+
+	got, err := v.VerifyRequest(request(MaxBodyBytes))
+	require.NoError(t, err)
+	assert.Len(t, got, MaxBodyBytes)
+
+	_, err = v.VerifyRequest(request(MaxBodyBytes + 1))
+	assert.ErrorIs(t, err, ErrBodyTooLarge, "a correctly signed body over the limit is refused, not truncated")
+}
+
+func ExampleVerifier_VerifyRequest() {
+	secret := "whsec_example"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := NewVerifier(secret)
+		if _, err := v.VerifyRequest(r); err != nil {
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, ErrSignatureMismatch):
+				status = http.StatusUnauthorized
+			case errors.Is(err, ErrBodyTooLarge):
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	body := []byte(`{"event":"JobCompleted"}`)
+	now := time.Now()
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, now, body))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	fmt.Println(rec.Code)
+	// Output: 204
+}